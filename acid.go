@@ -0,0 +1,100 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Size of the acid sub-chunk
+const AcidChunkSize = 24
+
+// Flags of the acid sub-chunk
+const (
+	AcidOneShot     uint32 = 0x01 // Plays once instead of looping
+	AcidRootNoteSet uint32 = 0x02 // Root note is valid and the loop is transposed
+	AcidStretch     uint32 = 0x04 // Tempo changes are time stretched
+	AcidDiskBased   uint32 = 0x08 // Streamed from disk instead of loaded in memory
+	AcidHighOctave  uint32 = 0x10
+)
+
+var ErrDecodeAcidSize = errors.New("acid sub-chunk size must be at least 24 bytes")
+
+// AcidChunk holds the loop metadata of ACIDized files.
+type AcidChunk struct {
+	Flags            uint32
+	RootNote         uint16 // MIDI note number, e.g. 60 for C4
+	Beats            uint32 // Number of beats
+	MeterDenominator uint16 // E.g. 4 for 4/4
+	MeterNumerator   uint16 // E.g. 4 for 4/4
+	Tempo            float32
+}
+
+// Decode decodes the acid sub-chunk data.
+func (c *AcidChunk) Decode(data []byte) error {
+	if len(data) < AcidChunkSize {
+		return ErrDecodeAcidSize
+	}
+
+	c.Flags = binary.LittleEndian.Uint32(data[0:4])
+	c.RootNote = binary.LittleEndian.Uint16(data[4:6])
+	// 2 + 4 reserved bytes
+	c.Beats = binary.LittleEndian.Uint32(data[12:16])
+	c.MeterDenominator = binary.LittleEndian.Uint16(data[16:18])
+	c.MeterNumerator = binary.LittleEndian.Uint16(data[18:20])
+	c.Tempo = math.Float32frombits(binary.LittleEndian.Uint32(data[20:24]))
+
+	return nil
+}
+
+// Encode encodes the acid sub-chunk data.
+func (c *AcidChunk) Encode() []byte {
+	data := make([]byte, AcidChunkSize)
+
+	binary.LittleEndian.PutUint32(data[0:4], c.Flags)
+	binary.LittleEndian.PutUint16(data[4:6], c.RootNote)
+	// Reserved value written by ACID
+	binary.LittleEndian.PutUint16(data[6:8], 0x8000)
+	binary.LittleEndian.PutUint32(data[12:16], c.Beats)
+	binary.LittleEndian.PutUint16(data[16:18], c.MeterDenominator)
+	binary.LittleEndian.PutUint16(data[18:20], c.MeterNumerator)
+	binary.LittleEndian.PutUint32(data[20:24], math.Float32bits(c.Tempo))
+
+	return data
+}
+
+// Loop reports whether the file is a loop rather than a one-shot.
+func (c *AcidChunk) Loop() bool {
+	return c.Flags&AcidOneShot == 0
+}
+
+// Key returns the pitch class of the root note, e.g. C#, or false if the
+// root note is not set.
+func (c *AcidChunk) Key() (string, bool) {
+	if c.Flags&AcidRootNoteSet == 0 {
+		return "", false
+	}
+
+	return [12]string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}[c.RootNote%12], true
+}
+
+// Acid returns the decoded acid sub-chunk.
+func (f *WAVEFileFormat) Acid() (*AcidChunk, error) {
+	data, err := f.SubChunk([4]byte{'a', 'c', 'i', 'd'})
+	if err != nil {
+		return nil, err
+	}
+
+	acid := &AcidChunk{}
+
+	if err := acid.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return acid, nil
+}
+
+// SetAcid encodes the acid sub-chunk, replacing an existing one.
+func (f *WAVEFileFormat) SetAcid(acid *AcidChunk) error {
+	return f.SetSubChunk([4]byte{'a', 'c', 'i', 'd'}, acid.Encode())
+}
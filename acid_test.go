@@ -0,0 +1,77 @@
+package wav_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestAcid(t *testing.T) {
+	// Stretched loop with root note C#4, 8 beats in 4/4 at 127.5 BPM
+	layout := []byte{
+		0x06, 0x00, 0x00, 0x00, // Flags
+		0x3D, 0x00, // Root note
+		0x00, 0x80, // Reserved
+		0x00, 0x00, 0x00, 0x00, // Reserved
+		0x08, 0x00, 0x00, 0x00, // Beats
+		0x04, 0x00, // Meter denominator
+		0x04, 0x00, // Meter numerator
+		0x00, 0x00, 0xFF, 0x42, // Tempo
+	}
+
+	acid := &wav.AcidChunk{}
+
+	if err := acid.Decode(layout); err != nil {
+		t.Fatalf("decoding acid: %s", err.Error())
+	}
+
+	want := wav.AcidChunk{
+		Flags:            wav.AcidRootNoteSet | wav.AcidStretch,
+		RootNote:         61,
+		Beats:            8,
+		MeterDenominator: 4,
+		MeterNumerator:   4,
+		Tempo:            127.5,
+	}
+
+	if *acid != want {
+		t.Errorf("acid: got %+v, want %+v", *acid, want)
+	}
+
+	if encoded := acid.Encode(); !bytes.Equal(encoded, layout) {
+		t.Errorf("encoded acid: got % X, want % X", encoded, layout)
+	}
+
+	if !acid.Loop() {
+		t.Errorf("loop: got one-shot, want loop")
+	}
+
+	if key, ok := acid.Key(); !ok || key != "C#" {
+		t.Errorf("key: got %q, %t, want C#, true", key, ok)
+	}
+
+	if _, ok := (&wav.AcidChunk{Flags: wav.AcidOneShot}).Key(); ok {
+		t.Errorf("key without root note: got a key, want none")
+	}
+
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, []byte{1, 2})
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	if err := waveFile.SetSubChunk([4]byte{'a', 'c', 'i', 'd'}, layout); err != nil {
+		t.Fatalf("setting acid sub-chunk: %s", err.Error())
+	}
+
+	if decoded, err := waveFile.Acid(); err != nil {
+		t.Fatalf("decoding acid sub-chunk: %s", err.Error())
+	} else if *decoded != want {
+		t.Errorf("acid sub-chunk: got %+v, want %+v", *decoded, want)
+	}
+
+	if err := (&wav.AcidChunk{}).Decode(layout[:wav.AcidChunkSize-1]); !errors.Is(err, wav.ErrDecodeAcidSize) {
+		t.Errorf("decoding short acid: got %v, want %v", err, wav.ErrDecodeAcidSize)
+	}
+}
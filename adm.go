@@ -0,0 +1,243 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+var ErrDecodeAxml = errors.New("axml sub-chunk must contain an audioFormatExtended element")
+
+// ADMProgramme is an audioProgramme, a complete mix of audio contents.
+type ADMProgramme struct {
+	ID          string   `xml:"audioProgrammeID,attr"`
+	Name        string   `xml:"audioProgrammeName,attr"`
+	Language    string   `xml:"audioProgrammeLanguage,attr,omitempty"`
+	Start       string   `xml:"start,attr,omitempty"` // hh:mm:ss.fffff
+	End         string   `xml:"end,attr,omitempty"`   // hh:mm:ss.fffff
+	ContentRefs []string `xml:"audioContentIDRef"`
+}
+
+// ADMContent is an audioContent, e.g. dialogue or music, made of objects.
+type ADMContent struct {
+	ID         string   `xml:"audioContentID,attr"`
+	Name       string   `xml:"audioContentName,attr"`
+	Language   string   `xml:"audioContentLanguage,attr,omitempty"`
+	ObjectRefs []string `xml:"audioObjectIDRef"`
+}
+
+// ADMObject is an audioObject, e.g. a bed or a moving object, which links
+// pack formats to the tracks holding its audio.
+type ADMObject struct {
+	ID             string   `xml:"audioObjectID,attr"`
+	Name           string   `xml:"audioObjectName,attr"`
+	Start          string   `xml:"start,attr,omitempty"`    // hh:mm:ss.fffff
+	Duration       string   `xml:"duration,attr,omitempty"` // hh:mm:ss.fffff
+	PackFormatRefs []string `xml:"audioPackFormatIDRef"`
+	ObjectRefs     []string `xml:"audioObjectIDRef"`
+	TrackUIDRefs   []string `xml:"audioTrackUIDRef"`
+}
+
+// ADMPackFormat is an audioPackFormat, grouping the channels of e.g. a 5.1
+// bed or an object.
+type ADMPackFormat struct {
+	ID                string   `xml:"audioPackFormatID,attr"`
+	Name              string   `xml:"audioPackFormatName,attr"`
+	TypeLabel         string   `xml:"typeLabel,attr,omitempty"`
+	TypeDefinition    string   `xml:"typeDefinition,attr,omitempty"` // E.g. DirectSpeakers or Objects
+	ChannelFormatRefs []string `xml:"audioChannelFormatIDRef"`
+}
+
+// ADMChannelFormat is an audioChannelFormat. Its blocks of positions and
+// gains are not part of the model.
+type ADMChannelFormat struct {
+	ID             string `xml:"audioChannelFormatID,attr"`
+	Name           string `xml:"audioChannelFormatName,attr"`
+	TypeLabel      string `xml:"typeLabel,attr,omitempty"`
+	TypeDefinition string `xml:"typeDefinition,attr,omitempty"`
+}
+
+// ADMTrackUID is an audioTrackUID, identifying the audio of a track as
+// allocated in the chna sub-chunk.
+type ADMTrackUID struct {
+	UID            string `xml:"UID,attr"`
+	SampleRate     int    `xml:"sampleRate,attr,omitempty"`
+	BitDepth       int    `xml:"bitDepth,attr,omitempty"`
+	TrackFormatRef string `xml:"audioTrackFormatIDRef,omitempty"`
+	PackFormatRef  string `xml:"audioPackFormatIDRef,omitempty"`
+}
+
+// AxmlChunk holds the minimal Audio Definition Model (ITU-R BS.2076) of the
+// axml sub-chunk. Elements outside the model, such as stream and track
+// formats and the blocks of channel formats, are not preserved.
+type AxmlChunk struct {
+	Version        string // E.g. ITU-R_BS.2076-2, optional
+	Programmes     []ADMProgramme
+	Contents       []ADMContent
+	Objects        []ADMObject
+	PackFormats    []ADMPackFormat
+	ChannelFormats []ADMChannelFormat
+	TrackUIDs      []ADMTrackUID
+}
+
+// admFormat is the audioFormatExtended element holding the model.
+type admFormat struct {
+	Version        string             `xml:"version,attr,omitempty"`
+	Programmes     []ADMProgramme     `xml:"audioProgramme"`
+	Contents       []ADMContent       `xml:"audioContent"`
+	Objects        []ADMObject        `xml:"audioObject"`
+	PackFormats    []ADMPackFormat    `xml:"audioPackFormat"`
+	ChannelFormats []ADMChannelFormat `xml:"audioChannelFormat"`
+	TrackUIDs      []ADMTrackUID      `xml:"audioTrackUID"`
+}
+
+// Decode decodes the axml sub-chunk data. The audioFormatExtended element is
+// found regardless of its enclosing elements, e.g. ebuCoreMain.
+func (c *AxmlChunk) Decode(data []byte) error {
+	// Documents are often padded with null characters
+	decoder := xml.NewDecoder(bytes.NewReader(bytes.TrimRight(data, "\x00")))
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return ErrDecodeAxml
+		} else if err != nil {
+			return fmt.Errorf("%w: %w", ErrDecodeAxml, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "audioFormatExtended" {
+			continue
+		}
+
+		var format admFormat
+
+		if err := decoder.DecodeElement(&format, &start); err != nil {
+			return fmt.Errorf("%w: %w", ErrDecodeAxml, err)
+		}
+
+		*c = AxmlChunk(format)
+
+		return nil
+	}
+}
+
+// ebuCoreMain is the root element of axml documents.
+type ebuCoreMain struct {
+	XMLName   xml.Name  `xml:"ebuCoreMain"`
+	Namespace string    `xml:"xmlns,attr"`
+	Format    admFormat `xml:"coreMetadata>format>audioFormatExtended"`
+}
+
+// Encode encodes the axml sub-chunk data as an indented EBU Core document.
+func (c *AxmlChunk) Encode() ([]byte, error) {
+	buffer := bytes.NewBufferString(xml.Header)
+
+	encoder := xml.NewEncoder(buffer)
+	encoder.Indent("", "\t")
+
+	root := ebuCoreMain{
+		Namespace: "urn:ebu:metadata-schema:ebuCore_2014",
+		Format:    admFormat(*c),
+	}
+
+	if err := encoder.Encode(root); err != nil {
+		return nil, fmt.Errorf("encoding axml: %w", err)
+	}
+
+	buffer.WriteByte('\n')
+
+	return buffer.Bytes(), nil
+}
+
+// Object returns the object with the ID.
+func (c *AxmlChunk) Object(id string) (ADMObject, bool) {
+	i := slices.IndexFunc(c.Objects, func(object ADMObject) bool { return object.ID == id })
+	if i < 0 {
+		return ADMObject{}, false
+	}
+
+	return c.Objects[i], true
+}
+
+// PackFormat returns the pack format with the ID. Common definitions, such as
+// the pack formats of standard beds, are usually not part of the document.
+func (c *AxmlChunk) PackFormat(id string) (ADMPackFormat, bool) {
+	i := slices.IndexFunc(c.PackFormats, func(pack ADMPackFormat) bool { return pack.ID == id })
+	if i < 0 {
+		return ADMPackFormat{}, false
+	}
+
+	return c.PackFormats[i], true
+}
+
+// ADMTrack is a track of the audio data with its ADM metadata.
+type ADMTrack struct {
+	ChnaAudioID
+	Objects []ADMObject // Objects referencing the audioTrackUID
+}
+
+// Tracks links the audio ids of the chna sub-chunk to the objects of the
+// model, in the order of the chna sub-chunk.
+func (c *AxmlChunk) Tracks(chna *ChnaChunk) []ADMTrack {
+	tracks := make([]ADMTrack, 0, len(chna.AudioIDs))
+
+	for _, id := range chna.AudioIDs {
+		track := ADMTrack{ChnaAudioID: id}
+
+		for _, object := range c.Objects {
+			if slices.Contains(object.TrackUIDRefs, id.UID) {
+				track.Objects = append(track.Objects, object)
+			}
+		}
+
+		tracks = append(tracks, track)
+	}
+
+	return tracks
+}
+
+// Axml returns the decoded axml sub-chunk.
+func (f *WAVEFileFormat) Axml() (*AxmlChunk, error) {
+	data, err := f.SubChunk([4]byte{'a', 'x', 'm', 'l'})
+	if err != nil {
+		return nil, err
+	}
+
+	axml := &AxmlChunk{}
+
+	if err := axml.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return axml, nil
+}
+
+// SetAxml encodes the axml sub-chunk, replacing an existing one.
+func (f *WAVEFileFormat) SetAxml(axml *AxmlChunk) error {
+	data, err := axml.Encode()
+	if err != nil {
+		return err
+	}
+
+	return f.SetSubChunk([4]byte{'a', 'x', 'm', 'l'}, data)
+}
+
+// ADMTracks returns the tracks of the chna sub-chunk with the objects of the
+// axml sub-chunk.
+func (f *WAVEFileFormat) ADMTracks() ([]ADMTrack, error) {
+	chna, err := f.Chna()
+	if err != nil {
+		return nil, err
+	}
+
+	axml, err := f.Axml()
+	if err != nil {
+		return nil, err
+	}
+
+	return axml.Tracks(chna), nil
+}
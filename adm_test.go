@@ -0,0 +1,150 @@
+package wav_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+const testAxml = `<?xml version="1.0" encoding="UTF-8"?>
+<ebuCoreMain xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns="urn:ebu:metadata-schema:ebuCore_2014">
+	<coreMetadata>
+		<format>
+			<audioFormatExtended version="ITU-R_BS.2076-2">
+				<audioProgramme audioProgrammeID="APR_1001" audioProgrammeName="Main" audioProgrammeLanguage="en" start="00:00:00.00000">
+					<audioContentIDRef>ACO_1001</audioContentIDRef>
+				</audioProgramme>
+				<audioContent audioContentID="ACO_1001" audioContentName="Mix">
+					<audioObjectIDRef>AO_1001</audioObjectIDRef>
+					<audioObjectIDRef>AO_1002</audioObjectIDRef>
+				</audioContent>
+				<audioObject audioObjectID="AO_1001" audioObjectName="Bed">
+					<audioPackFormatIDRef>AP_00010002</audioPackFormatIDRef>
+					<audioTrackUIDRef>ATU_00000001</audioTrackUIDRef>
+					<audioTrackUIDRef>ATU_00000002</audioTrackUIDRef>
+				</audioObject>
+				<audioObject audioObjectID="AO_1002" audioObjectName="Plane" start="00:00:01.00000" duration="00:00:02.00000">
+					<audioPackFormatIDRef>AP_00031001</audioPackFormatIDRef>
+					<audioTrackUIDRef>ATU_00000003</audioTrackUIDRef>
+				</audioObject>
+				<audioPackFormat audioPackFormatID="AP_00031001" audioPackFormatName="Plane" typeLabel="0003" typeDefinition="Objects">
+					<audioChannelFormatIDRef>AC_00031001</audioChannelFormatIDRef>
+				</audioPackFormat>
+				<audioChannelFormat audioChannelFormatID="AC_00031001" audioChannelFormatName="Plane" typeLabel="0003" typeDefinition="Objects">
+					<audioBlockFormat audioBlockFormatID="AB_00031001_00000001"/>
+				</audioChannelFormat>
+				<audioTrackUID UID="ATU_00000003" sampleRate="48000" bitDepth="24">
+					<audioTrackFormatIDRef>AT_00031001_01</audioTrackFormatIDRef>
+					<audioPackFormatIDRef>AP_00031001</audioPackFormatIDRef>
+				</audioTrackUID>
+			</audioFormatExtended>
+		</format>
+	</coreMetadata>
+</ebuCoreMain>
+`
+
+func TestADM(t *testing.T) {
+	file, err := wav.New(wav.Config{Channels: 3, SampleRate: 48000, BitDepth: 24}, make([]byte, 9))
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	axml := &wav.AxmlChunk{}
+
+	if err := axml.Decode(append([]byte(testAxml), 0, 0)); err != nil {
+		t.Fatalf("decoding axml: %s", err.Error())
+	}
+
+	if axml.Version != "ITU-R_BS.2076-2" || len(axml.Programmes) != 1 || len(axml.Contents) != 1 || len(axml.Objects) != 2 || len(axml.PackFormats) != 1 || len(axml.ChannelFormats) != 1 || len(axml.TrackUIDs) != 1 {
+		t.Fatalf("axml: got %+v", axml)
+	}
+
+	if object, ok := axml.Object("AO_1002"); !ok || object.Duration != "00:00:02.00000" || object.PackFormatRefs[0] != "AP_00031001" {
+		t.Errorf("object: got %+v", object)
+	}
+
+	if pack, ok := axml.PackFormat("AP_00031001"); !ok || pack.TypeDefinition != "Objects" {
+		t.Errorf("pack format: got %+v", pack)
+	}
+
+	chna := &wav.ChnaChunk{AudioIDs: []wav.ChnaAudioID{
+		{TrackIndex: 1, UID: "ATU_00000001", TrackFormatRef: "AT_00010001_01", PackFormatRef: "AP_00010002"},
+		{TrackIndex: 2, UID: "ATU_00000002", TrackFormatRef: "AT_00010002_01", PackFormatRef: "AP_00010002"},
+		{TrackIndex: 3, UID: "ATU_00000003", TrackFormatRef: "AT_00031001_01", PackFormatRef: "AP_00031001"},
+	}}
+
+	if err := file.SetChna(chna); err != nil {
+		t.Fatalf("setting chna: %s", err.Error())
+	}
+
+	if err := file.SetAxml(axml); err != nil {
+		t.Fatalf("setting axml: %s", err.Error())
+	}
+
+	var buffer bytes.Buffer
+
+	if err := file.Encode(&buffer); err != nil {
+		t.Fatalf("encoding: %s", err.Error())
+	}
+
+	decoded := &wav.WAVEFileFormat{}
+
+	if err := decoded.Decode(&buffer); err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	decodedChna, err := decoded.Chna()
+	if err != nil {
+		t.Fatalf("chna: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(decodedChna, chna) || decodedChna.Tracks() != 3 {
+		t.Errorf("chna: got %+v, want %+v", decodedChna, chna)
+	}
+
+	decodedAxml, err := decoded.Axml()
+	if err != nil {
+		t.Fatalf("axml: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(decodedAxml, axml) {
+		t.Errorf("axml: got %+v, want %+v", decodedAxml, axml)
+	}
+
+	tracks, err := decoded.ADMTracks()
+	if err != nil {
+		t.Fatalf("tracks: %s", err.Error())
+	}
+
+	if len(tracks) != 3 || len(tracks[1].Objects) != 1 || tracks[1].Objects[0].Name != "Bed" || tracks[2].Objects[0].Name != "Plane" {
+		t.Errorf("tracks: got %+v", tracks)
+	}
+}
+
+func TestChnaUnused(t *testing.T) {
+	// One used and one unused audio id
+	data := []byte{1, 0, 2, 0}
+	data = append(data, 1, 0)
+	data = append(data, "ATU_00000001AT_00010003_01AP_00010001"...)
+	data = append(data, 0)
+	data = append(data, make([]byte, wav.ChnaAudioIDSize)...)
+
+	chna := &wav.ChnaChunk{}
+
+	if err := chna.Decode(data); err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	if len(chna.AudioIDs) != 1 || chna.AudioIDs[0].PackFormatRef != "AP_00010001" {
+		t.Errorf("audio ids: got %+v", chna.AudioIDs)
+	}
+
+	// Unused audio ids are not encoded
+	want := append([]byte{1, 0, 1, 0}, data[4:4+wav.ChnaAudioIDSize]...)
+
+	if encoded := chna.Encode(); !bytes.Equal(encoded, want) {
+		t.Errorf("encoded: got % X, want % X", encoded, want)
+	}
+}
@@ -0,0 +1,594 @@
+// Package aiff decodes and encodes AIFF and AIFF-C files and converts them to
+// and from WAV files without loss of audio data, so their samples can be read
+// with the same accessors.
+//
+// https://www.mmsp.ece.mcgill.ca/Documents/AudioFormats/AIFF/AIFF.html
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/samborkent/wav"
+)
+
+// Compression types of AIFF-C files
+var (
+	CompressionNone         = [4]byte{'N', 'O', 'N', 'E'} // Big endian PCM
+	CompressionLittleEndian = [4]byte{'s', 'o', 'w', 't'} // Little endian PCM
+	CompressionFloat32      = [4]byte{'f', 'l', '3', '2'}
+	CompressionFloat64      = [4]byte{'f', 'l', '6', '4'}
+	CompressionALaw         = [4]byte{'a', 'l', 'a', 'w'}
+	CompressionMuLaw        = [4]byte{'u', 'l', 'a', 'w'}
+)
+
+// Form types
+var (
+	FormAIFF = [4]byte{'A', 'I', 'F', 'F'}
+	FormAIFC = [4]byte{'A', 'I', 'F', 'C'}
+)
+
+// AIFC version 1 timestamp of the FVER chunk
+const versionAIFC = 0xA2805140
+
+var (
+	ErrDecodeFormID            = errors.New("form chunk id does not match 'FORM'")
+	ErrDecodeFormType          = errors.New("form chunk type does not match 'AIFF' or 'AIFC'")
+	ErrDecodeCommon            = errors.New("common chunk is missing or malformed")
+	ErrDecodeSoundData         = errors.New("sound data chunk is missing or malformed")
+	ErrDecodeMarker            = errors.New("marker chunk is malformed")
+	ErrCompressionNotSupported = errors.New("compression type is not supported")
+	ErrSampleRate              = errors.New("sample rate is not a whole number of hertz")
+	ErrDataTooLarge            = errors.New("data exceeds aiff length limit of 4 GiB")
+)
+
+// Chunk is a chunk other than the common, sound data and version chunks,
+// such as NAME, ANNO or MARK.
+type Chunk struct {
+	ID   [4]byte
+	Data []byte // Without padding byte
+}
+
+// File is an AIFF or AIFF-C file held in memory.
+type File struct {
+	FormType        [4]byte // FormAIFF or FormAIFC
+	Channels        int
+	Frames          int
+	SampleSize      int // Bits per sample, which are left-aligned in whole bytes
+	SampleRate      float64
+	Compression     [4]byte // CompressionNone for AIFF files
+	CompressionName string  // AIFF-C only
+	Data            []byte  // Sound data as stored, e.g. big endian PCM
+	Chunks          []Chunk // Optional, e.g. metadata
+}
+
+// Marker is a position in the sound data of a MARK chunk.
+type Marker struct {
+	ID       int16
+	Position uint32 // Frame
+	Name     string
+}
+
+// Decode decodes an AIFF or AIFF-C file. The common chunk may follow the
+// sound data chunk, so the whole file is read.
+func (f *File) Decode(reader io.Reader) error {
+	var header [12]byte
+
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return fmt.Errorf("reading form chunk: %w", err)
+	}
+
+	if [4]byte(header[0:4]) != [4]byte{'F', 'O', 'R', 'M'} {
+		return ErrDecodeFormID
+	}
+
+	*f = File{FormType: [4]byte(header[8:12])}
+
+	if f.FormType != FormAIFF && f.FormType != FormAIFC {
+		return ErrDecodeFormType
+	}
+
+	// Chunks are read up to the form size, or the end of a truncated file
+	size := int64(binary.BigEndian.Uint32(header[4:8])) - 4
+	foundCommon, foundData := false, false
+
+	for read := int64(0); read+8 <= size; {
+		var chunk [8]byte
+
+		if _, err := io.ReadFull(reader, chunk[:]); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("reading chunk: %w", err)
+		}
+
+		id := [4]byte(chunk[0:4])
+		data := make([]byte, binary.BigEndian.Uint32(chunk[4:8]))
+
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return fmt.Errorf("reading '%s' chunk: %w", id[:], err)
+		}
+
+		if len(data)%2 != 0 {
+			var padding [1]byte
+
+			if _, err := reader.Read(padding[:]); err != nil && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("reading '%s' chunk: padding byte: %w", id[:], err)
+			}
+		}
+
+		read += 8 + int64(len(data)+len(data)%2)
+
+		switch id {
+		case [4]byte{'C', 'O', 'M', 'M'}:
+			if err := f.decodeCommon(data); err != nil {
+				return err
+			}
+
+			foundCommon = true
+		case [4]byte{'S', 'S', 'N', 'D'}:
+			if len(data) < 8 {
+				return ErrDecodeSoundData
+			}
+
+			offset := int(binary.BigEndian.Uint32(data[0:4]))
+			if offset > len(data)-8 {
+				return ErrDecodeSoundData
+			}
+
+			f.Data = data[8+offset:]
+			foundData = true
+		case [4]byte{'F', 'V', 'E', 'R'}:
+		default:
+			f.Chunks = append(f.Chunks, Chunk{ID: id, Data: data})
+		}
+	}
+
+	if !foundCommon {
+		return ErrDecodeCommon
+	}
+
+	if !foundData {
+		if f.Frames != 0 {
+			return ErrDecodeSoundData
+		}
+
+		f.Data = []byte{}
+	}
+
+	// Drop trailing bytes beyond the number of frames
+	if blockAlign := f.blockAlign(); blockAlign > 0 && len(f.Data) > f.Frames*blockAlign {
+		f.Data = f.Data[:f.Frames*blockAlign]
+	}
+
+	return nil
+}
+
+func (f *File) decodeCommon(data []byte) error {
+	if len(data) < 18 {
+		return ErrDecodeCommon
+	}
+
+	f.Channels = int(binary.BigEndian.Uint16(data[0:2]))
+	f.Frames = int(binary.BigEndian.Uint32(data[2:6]))
+	f.SampleSize = int(binary.BigEndian.Uint16(data[6:8]))
+	f.SampleRate = decodeExtended([10]byte(data[8:18]))
+	f.Compression = CompressionNone
+
+	if f.FormType != FormAIFC {
+		return nil
+	}
+
+	if len(data) < 22 {
+		return ErrDecodeCommon
+	}
+
+	f.Compression = [4]byte(data[18:22])
+
+	if len(data) > 22 {
+		name, _, ok := decodeString(data[22:])
+		if !ok {
+			return ErrDecodeCommon
+		}
+
+		f.CompressionName = name
+	}
+
+	return nil
+}
+
+// Encode encodes the file. Files with a compression type other than
+// CompressionNone are encoded as AIFF-C.
+func (f *File) Encode(writer io.Writer) error {
+	formType := f.FormType
+	if f.Compression != CompressionNone && f.Compression != [4]byte{} {
+		formType = FormAIFC
+	} else if formType != FormAIFC {
+		formType = FormAIFF
+	}
+
+	var body bytes.Buffer
+
+	if formType == FormAIFC {
+		writeChunk(&body, [4]byte{'F', 'V', 'E', 'R'}, binary.BigEndian.AppendUint32(nil, versionAIFC))
+	}
+
+	writeChunk(&body, [4]byte{'C', 'O', 'M', 'M'}, f.encodeCommon(formType))
+
+	for _, chunk := range f.Chunks {
+		writeChunk(&body, chunk.ID, chunk.Data)
+	}
+
+	// Sound data offset and block size of zero
+	writeChunk(&body, [4]byte{'S', 'S', 'N', 'D'}, append(make([]byte, 8, 8+len(f.Data)), f.Data...))
+
+	if int64(body.Len())+4 > math.MaxUint32 {
+		return ErrDataTooLarge
+	}
+
+	header := make([]byte, 0, 12)
+	header = append(header, 'F', 'O', 'R', 'M')
+	header = binary.BigEndian.AppendUint32(header, uint32(body.Len()+4))
+	header = append(header, formType[:]...)
+
+	if _, err := writer.Write(header); err != nil {
+		return fmt.Errorf("writing form chunk: %w", err)
+	}
+
+	if _, err := body.WriteTo(writer); err != nil {
+		return fmt.Errorf("writing chunks: %w", err)
+	}
+
+	return nil
+}
+
+func (f *File) encodeCommon(formType [4]byte) []byte {
+	data := make([]byte, 0, 18)
+	data = binary.BigEndian.AppendUint16(data, uint16(f.Channels))
+	data = binary.BigEndian.AppendUint32(data, uint32(f.Frames))
+	data = binary.BigEndian.AppendUint16(data, uint16(f.SampleSize))
+
+	rate := encodeExtended(f.SampleRate)
+	data = append(data, rate[:]...)
+
+	if formType == FormAIFC {
+		compression := f.Compression
+		if compression == [4]byte{} {
+			compression = CompressionNone
+		}
+
+		data = append(data, compression[:]...)
+		data = appendString(data, f.CompressionName)
+	}
+
+	return data
+}
+
+// Chunk returns the data of the first chunk with the ID.
+func (f *File) Chunk(id [4]byte) ([]byte, bool) {
+	for _, chunk := range f.Chunks {
+		if chunk.ID == id {
+			return chunk.Data, true
+		}
+	}
+
+	return nil, false
+}
+
+// Markers returns the markers of the MARK chunk, if any.
+func (f *File) Markers() ([]Marker, error) {
+	data, ok := f.Chunk([4]byte{'M', 'A', 'R', 'K'})
+	if !ok {
+		return nil, nil
+	}
+
+	if len(data) < 2 {
+		return nil, ErrDecodeMarker
+	}
+
+	count := int(binary.BigEndian.Uint16(data[0:2]))
+	markers := make([]Marker, 0, count)
+
+	for offset := 2; len(markers) < count; {
+		if offset+6 > len(data) {
+			return nil, ErrDecodeMarker
+		}
+
+		marker := Marker{
+			ID:       int16(binary.BigEndian.Uint16(data[offset : offset+2])),
+			Position: binary.BigEndian.Uint32(data[offset+2 : offset+6]),
+		}
+
+		name, size, ok := decodeString(data[offset+6:])
+		if !ok {
+			return nil, ErrDecodeMarker
+		}
+
+		marker.Name = name
+		markers = append(markers, marker)
+		offset += 6 + size
+	}
+
+	return markers, nil
+}
+
+// SetMarkers replaces the MARK chunk, removing it if there are no markers.
+func (f *File) SetMarkers(markers []Marker) {
+	chunks := f.Chunks[:0]
+	index := -1
+
+	for _, chunk := range f.Chunks {
+		if chunk.ID == [4]byte{'M', 'A', 'R', 'K'} {
+			if index < 0 {
+				index = len(chunks)
+				chunks = append(chunks, chunk)
+			}
+
+			continue
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	f.Chunks = chunks
+
+	if len(markers) == 0 {
+		if index >= 0 {
+			f.Chunks = append(f.Chunks[:index], f.Chunks[index+1:]...)
+		}
+
+		return
+	}
+
+	data := binary.BigEndian.AppendUint16(nil, uint16(len(markers)))
+
+	for _, marker := range markers {
+		data = binary.BigEndian.AppendUint16(data, uint16(marker.ID))
+		data = binary.BigEndian.AppendUint32(data, marker.Position)
+		data = appendString(data, marker.Name)
+	}
+
+	if index < 0 {
+		f.Chunks = append(f.Chunks, Chunk{ID: [4]byte{'M', 'A', 'R', 'K'}, Data: data})
+	} else {
+		f.Chunks[index].Data = data
+	}
+}
+
+// Config returns the configuration of the audio data once converted to WAV,
+// where bit depths are rounded up to whole bytes.
+func (f *File) Config() (wav.Config, error) {
+	if f.SampleRate <= 0 || f.SampleRate != math.Trunc(f.SampleRate) || f.SampleRate > math.MaxUint32 {
+		return wav.Config{}, fmt.Errorf("%w: %g", ErrSampleRate, f.SampleRate)
+	}
+
+	cfg := wav.Config{
+		Channels:   f.Channels,
+		SampleRate: int(f.SampleRate),
+		BitDepth:   (f.SampleSize + 7) / 8 * 8,
+		Format:     wav.FormatPCM,
+	}
+
+	switch f.Compression {
+	case CompressionNone, CompressionLittleEndian, [4]byte{}:
+	case CompressionFloat32, [4]byte{'F', 'L', '3', '2'}:
+		cfg.BitDepth, cfg.Format, cfg.FloatingPoint = 32, wav.FormatIEEEFloat, true
+	case CompressionFloat64, [4]byte{'F', 'L', '6', '4'}:
+		cfg.BitDepth, cfg.Format, cfg.FloatingPoint = 64, wav.FormatIEEEFloat, true
+	case CompressionALaw, [4]byte{'A', 'L', 'A', 'W'}:
+		cfg.BitDepth, cfg.Format = 8, wav.FormatALaw
+	case CompressionMuLaw, [4]byte{'U', 'L', 'A', 'W'}:
+		cfg.BitDepth, cfg.Format = 8, wav.FormatMuLaw
+	default:
+		return wav.Config{}, fmt.Errorf("%w: '%s'", ErrCompressionNotSupported, f.Compression[:])
+	}
+
+	return cfg, nil
+}
+
+// SampleReader returns a SampleReader over the audio data.
+func (f *File) SampleReader() (*wav.SampleReader, error) {
+	file, err := f.WAV()
+	if err != nil {
+		return nil, err
+	}
+
+	return file.SampleReader()
+}
+
+// WAV converts the file to WAV. The audio data is converted to little endian
+// and unsigned 8-bit samples without loss. Markers become cue points with
+// labels, and the NAME, AUTH, copyright and first ANNO chunks become LIST
+// INFO fields. Other chunks are dropped.
+func (f *File) WAV() (*wav.WAVEFileFormat, error) {
+	cfg, err := f.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	layout := wav.RawLayout{BigEndian: f.Compression != CompressionLittleEndian, Signed8: true}
+
+	file, err := wav.DecodeRaw(bytes.NewReader(f.Data), cfg, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.exportMetadata(file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// FromWAV converts a WAV file to AIFF, or AIFF-C for floating point and
+// companded audio data. It is the inverse of WAV.
+func FromWAV(file *wav.WAVEFileFormat) (*File, error) {
+	cfg := file.Config()
+
+	var data bytes.Buffer
+
+	if err := file.EncodeRaw(&data, wav.RawLayout{BigEndian: true, Signed8: true}); err != nil {
+		return nil, err
+	}
+
+	if data.Len()+64 > math.MaxUint32 {
+		return nil, ErrDataTooLarge
+	}
+
+	f := &File{
+		FormType:    FormAIFF,
+		Channels:    cfg.Channels,
+		Frames:      file.Frames(),
+		SampleSize:  cfg.BitDepth,
+		SampleRate:  float64(cfg.SampleRate),
+		Compression: CompressionNone,
+		Data:        data.Bytes(),
+	}
+
+	switch cfg.Format {
+	case wav.FormatIEEEFloat:
+		f.FormType = FormAIFC
+		f.Compression, f.CompressionName = CompressionFloat32, "32-bit floating point"
+
+		if cfg.BitDepth == 64 {
+			f.Compression, f.CompressionName = CompressionFloat64, "64-bit floating point"
+		}
+	case wav.FormatALaw:
+		f.FormType = FormAIFC
+		f.Compression, f.CompressionName = CompressionALaw, "ALaw 2:1"
+	case wav.FormatMuLaw:
+		f.FormType = FormAIFC
+		f.Compression, f.CompressionName = CompressionMuLaw, "uLaw 2:1"
+	}
+
+	if err := f.importMetadata(file); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// blockAlign returns the number of bytes per frame.
+func (f *File) blockAlign() int {
+	bytesPerSample := (f.SampleSize + 7) / 8
+
+	switch f.Compression {
+	case CompressionFloat32, [4]byte{'F', 'L', '3', '2'}:
+		bytesPerSample = 4
+	case CompressionFloat64, [4]byte{'F', 'L', '6', '4'}:
+		bytesPerSample = 8
+	case CompressionALaw, CompressionMuLaw, [4]byte{'A', 'L', 'A', 'W'}, [4]byte{'U', 'L', 'A', 'W'}:
+		bytesPerSample = 1
+	case CompressionNone, CompressionLittleEndian:
+	default:
+		return 0
+	}
+
+	return f.Channels * bytesPerSample
+}
+
+func writeChunk(buffer *bytes.Buffer, id [4]byte, data []byte) {
+	buffer.Write(id[:])
+	buffer.Write(binary.BigEndian.AppendUint32(nil, uint32(len(data))))
+	buffer.Write(data)
+
+	if len(data)%2 != 0 {
+		buffer.WriteByte(0)
+	}
+}
+
+// decodeString decodes a Pascal string padded to an even size, returning the
+// string and its size including the padding.
+func decodeString(data []byte) (string, int, bool) {
+	if len(data) == 0 {
+		return "", 0, false
+	}
+
+	length := int(data[0])
+	if 1+length > len(data) {
+		return "", 0, false
+	}
+
+	size := 1 + length
+	if size%2 != 0 && size < len(data) {
+		size++
+	}
+
+	return string(data[1 : 1+length]), size, true
+}
+
+// appendString appends a Pascal string padded to an even size, truncated to
+// 255 bytes.
+func appendString(data []byte, value string) []byte {
+	if len(value) > math.MaxUint8 {
+		value = value[:math.MaxUint8]
+	}
+
+	data = append(data, byte(len(value)))
+	data = append(data, value...)
+
+	if (1+len(value))%2 != 0 {
+		data = append(data, 0)
+	}
+
+	return data
+}
+
+// decodeExtended decodes an 80-bit IEEE 754 extended precision number.
+func decodeExtended(data [10]byte) float64 {
+	exponent := int(binary.BigEndian.Uint16(data[0:2]))
+	mantissa := binary.BigEndian.Uint64(data[2:10])
+
+	sign := 1.0
+	if exponent&0x8000 != 0 {
+		sign = -1
+		exponent &= 0x7FFF
+	}
+
+	switch {
+	case exponent == 0 && mantissa == 0:
+		return 0
+	case exponent == 0x7FFF:
+		if mantissa<<1 == 0 {
+			return math.Inf(int(sign))
+		}
+
+		return math.NaN()
+	}
+
+	// Explicit integer bit, so the mantissa is a 1.63 fixed point number
+	return sign * math.Ldexp(float64(mantissa), exponent-16383-63)
+}
+
+// encodeExtended encodes an 80-bit IEEE 754 extended precision number.
+func encodeExtended(value float64) [10]byte {
+	var data [10]byte
+
+	if value == 0 || math.IsNaN(value) {
+		return data
+	}
+
+	sign := uint16(0)
+	if value < 0 {
+		sign = 0x8000
+		value = -value
+	}
+
+	if math.IsInf(value, 0) {
+		binary.BigEndian.PutUint16(data[0:2], sign|0x7FFF)
+		binary.BigEndian.PutUint64(data[2:10], 1<<63)
+
+		return data
+	}
+
+	// Fraction in [0.5, 1), so the integer bit is the top mantissa bit
+	fraction, exponent := math.Frexp(value)
+
+	binary.BigEndian.PutUint16(data[0:2], sign|uint16(exponent-1+16383))
+	binary.BigEndian.PutUint64(data[2:10], uint64(math.Ldexp(fraction, 64)))
+
+	return data
+}
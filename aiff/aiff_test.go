@@ -0,0 +1,196 @@
+package aiff_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/aiff"
+)
+
+func TestDecode(t *testing.T) {
+	// Stereo 16-bit at 44.1 kHz with two frames
+	comm := []byte{0, 2, 0, 0, 0, 2, 0, 16, 0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0}
+	ssnd := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0x40, 0x00, 0xC0, 0x00, 0x7F, 0xFF, 0x80, 0x00}
+
+	var data bytes.Buffer
+
+	data.WriteString("FORM")
+	data.Write(binary.BigEndian.AppendUint32(nil, uint32(4+8+len(comm)+8+len(ssnd)+8+4)))
+	data.WriteString("AIFF")
+	data.WriteString("SSND")
+	data.Write(binary.BigEndian.AppendUint32(nil, uint32(len(ssnd))))
+	data.Write(ssnd)
+	data.WriteString("COMM")
+	data.Write(binary.BigEndian.AppendUint32(nil, uint32(len(comm))))
+	data.Write(comm)
+	data.WriteString("NAME")
+	data.Write(binary.BigEndian.AppendUint32(nil, 3))
+	data.WriteString("abc\x00")
+
+	file := &aiff.File{}
+
+	if err := file.Decode(&data); err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	cfg, err := file.Config()
+	if err != nil {
+		t.Fatalf("config: %s", err.Error())
+	}
+
+	if want := (wav.Config{Channels: 2, SampleRate: 44100, BitDepth: 16, Format: wav.FormatPCM}); cfg != want {
+		t.Errorf("config: got %+v, want %+v", cfg, want)
+	}
+
+	reader, err := file.SampleReader()
+	if err != nil {
+		t.Fatalf("creating sample reader: %s", err.Error())
+	}
+
+	samples := make([]float64, 4)
+
+	if _, err := reader.ReadFrames(samples); err != nil {
+		t.Fatalf("reading frames: %s", err.Error())
+	}
+
+	want := []float64{0.5, -0.5, 32767.0 / 32768, -1}
+	for i := range want {
+		if samples[i] != want[i] {
+			t.Errorf("sample %d: got %f, want %f", i, samples[i], want[i])
+		}
+	}
+
+	converted, err := file.WAV()
+	if err != nil {
+		t.Fatalf("converting: %s", err.Error())
+	}
+
+	if info, err := converted.Info(); err != nil {
+		t.Errorf("info: %s", err.Error())
+	} else if title, _ := info.Get(wav.InfoTitle); title != "abc" {
+		t.Errorf("title: got %q, want %q", title, "abc")
+	}
+}
+
+func TestConversion(t *testing.T) {
+	configs := []wav.Config{
+		{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatPCM},
+		{Channels: 2, SampleRate: 44100, BitDepth: 16, Format: wav.FormatPCM},
+		{Channels: 2, SampleRate: 48000, BitDepth: 24, Format: wav.FormatPCM},
+		{Channels: 1, SampleRate: 96000, BitDepth: 32, Format: wav.FormatIEEEFloat, FloatingPoint: true},
+		{Channels: 1, SampleRate: 22050, BitDepth: 64, Format: wav.FormatIEEEFloat, FloatingPoint: true},
+		{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatALaw},
+		{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatMuLaw},
+	}
+
+	for _, cfg := range configs {
+		frames := 101
+		data := make([]byte, frames*cfg.Channels*cfg.BitDepth/8)
+
+		for i := range data {
+			data[i] = byte(i * 37)
+		}
+
+		if cfg.Format == wav.FormatIEEEFloat {
+			for i := 0; i < len(data); i += cfg.BitDepth / 8 {
+				value := math.Sin(float64(i))
+				if cfg.BitDepth == 32 {
+					binary.LittleEndian.PutUint32(data[i:], math.Float32bits(float32(value)))
+				} else {
+					binary.LittleEndian.PutUint64(data[i:], math.Float64bits(value))
+				}
+			}
+		}
+
+		original, err := wav.New(cfg, data)
+		if err != nil {
+			t.Fatalf("%+v: creating file: %s", cfg, err.Error())
+		}
+
+		if err := original.SetCue(&wav.CueChunk{Points: []wav.CuePoint{wav.NewCuePoint(1, 50)}}); err != nil {
+			t.Fatalf("%+v: setting cue sub-chunk: %s", cfg, err.Error())
+		}
+
+		if err := original.SetAssociatedData(&wav.AssociatedDataChunk{Labels: []wav.CueLabel{{CueID: 1, Text: "middle"}}}); err != nil {
+			t.Fatalf("%+v: setting adtl sub-chunk: %s", cfg, err.Error())
+		}
+
+		file, err := aiff.FromWAV(original)
+		if err != nil {
+			t.Fatalf("%+v: converting to aiff: %s", cfg, err.Error())
+		}
+
+		var encoded bytes.Buffer
+
+		if err := file.Encode(&encoded); err != nil {
+			t.Fatalf("%+v: encoding: %s", cfg, err.Error())
+		}
+
+		decoded := &aiff.File{}
+
+		if err := decoded.Decode(&encoded); err != nil {
+			t.Fatalf("%+v: decoding: %s", cfg, err.Error())
+		}
+
+		if decoded.SampleRate != float64(cfg.SampleRate) || decoded.Frames != frames {
+			t.Errorf("%+v: got %g Hz and %d frames, want %d Hz and %d frames", cfg, decoded.SampleRate, decoded.Frames, cfg.SampleRate, frames)
+		}
+
+		// Pascal strings of AIFF-C are not UTF-8
+		for _, char := range []byte(decoded.CompressionName) {
+			if char > 0x7F {
+				t.Errorf("%+v: compression name %q is not ASCII", cfg, decoded.CompressionName)
+				break
+			}
+		}
+
+		converted, err := decoded.WAV()
+		if err != nil {
+			t.Fatalf("%+v: converting to wav: %s", cfg, err.Error())
+		}
+
+		if got := converted.Config(); got != cfg {
+			t.Errorf("config: got %+v, want %+v", got, cfg)
+		}
+
+		if !bytes.Equal(converted.DataChunk.Data, data) {
+			t.Errorf("%+v: audio data differs after conversion", cfg)
+		}
+
+		cue, err := converted.Cue()
+		if err != nil || len(cue.Points) != 1 || cue.Points[0].Position != 50 {
+			t.Errorf("%+v: cue: got %+v and error %v, want one point at frame 50", cfg, cue, err)
+		}
+
+		if adtl, err := converted.AssociatedData(); err != nil {
+			t.Errorf("%+v: adtl: %s", cfg, err.Error())
+		} else if label, _ := adtl.Label(1); label != "middle" {
+			t.Errorf("%+v: label: got %q, want %q", cfg, label, "middle")
+		}
+	}
+}
+
+func TestSampleRate(t *testing.T) {
+	for _, rate := range []float64{8000, 11025, 44100, 48000, 192000, 2822400} {
+		file := &aiff.File{Channels: 1, Frames: 0, SampleSize: 16, SampleRate: rate, Compression: aiff.CompressionNone}
+
+		var encoded bytes.Buffer
+
+		if err := file.Encode(&encoded); err != nil {
+			t.Fatalf("encoding: %s", err.Error())
+		}
+
+		decoded := &aiff.File{}
+
+		if err := decoded.Decode(&encoded); err != nil {
+			t.Fatalf("decoding: %s", err.Error())
+		}
+
+		if decoded.SampleRate != rate {
+			t.Errorf("sample rate: got %g, want %g", decoded.SampleRate, rate)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package aiff
+
+import (
+	"bytes"
+	"errors"
+	"math"
+
+	"github.com/samborkent/wav"
+)
+
+// textChunks maps AIFF text chunks to LIST INFO fields.
+var textChunks = []struct {
+	id   [4]byte
+	info [4]byte
+}{
+	{[4]byte{'N', 'A', 'M', 'E'}, wav.InfoTitle},
+	{[4]byte{'A', 'U', 'T', 'H'}, wav.InfoArtist},
+	{[4]byte{'(', 'c', ')', ' '}, wav.InfoCopyright},
+	{[4]byte{'A', 'N', 'N', 'O'}, wav.InfoComment},
+}
+
+// exportMetadata copies the text chunks and markers to the WAV file.
+func (f *File) exportMetadata(file *wav.WAVEFileFormat) error {
+	info := &wav.InfoChunk{}
+
+	for _, text := range textChunks {
+		if data, ok := f.Chunk(text.id); ok {
+			info.Set(text.info, string(bytes.TrimRight(data, "\x00")))
+		}
+	}
+
+	if len(info.Entries) > 0 {
+		if err := file.SetInfo(info); err != nil {
+			return err
+		}
+	}
+
+	markers, err := f.Markers()
+	if err != nil || len(markers) == 0 {
+		return err
+	}
+
+	cue := &wav.CueChunk{}
+	adtl := &wav.AssociatedDataChunk{}
+
+	for _, marker := range markers {
+		id := uint32(uint16(marker.ID))
+
+		cue.Points = append(cue.Points, wav.NewCuePoint(id, int(marker.Position)))
+
+		if marker.Name != "" {
+			adtl.Labels = append(adtl.Labels, wav.CueLabel{CueID: id, Text: marker.Name})
+		}
+	}
+
+	if err := file.SetCue(cue); err != nil {
+		return err
+	}
+
+	if len(adtl.Labels) == 0 {
+		return nil
+	}
+
+	return file.SetAssociatedData(adtl)
+}
+
+// importMetadata copies the LIST INFO fields and cue points of the WAV file
+// to text chunks and markers. Cue point IDs outside the marker ID range are
+// renumbered from one.
+func (f *File) importMetadata(file *wav.WAVEFileFormat) error {
+	info, err := file.Info()
+	if err == nil {
+		for _, text := range textChunks {
+			if value, ok := info.Get(text.info); ok {
+				f.Chunks = append(f.Chunks, Chunk{ID: text.id, Data: []byte(value)})
+			}
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		return err
+	}
+
+	cue, err := file.Cue()
+	if errors.Is(err, wav.ErrSubChunkNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	adtl, err := file.AssociatedData()
+	if errors.Is(err, wav.ErrSubChunkNotFound) {
+		adtl = &wav.AssociatedDataChunk{}
+	} else if err != nil {
+		return err
+	}
+
+	renumber := false
+
+	for _, point := range cue.Points {
+		if point.ID == 0 || point.ID > math.MaxInt16 {
+			renumber = true
+		}
+	}
+
+	markers := make([]Marker, 0, len(cue.Points))
+
+	for i, point := range cue.Points {
+		marker := Marker{ID: int16(point.ID), Position: point.Position}
+		if renumber {
+			marker.ID = int16(i + 1)
+		}
+
+		marker.Name, _ = adtl.Label(point.ID)
+		markers = append(markers, marker)
+	}
+
+	f.SetMarkers(markers)
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrAmbisonicChannels = errors.New("number of channels does not match a full-sphere ambisonic order")
+
+// ambisonic reports whether the format sub-chunk holds ambisonic B-format
+// audio data.
+func (c *FormatChunk) ambisonic() bool {
+	if binary.LittleEndian.Uint16(c.Format[:]) != FormatExtensible {
+		return false
+	}
+
+	return c.SubFormat == SubFormatAmbisonicPCM || c.SubFormat == SubFormatAmbisonicFloat
+}
+
+// AmbisonicOrder returns the order of full-sphere ambisonics with the number
+// of channels, i.e. (order+1)^2 channels, starting at first order.
+func AmbisonicOrder(channels int) (int, error) {
+	order := int(math.Sqrt(float64(channels))) - 1
+
+	if order < 1 || (order+1)*(order+1) != channels {
+		return 0, fmt.Errorf("%w: %d channels", ErrAmbisonicChannels, channels)
+	}
+
+	return order, nil
+}
+
+// AmbisonicChannels returns the number of channels of full-sphere
+// ambisonics of the order.
+func AmbisonicChannels(order int) int {
+	return (order + 1) * (order + 1)
+}
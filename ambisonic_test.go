@@ -0,0 +1,75 @@
+package wav_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestAmbisonic(t *testing.T) {
+	tests := []struct {
+		cfg       wav.Config
+		subFormat [16]byte
+	}{
+		{wav.Config{Channels: 4, SampleRate: 48000, BitDepth: 16, Format: wav.FormatPCM, Ambisonic: true}, wav.SubFormatAmbisonicPCM},
+		{wav.Config{Channels: 9, SampleRate: 48000, BitDepth: 32, FloatingPoint: true, Format: wav.FormatIEEEFloat, Ambisonic: true}, wav.SubFormatAmbisonicFloat},
+		{wav.Config{Channels: 2, SampleRate: 44100, BitDepth: 24, Format: wav.FormatPCM, ChannelMask: wav.SpeakerFrontLeft | wav.SpeakerFrontRight}, [16]byte{0x01, 0, 0, 0, 0, 0, 0x10, 0, 0x80, 0, 0, 0xAA, 0, 0x38, 0x9B, 0x71}},
+	}
+
+	for _, test := range tests {
+		data := make([]byte, 10*test.cfg.Channels*test.cfg.BitDepth/8)
+
+		file, err := wav.New(test.cfg, data)
+		if err != nil {
+			t.Fatalf("creating file: %s", err.Error())
+		}
+
+		var buffer bytes.Buffer
+
+		if err := file.Encode(&buffer); err != nil {
+			t.Fatalf("encoding: %s", err.Error())
+		}
+
+		decoded := &wav.WAVEFileFormat{}
+
+		if err := decoded.Decode(&buffer); err != nil {
+			t.Fatalf("decoding: %s", err.Error())
+		}
+
+		if decoded.Config() != test.cfg {
+			t.Errorf("config: got %+v, want %+v", decoded.Config(), test.cfg)
+		}
+
+		if decoded.FormatChunk.SubFormat != test.subFormat {
+			t.Errorf("sub-format: got % X, want % X", decoded.FormatChunk.SubFormat, test.subFormat)
+		}
+
+		if decoded.Frames() != 10 {
+			t.Errorf("frames: got %d, want %d", decoded.Frames(), 10)
+		}
+
+		if _, err := decoded.SampleReader(); err != nil {
+			t.Errorf("sample reader: %s", err.Error())
+		}
+	}
+}
+
+func TestAmbisonicOrder(t *testing.T) {
+	for order := 1; order <= 7; order++ {
+		if got, err := wav.AmbisonicOrder(wav.AmbisonicChannels(order)); err != nil || got != order {
+			t.Errorf("order %d: got %d, %v", order, got, err)
+		}
+	}
+
+	for _, channels := range []int{1, 2, 6, 10} {
+		if _, err := wav.AmbisonicOrder(channels); !errors.Is(err, wav.ErrAmbisonicChannels) {
+			t.Errorf("%d channels: got %v, want %v", channels, err, wav.ErrAmbisonicChannels)
+		}
+	}
+
+	if _, err := wav.New(wav.Config{Channels: 6, SampleRate: 48000, BitDepth: 16, Ambisonic: true}, nil); !errors.Is(err, wav.ErrAmbisonicChannels) {
+		t.Errorf("new with 6 channels: got %v, want %v", err, wav.ErrAmbisonicChannels)
+	}
+}
@@ -0,0 +1,111 @@
+package wav
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+// analysisFrames is the number of frames decoded per read while analyzing.
+const analysisFrames = 4096
+
+// ChannelStatistics holds sample level statistics of a single channel. All
+// levels are linear, relative to full scale.
+type ChannelStatistics struct {
+	Peak           float64 // Largest absolute sample value
+	RMS            float64 // Root mean square
+	DCOffset       float64 // Mean sample value
+	ClippedSamples int     // Number of samples at or beyond full scale
+	LongestClipRun int     // Longest run of consecutive clipped samples
+}
+
+// PeakDBFS returns the peak level in dBFS.
+func (s ChannelStatistics) PeakDBFS() float64 {
+	return 20 * math.Log10(s.Peak)
+}
+
+// RMSDBFS returns the RMS level in dBFS.
+func (s ChannelStatistics) RMSDBFS() float64 {
+	return 20 * math.Log10(s.RMS)
+}
+
+// Analysis holds the statistics of the audio data.
+type Analysis struct {
+	Frames   int
+	Channels []ChannelStatistics
+}
+
+// Analyze decodes the header of the WAV file read from reader and streams
+// over the audio data to compute per channel statistics, without loading the
+// audio data into memory.
+func Analyze(reader io.Reader) (*Analysis, error) {
+	file, samples, err := DecodeSamples(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return analyze(samples, file.FormatChunk.fullScale())
+}
+
+// Analyze computes per channel statistics of the decoded audio data.
+func (f *WAVEFileFormat) Analyze() (*Analysis, error) {
+	samples, err := f.SampleReader()
+	if err != nil {
+		return nil, err
+	}
+
+	return analyze(samples, f.FormatChunk.fullScale())
+}
+
+func analyze(samples *SampleReader, fullScale float64) (*Analysis, error) {
+	channels := samples.Channels()
+
+	analysis := &Analysis{
+		Channels: make([]ChannelStatistics, channels),
+	}
+
+	sums := make([]float64, channels)
+	squares := make([]float64, channels)
+	clipRuns := make([]int, channels)
+
+	buffer := make([]float64, analysisFrames*channels)
+
+	for {
+		frames, err := samples.ReadFrames(buffer)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		for i, sample := range buffer[:frames*channels] {
+			channel := i % channels
+			statistics := &analysis.Channels[channel]
+
+			sums[channel] += sample
+			squares[channel] += sample * sample
+			statistics.Peak = max(statistics.Peak, math.Abs(sample))
+
+			if math.Abs(sample) >= fullScale {
+				statistics.ClippedSamples++
+				clipRuns[channel]++
+				statistics.LongestClipRun = max(statistics.LongestClipRun, clipRuns[channel])
+			} else {
+				clipRuns[channel] = 0
+			}
+		}
+
+		analysis.Frames += frames
+	}
+
+	if analysis.Frames == 0 {
+		return analysis, nil
+	}
+
+	for channel := range analysis.Channels {
+		analysis.Channels[channel].DCOffset = sums[channel] / float64(analysis.Frames)
+		analysis.Channels[channel].RMS = math.Sqrt(squares[channel] / float64(analysis.Frames))
+	}
+
+	return analysis, nil
+}
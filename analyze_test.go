@@ -0,0 +1,81 @@
+package wav_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestAnalyze(t *testing.T) {
+	// Left channel: constant offset, right channel: clipped square wave
+	left := []int16{1000, 1000, 1000, 1000, 1000, 1000}
+	right := []int16{math.MaxInt16, math.MaxInt16, math.MaxInt16, math.MinInt16, 0, math.MaxInt16}
+
+	data := make([]byte, 0, 4*len(left))
+	for i := range left {
+		data = binary.LittleEndian.AppendUint16(data, uint16(left[i]))
+		data = binary.LittleEndian.AppendUint16(data, uint16(right[i]))
+	}
+
+	waveFile, err := wav.New(wav.Config{Channels: 2, SampleRate: 48000, BitDepth: 16}, data)
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	analysis, err := wav.Analyze(encoded)
+	if err != nil {
+		t.Fatalf("analyzing wav file: %s", err.Error())
+	}
+
+	if analysis.Frames != len(left) {
+		t.Errorf("frames: got %d, want %d", analysis.Frames, len(left))
+	}
+
+	const offset = 1000.0 / 32768
+
+	if got := analysis.Channels[0]; math.Abs(got.DCOffset-offset) > 1e-9 || math.Abs(got.RMS-offset) > 1e-9 || math.Abs(got.Peak-offset) > 1e-9 {
+		t.Errorf("left channel: got %+v, want offset, rms and peak of %f", got, offset)
+	}
+
+	if got := analysis.Channels[1]; got.ClippedSamples != 5 || got.LongestClipRun != 4 || got.Peak != 1 {
+		t.Errorf("right channel: got %+v, want 5 clipped samples, longest run of 4 and peak of 1", got)
+	}
+}
+
+func TestAnalyzeFloat(t *testing.T) {
+	samples := []float32{0.5, -0.5, 1, 1, -0.25}
+
+	data := make([]byte, 0, 4*len(samples))
+	for _, sample := range samples {
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(sample))
+	}
+
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 44100, BitDepth: 32, FloatingPoint: true}, data)
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	analysis, err := wav.Analyze(encoded)
+	if err != nil {
+		t.Fatalf("analyzing wav file: %s", err.Error())
+	}
+
+	if got := analysis.Channels[0]; got.ClippedSamples != 2 || got.LongestClipRun != 2 || math.Abs(got.DCOffset-0.35) > 1e-9 {
+		t.Errorf("got %+v, want 2 clipped samples, longest run of 2 and offset of 0.35", got)
+	}
+}
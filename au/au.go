@@ -0,0 +1,223 @@
+// Package au converts Sun/NeXT .au files to and from WAV files. Audio data of
+// .au files is big endian, and the annotation is kept as LIST INFO comment.
+//
+// https://www.mmsp.ece.mcgill.ca/Documents/AudioFormats/AU/AU.html
+package au
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/samborkent/wav"
+)
+
+// Encodings of the audio data
+const (
+	EncodingMuLaw   = 1
+	EncodingPCM8    = 2
+	EncodingPCM16   = 3
+	EncodingPCM24   = 4
+	EncodingPCM32   = 5
+	EncodingFloat32 = 6
+	EncodingFloat64 = 7
+	EncodingALaw    = 27
+)
+
+// Size of the fixed header fields
+const HeaderSize = 24
+
+// Data size of files written without knowing it in advance
+const unknownSize = math.MaxUint32
+
+var (
+	ErrDecodeMagic          = errors.New("header magic number does not match '.snd'")
+	ErrDecodeOffset         = errors.New("header data offset is smaller than the header")
+	ErrEncodingNotSupported = errors.New("encoding is not supported")
+	ErrFormatNotSupported   = errors.New("sample format cannot be stored in .au files")
+)
+
+var layout = wav.RawLayout{BigEndian: true, Signed8: true}
+
+// Header is the header of an .au file.
+type Header struct {
+	DataOffset uint32
+	DataSize   uint32 // math.MaxUint32 if unknown
+	Encoding   uint32
+	SampleRate uint32
+	Channels   uint32
+	Annotation string // Without trailing null bytes
+}
+
+// Config returns the configuration describing the audio data.
+func (h *Header) Config() (wav.Config, error) {
+	cfg := wav.Config{
+		Channels:   int(h.Channels),
+		SampleRate: int(h.SampleRate),
+		Format:     wav.FormatPCM,
+	}
+
+	switch h.Encoding {
+	case EncodingMuLaw:
+		cfg.BitDepth, cfg.Format = 8, wav.FormatMuLaw
+	case EncodingALaw:
+		cfg.BitDepth, cfg.Format = 8, wav.FormatALaw
+	case EncodingPCM8, EncodingPCM16, EncodingPCM24, EncodingPCM32:
+		cfg.BitDepth = int(h.Encoding-EncodingPCM8+1) * 8
+	case EncodingFloat32, EncodingFloat64:
+		cfg.BitDepth = int(h.Encoding-EncodingFloat32+1) * 32
+		cfg.Format, cfg.FloatingPoint = wav.FormatIEEEFloat, true
+	default:
+		return wav.Config{}, fmt.Errorf("%w: %d", ErrEncodingNotSupported, h.Encoding)
+	}
+
+	return cfg, nil
+}
+
+// DecodeHeader decodes the header, leaving reader at the start of the audio
+// data.
+func DecodeHeader(reader io.Reader) (*Header, error) {
+	var fields [HeaderSize]byte
+
+	if _, err := io.ReadFull(reader, fields[:]); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	if [4]byte(fields[0:4]) != [4]byte{'.', 's', 'n', 'd'} {
+		return nil, ErrDecodeMagic
+	}
+
+	header := &Header{
+		DataOffset: binary.BigEndian.Uint32(fields[4:8]),
+		DataSize:   binary.BigEndian.Uint32(fields[8:12]),
+		Encoding:   binary.BigEndian.Uint32(fields[12:16]),
+		SampleRate: binary.BigEndian.Uint32(fields[16:20]),
+		Channels:   binary.BigEndian.Uint32(fields[20:24]),
+	}
+
+	if header.DataOffset < HeaderSize {
+		return nil, ErrDecodeOffset
+	}
+
+	// Read without allocating the untrusted size up front
+	size := int64(header.DataOffset - HeaderSize)
+
+	annotation, err := io.ReadAll(io.LimitReader(reader, size))
+	if err != nil {
+		return nil, fmt.Errorf("reading header: annotation: %w", err)
+	} else if int64(len(annotation)) < size {
+		return nil, fmt.Errorf("reading header: annotation: %w", io.ErrUnexpectedEOF)
+	}
+
+	header.Annotation = string(bytes.TrimRight(annotation, "\x00"))
+
+	return header, nil
+}
+
+// Decode decodes an .au file into a WAV file. Audio data of unknown size
+// extends to the end of reader.
+func Decode(reader io.Reader) (*wav.WAVEFileFormat, error) {
+	header, err := DecodeHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := header.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	if header.DataSize != unknownSize {
+		reader = io.LimitReader(reader, int64(header.DataSize))
+	}
+
+	file, err := wav.DecodeRaw(reader, cfg, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Annotation != "" {
+		info := &wav.InfoChunk{}
+		info.Set(wav.InfoComment, header.Annotation)
+
+		if err := file.SetInfo(info); err != nil {
+			return nil, err
+		}
+	}
+
+	return file, nil
+}
+
+// Encode encodes the WAV file as .au file, with its LIST INFO comment, if
+// any, as annotation.
+func Encode(writer io.Writer, file *wav.WAVEFileFormat) error {
+	cfg := file.Config()
+
+	header := &Header{
+		SampleRate: uint32(cfg.SampleRate),
+		Channels:   uint32(cfg.Channels),
+	}
+
+	switch {
+	case cfg.Format == wav.FormatMuLaw:
+		header.Encoding = EncodingMuLaw
+	case cfg.Format == wav.FormatALaw:
+		header.Encoding = EncodingALaw
+	case cfg.Format == wav.FormatPCM && cfg.BitDepth >= 8 && cfg.BitDepth <= 32:
+		header.Encoding = EncodingPCM8 + uint32(cfg.BitDepth/8-1)
+	case cfg.Format == wav.FormatIEEEFloat && (cfg.BitDepth == 32 || cfg.BitDepth == 64):
+		header.Encoding = EncodingFloat32 + uint32(cfg.BitDepth/32-1)
+	default:
+		return fmt.Errorf("%w: format 0x%04X with %d bits", ErrFormatNotSupported, cfg.Format, cfg.BitDepth)
+	}
+
+	info, err := file.Info()
+	if err == nil {
+		header.Annotation, _ = info.Get(wav.InfoComment)
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		return err
+	}
+
+	var data bytes.Buffer
+
+	if err := file.EncodeRaw(&data, layout); err != nil {
+		return err
+	}
+
+	header.DataSize = uint32(data.Len())
+
+	if err := header.Encode(writer); err != nil {
+		return err
+	}
+
+	if _, err := data.WriteTo(writer); err != nil {
+		return fmt.Errorf("writing audio data: %w", err)
+	}
+
+	return nil
+}
+
+// Encode encodes the header. The annotation is null terminated and padded to
+// a multiple of 8 bytes, and the data offset is set accordingly.
+func (h *Header) Encode(writer io.Writer) error {
+	h.DataOffset = uint32(HeaderSize + (len(h.Annotation)+8)/8*8)
+
+	data := make([]byte, 0, int(h.DataOffset))
+	data = append(data, '.', 's', 'n', 'd')
+	data = binary.BigEndian.AppendUint32(data, h.DataOffset)
+	data = binary.BigEndian.AppendUint32(data, h.DataSize)
+	data = binary.BigEndian.AppendUint32(data, h.Encoding)
+	data = binary.BigEndian.AppendUint32(data, h.SampleRate)
+	data = binary.BigEndian.AppendUint32(data, h.Channels)
+	data = append(data, h.Annotation...)
+	data = append(data, make([]byte, int(h.DataOffset)-len(data))...)
+
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	return nil
+}
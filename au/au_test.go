@@ -0,0 +1,107 @@
+package au_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/au"
+)
+
+func TestDecode(t *testing.T) {
+	var data bytes.Buffer
+
+	// Mono mu-law at 8 kHz of unknown size with an annotation
+	data.WriteString(".snd")
+	data.Write(binary.BigEndian.AppendUint32(nil, 32))
+	data.Write(binary.BigEndian.AppendUint32(nil, 0xFFFFFFFF))
+	data.Write(binary.BigEndian.AppendUint32(nil, au.EncodingMuLaw))
+	data.Write(binary.BigEndian.AppendUint32(nil, 8000))
+	data.Write(binary.BigEndian.AppendUint32(nil, 1))
+	data.WriteString("call\x00\x00\x00\x00")
+	data.Write([]byte{0xFF, 0x80, 0x00})
+
+	file, err := au.Decode(&data)
+	if err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	if want := (wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatMuLaw}); file.Config() != want {
+		t.Errorf("config: got %+v, want %+v", file.Config(), want)
+	}
+
+	if !bytes.Equal(file.DataChunk.Data, []byte{0xFF, 0x80, 0x00}) {
+		t.Errorf("audio data: got %v, want %v", file.DataChunk.Data, []byte{0xFF, 0x80, 0x00})
+	}
+
+	if info, err := file.Info(); err != nil {
+		t.Errorf("info: %s", err.Error())
+	} else if comment, _ := info.Get(wav.InfoComment); comment != "call" {
+		t.Errorf("comment: got %q, want %q", comment, "call")
+	}
+}
+
+func TestDecodeHeaderAnnotation(t *testing.T) {
+	var data bytes.Buffer
+
+	// Data offset of 4 GiB with a truncated annotation
+	data.WriteString(".snd")
+	data.Write(binary.BigEndian.AppendUint32(nil, 0xFFFFFFFF))
+	data.Write(binary.BigEndian.AppendUint32(nil, 0))
+	data.Write(binary.BigEndian.AppendUint32(nil, au.EncodingMuLaw))
+	data.Write(binary.BigEndian.AppendUint32(nil, 8000))
+	data.Write(binary.BigEndian.AppendUint32(nil, 1))
+	data.WriteString("call")
+
+	if _, err := au.DecodeHeader(&data); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("decoding truncated annotation: got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	configs := []wav.Config{
+		{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatPCM},
+		{Channels: 2, SampleRate: 44100, BitDepth: 16, Format: wav.FormatPCM},
+		{Channels: 1, SampleRate: 48000, BitDepth: 24, Format: wav.FormatPCM},
+		{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatALaw},
+		{Channels: 1, SampleRate: 96000, BitDepth: 64, Format: wav.FormatIEEEFloat, FloatingPoint: true},
+	}
+
+	for _, cfg := range configs {
+		data := make([]byte, 12*cfg.Channels*cfg.BitDepth/8)
+		for i := range data {
+			data[i] = byte(i * 13)
+		}
+
+		if cfg.Format == wav.FormatIEEEFloat {
+			clear(data)
+		}
+
+		original, err := wav.New(cfg, data)
+		if err != nil {
+			t.Fatalf("%+v: creating file: %s", cfg, err.Error())
+		}
+
+		var encoded bytes.Buffer
+
+		if err := au.Encode(&encoded, original); err != nil {
+			t.Fatalf("%+v: encoding: %s", cfg, err.Error())
+		}
+
+		if cfg.BitDepth == 16 && encoded.Bytes()[32] != data[1] {
+			t.Errorf("%+v: first sample is not big endian", cfg)
+		}
+
+		decoded, err := au.Decode(&encoded)
+		if err != nil {
+			t.Fatalf("%+v: decoding: %s", cfg, err.Error())
+		}
+
+		if decoded.Config() != cfg || !bytes.Equal(decoded.DataChunk.Data, data) {
+			t.Errorf("%+v: got %+v with different audio data after round trip", cfg, decoded.Config())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// EBU Tech 3285 broadcast wave format extension
+const (
+	BextChunkSize = 602 // Without coding history
+	BextVersion   = 2
+)
+
+// BextLoudnessUnknown marks a bext loudness field as not measured.
+const BextLoudnessUnknown = math.MaxInt16
+
+var ErrDecodeBextSize = errors.New("bext sub-chunk size must be at least 602 bytes")
+
+// BextChunk holds the broadcast audio extension metadata.
+type BextChunk struct {
+	Description          string   // ASCII, at most 256 characters
+	Originator           string   // ASCII, at most 32 characters
+	OriginatorReference  string   // ASCII, at most 32 characters
+	OriginationDate      string   // yyyy:mm:dd
+	OriginationTime      string   // hh:mm:ss
+	TimeReference        uint64   // Samples since midnight of the first sample
+	Version              uint16   // Version of the bext sub-chunk
+	UMID                 [64]byte // SMPTE 330M unique material identifier
+	LoudnessValue        int16    // Integrated loudness in 0.01 LUFS
+	LoudnessRange        int16    // Loudness range in 0.01 LU
+	MaxTruePeakLevel     int16    // Maximum true peak level in 0.01 dBTP
+	MaxMomentaryLoudness int16    // Highest momentary loudness in 0.01 LUFS
+	MaxShortTermLoudness int16    // Highest short-term loudness in 0.01 LUFS
+	CodingHistory        string   // ASCII
+}
+
+// Decode decodes the bext sub-chunk data.
+func (c *BextChunk) Decode(data []byte) error {
+	if len(data) < BextChunkSize {
+		return ErrDecodeBextSize
+	}
+
+	c.Description = decodeString(data[0:256])
+	c.Originator = decodeString(data[256:288])
+	c.OriginatorReference = decodeString(data[288:320])
+	c.OriginationDate = decodeString(data[320:330])
+	c.OriginationTime = decodeString(data[330:338])
+	c.TimeReference = binary.LittleEndian.Uint64(data[338:346])
+	c.Version = binary.LittleEndian.Uint16(data[346:348])
+	copy(c.UMID[:], data[348:412])
+	c.LoudnessValue = int16(binary.LittleEndian.Uint16(data[412:414]))
+	c.LoudnessRange = int16(binary.LittleEndian.Uint16(data[414:416]))
+	c.MaxTruePeakLevel = int16(binary.LittleEndian.Uint16(data[416:418]))
+	c.MaxMomentaryLoudness = int16(binary.LittleEndian.Uint16(data[418:420]))
+	c.MaxShortTermLoudness = int16(binary.LittleEndian.Uint16(data[420:422]))
+	// 180 reserved bytes
+	c.CodingHistory = decodeString(data[BextChunkSize:])
+
+	return nil
+}
+
+// Encode encodes the bext sub-chunk data. Strings exceeding their field are
+// truncated.
+func (c *BextChunk) Encode() []byte {
+	data := make([]byte, BextChunkSize, BextChunkSize+len(c.CodingHistory))
+
+	copy(data[0:256], c.Description)
+	copy(data[256:288], c.Originator)
+	copy(data[288:320], c.OriginatorReference)
+	copy(data[320:330], c.OriginationDate)
+	copy(data[330:338], c.OriginationTime)
+	binary.LittleEndian.PutUint64(data[338:346], c.TimeReference)
+	binary.LittleEndian.PutUint16(data[346:348], c.Version)
+	copy(data[348:412], c.UMID[:])
+	binary.LittleEndian.PutUint16(data[412:414], uint16(c.LoudnessValue))
+	binary.LittleEndian.PutUint16(data[414:416], uint16(c.LoudnessRange))
+	binary.LittleEndian.PutUint16(data[416:418], uint16(c.MaxTruePeakLevel))
+	binary.LittleEndian.PutUint16(data[418:420], uint16(c.MaxMomentaryLoudness))
+	binary.LittleEndian.PutUint16(data[420:422], uint16(c.MaxShortTermLoudness))
+
+	return append(data, c.CodingHistory...)
+}
+
+// Bext returns the decoded bext sub-chunk.
+func (f *WAVEFileFormat) Bext() (*BextChunk, error) {
+	data, err := f.SubChunk([4]byte{'b', 'e', 'x', 't'})
+	if err != nil {
+		return nil, err
+	}
+
+	bext := &BextChunk{}
+
+	if err := bext.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return bext, nil
+}
+
+// SetBext encodes the bext sub-chunk, replacing an existing one.
+func (f *WAVEFileFormat) SetBext(bext *BextChunk) error {
+	return f.SetSubChunk([4]byte{'b', 'e', 'x', 't'}, bext.Encode())
+}
+
+// decodeString decodes a fixed width or variable length text field, which is
+// terminated by a null character if shorter than the field.
+func decodeString(data []byte) string {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+
+	return string(data)
+}
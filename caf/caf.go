@@ -0,0 +1,540 @@
+// Package caf decodes and encodes Apple Core Audio Format files and converts
+// them to and from WAV files. Linear PCM, floating point and companded audio
+// data is converted without loss, and IMA4 audio data is decoded to 16-bit
+// PCM.
+//
+// https://developer.apple.com/library/archive/documentation/MusicAudio/Reference/CAFSpec/
+package caf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/samborkent/wav"
+)
+
+// Audio data formats
+var (
+	FormatLinearPCM = [4]byte{'l', 'p', 'c', 'm'}
+	FormatALaw      = [4]byte{'a', 'l', 'a', 'w'}
+	FormatMuLaw     = [4]byte{'u', 'l', 'a', 'w'}
+	FormatIMA4      = [4]byte{'i', 'm', 'a', '4'}
+)
+
+// Linear PCM format flags
+const (
+	FlagFloat        = 1 << 0
+	FlagLittleEndian = 1 << 1
+)
+
+const (
+	DescriptionSize  = 32
+	PacketHeaderSize = 24
+)
+
+// Data chunk size of files written without knowing it in advance
+const unknownSize = -1
+
+var (
+	ErrDecodeFileType           = errors.New("file type does not match 'caff'")
+	ErrDecodeVersion            = errors.New("file version is not supported")
+	ErrDecodeDescription        = errors.New("audio description chunk is missing or malformed")
+	ErrDecodeData               = errors.New("audio data chunk is missing or malformed")
+	ErrDecodePacketTable        = errors.New("packet table chunk is malformed")
+	ErrDecodeChannelLayout      = errors.New("channel layout chunk is malformed")
+	ErrDecodeInfo               = errors.New("information chunk is malformed")
+	ErrFormatNotSupported       = errors.New("audio data format is not supported")
+	ErrSampleRate               = errors.New("sample rate is not a whole number of hertz")
+	ErrSampleFormatNotSupported = errors.New("sample format cannot be stored in caf files")
+)
+
+// Description is the audio description chunk.
+type Description struct {
+	SampleRate       float64
+	FormatID         [4]byte
+	FormatFlags      uint32
+	BytesPerPacket   uint32 // Zero for variable packet sizes
+	FramesPerPacket  uint32 // Zero for variable packet durations
+	ChannelsPerFrame uint32
+	BitsPerChannel   uint32
+}
+
+// PacketTable is the packet table chunk, which holds the number of frames
+// to skip at the start and end of the audio data and, for variable packet
+// sizes or durations, the size and duration of each packet.
+type PacketTable struct {
+	Packets         int64
+	ValidFrames     int64
+	PrimingFrames   int32
+	RemainderFrames int32
+	Entries         []int64
+}
+
+// InfoEntry is a text field of the information chunk.
+type InfoEntry struct {
+	Key   string
+	Value string
+}
+
+// Chunk is a chunk not otherwise decoded, such as markers or regions.
+type Chunk struct {
+	ID   [4]byte
+	Data []byte
+}
+
+// File is a CAF file held in memory.
+type File struct {
+	Description
+	EditCount     uint32
+	Data          []byte         // Packets as stored
+	PacketTable   *PacketTable   // Optional
+	ChannelLayout *ChannelLayout // Optional
+	Info          []InfoEntry    // Optional
+	Chunks        []Chunk        // Optional
+}
+
+// Decode decodes a CAF file. Audio data of unknown size extends to the end of
+// reader.
+func (f *File) Decode(reader io.Reader) error {
+	var header [8]byte
+
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return fmt.Errorf("reading file header: %w", err)
+	}
+
+	if [4]byte(header[0:4]) != [4]byte{'c', 'a', 'f', 'f'} {
+		return ErrDecodeFileType
+	}
+
+	if binary.BigEndian.Uint16(header[4:6]) != 1 {
+		return ErrDecodeVersion
+	}
+
+	*f = File{}
+	foundDescription, foundData := false, false
+
+	for {
+		var chunk [12]byte
+
+		if _, err := io.ReadFull(reader, chunk[:]); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("reading chunk: %w", err)
+		}
+
+		id := [4]byte(chunk[0:4])
+		size := int64(binary.BigEndian.Uint64(chunk[4:12]))
+
+		var data []byte
+		var err error
+
+		if size == unknownSize && id == [4]byte{'d', 'a', 't', 'a'} {
+			data, err = io.ReadAll(io.LimitReader(reader, math.MaxUint32))
+		} else if size < 0 || size > math.MaxUint32 {
+			return fmt.Errorf("reading '%s' chunk: size %d is not supported", id[:], size)
+		} else {
+			data = make([]byte, size)
+			_, err = io.ReadFull(reader, data)
+		}
+
+		if err != nil {
+			return fmt.Errorf("reading '%s' chunk: %w", id[:], err)
+		}
+
+		switch id {
+		case [4]byte{'d', 'e', 's', 'c'}:
+			if err := f.Description.decode(data); err != nil {
+				return err
+			}
+
+			foundDescription = true
+		case [4]byte{'d', 'a', 't', 'a'}:
+			if len(data) < 4 {
+				return ErrDecodeData
+			}
+
+			f.EditCount = binary.BigEndian.Uint32(data[0:4])
+			f.Data = data[4:]
+			foundData = true
+		case [4]byte{'p', 'a', 'k', 't'}:
+			f.PacketTable = &PacketTable{}
+
+			if err := f.PacketTable.decode(data); err != nil {
+				return err
+			}
+		case [4]byte{'c', 'h', 'a', 'n'}:
+			f.ChannelLayout = &ChannelLayout{}
+
+			if err := f.ChannelLayout.decode(data); err != nil {
+				return err
+			}
+		case [4]byte{'i', 'n', 'f', 'o'}:
+			if f.Info, err = decodeInfo(data); err != nil {
+				return err
+			}
+		case [4]byte{'f', 'r', 'e', 'e'}:
+		default:
+			f.Chunks = append(f.Chunks, Chunk{ID: id, Data: data})
+		}
+	}
+
+	if !foundDescription {
+		return ErrDecodeDescription
+	}
+
+	if !foundData {
+		return ErrDecodeData
+	}
+
+	return nil
+}
+
+func (d *Description) decode(data []byte) error {
+	if len(data) != DescriptionSize {
+		return ErrDecodeDescription
+	}
+
+	*d = Description{
+		SampleRate:       math.Float64frombits(binary.BigEndian.Uint64(data[0:8])),
+		FormatID:         [4]byte(data[8:12]),
+		FormatFlags:      binary.BigEndian.Uint32(data[12:16]),
+		BytesPerPacket:   binary.BigEndian.Uint32(data[16:20]),
+		FramesPerPacket:  binary.BigEndian.Uint32(data[20:24]),
+		ChannelsPerFrame: binary.BigEndian.Uint32(data[24:28]),
+		BitsPerChannel:   binary.BigEndian.Uint32(data[28:32]),
+	}
+
+	if d.ChannelsPerFrame == 0 || d.SampleRate <= 0 {
+		return ErrDecodeDescription
+	}
+
+	return nil
+}
+
+func (d *Description) encode() []byte {
+	data := make([]byte, 0, DescriptionSize)
+	data = binary.BigEndian.AppendUint64(data, math.Float64bits(d.SampleRate))
+	data = append(data, d.FormatID[:]...)
+	data = binary.BigEndian.AppendUint32(data, d.FormatFlags)
+	data = binary.BigEndian.AppendUint32(data, d.BytesPerPacket)
+	data = binary.BigEndian.AppendUint32(data, d.FramesPerPacket)
+	data = binary.BigEndian.AppendUint32(data, d.ChannelsPerFrame)
+	data = binary.BigEndian.AppendUint32(data, d.BitsPerChannel)
+
+	return data
+}
+
+func (t *PacketTable) decode(data []byte) error {
+	if len(data) < PacketHeaderSize {
+		return ErrDecodePacketTable
+	}
+
+	t.Packets = int64(binary.BigEndian.Uint64(data[0:8]))
+	t.ValidFrames = int64(binary.BigEndian.Uint64(data[8:16]))
+	t.PrimingFrames = int32(binary.BigEndian.Uint32(data[16:20]))
+	t.RemainderFrames = int32(binary.BigEndian.Uint32(data[20:24]))
+
+	// Variable length integers of 7 bits per byte, most significant first
+	var value int64
+
+	for i, b := range data[PacketHeaderSize:] {
+		value = value<<7 | int64(b&0x7F)
+
+		if b&0x80 == 0 {
+			t.Entries = append(t.Entries, value)
+			value = 0
+		} else if i == len(data)-PacketHeaderSize-1 {
+			return ErrDecodePacketTable
+		}
+	}
+
+	return nil
+}
+
+func (t *PacketTable) encode() []byte {
+	data := make([]byte, 0, PacketHeaderSize)
+	data = binary.BigEndian.AppendUint64(data, uint64(t.Packets))
+	data = binary.BigEndian.AppendUint64(data, uint64(t.ValidFrames))
+	data = binary.BigEndian.AppendUint32(data, uint32(t.PrimingFrames))
+	data = binary.BigEndian.AppendUint32(data, uint32(t.RemainderFrames))
+
+	for _, entry := range t.Entries {
+		var encoded [10]byte
+
+		i := len(encoded) - 1
+		encoded[i] = byte(entry & 0x7F)
+
+		for entry >>= 7; entry > 0; entry >>= 7 {
+			i--
+			encoded[i] = byte(entry&0x7F) | 0x80
+		}
+
+		data = append(data, encoded[i:]...)
+	}
+
+	return data
+}
+
+func decodeInfo(data []byte) ([]InfoEntry, error) {
+	if len(data) < 4 {
+		return nil, ErrDecodeInfo
+	}
+
+	count := binary.BigEndian.Uint32(data[0:4])
+	strings := bytes.Split(data[4:], []byte{0})
+
+	// Null terminated strings leave an empty remainder
+	if uint64(len(strings)) < 2*uint64(count)+1 {
+		return nil, ErrDecodeInfo
+	}
+
+	entries := make([]InfoEntry, count)
+
+	for i := range entries {
+		entries[i] = InfoEntry{Key: string(strings[2*i]), Value: string(strings[2*i+1])}
+	}
+
+	return entries, nil
+}
+
+func encodeInfo(entries []InfoEntry) []byte {
+	data := binary.BigEndian.AppendUint32(nil, uint32(len(entries)))
+
+	for _, entry := range entries {
+		data = append(data, entry.Key...)
+		data = append(data, 0)
+		data = append(data, entry.Value...)
+		data = append(data, 0)
+	}
+
+	return data
+}
+
+// Encode encodes the file.
+func (f *File) Encode(writer io.Writer) error {
+	var buffer bytes.Buffer
+
+	buffer.Write([]byte{'c', 'a', 'f', 'f', 0, 1, 0, 0})
+
+	writeChunk(&buffer, [4]byte{'d', 'e', 's', 'c'}, f.Description.encode())
+
+	if f.ChannelLayout != nil {
+		writeChunk(&buffer, [4]byte{'c', 'h', 'a', 'n'}, f.ChannelLayout.encode())
+	}
+
+	if len(f.Info) > 0 {
+		writeChunk(&buffer, [4]byte{'i', 'n', 'f', 'o'}, encodeInfo(f.Info))
+	}
+
+	for _, chunk := range f.Chunks {
+		writeChunk(&buffer, chunk.ID, chunk.Data)
+	}
+
+	if f.PacketTable != nil {
+		writeChunk(&buffer, [4]byte{'p', 'a', 'k', 't'}, f.PacketTable.encode())
+	}
+
+	buffer.Write([]byte{'d', 'a', 't', 'a'})
+	buffer.Write(binary.BigEndian.AppendUint64(nil, uint64(4+len(f.Data))))
+	buffer.Write(binary.BigEndian.AppendUint32(nil, f.EditCount))
+
+	if _, err := buffer.WriteTo(writer); err != nil {
+		return fmt.Errorf("writing chunks: %w", err)
+	}
+
+	if _, err := writer.Write(f.Data); err != nil {
+		return fmt.Errorf("writing audio data: %w", err)
+	}
+
+	return nil
+}
+
+func writeChunk(buffer *bytes.Buffer, id [4]byte, data []byte) {
+	buffer.Write(id[:])
+	buffer.Write(binary.BigEndian.AppendUint64(nil, uint64(len(data))))
+	buffer.Write(data)
+}
+
+// Config returns the configuration of the audio data once converted to WAV,
+// where bit depths are rounded up to whole bytes and IMA4 is decoded to
+// 16-bit PCM.
+func (f *File) Config() (wav.Config, error) {
+	if f.SampleRate != math.Trunc(f.SampleRate) || f.SampleRate > math.MaxUint32 {
+		return wav.Config{}, fmt.Errorf("%w: %g", ErrSampleRate, f.SampleRate)
+	}
+
+	cfg := wav.Config{
+		Channels:   int(f.ChannelsPerFrame),
+		SampleRate: int(f.SampleRate),
+		Format:     wav.FormatPCM,
+	}
+
+	switch f.FormatID {
+	case FormatLinearPCM:
+		if f.FramesPerPacket != 1 || f.BytesPerPacket == 0 || f.BytesPerPacket%f.ChannelsPerFrame != 0 {
+			return wav.Config{}, fmt.Errorf("%w: %d bytes and %d frames per packet", ErrFormatNotSupported, f.BytesPerPacket, f.FramesPerPacket)
+		}
+
+		cfg.BitDepth = int(f.BytesPerPacket/f.ChannelsPerFrame) * 8
+
+		if f.FormatFlags&FlagFloat != 0 {
+			cfg.Format, cfg.FloatingPoint = wav.FormatIEEEFloat, true
+		}
+	case FormatALaw:
+		cfg.BitDepth, cfg.Format = 8, wav.FormatALaw
+	case FormatMuLaw:
+		cfg.BitDepth, cfg.Format = 8, wav.FormatMuLaw
+	case FormatIMA4:
+		cfg.BitDepth = 16
+	default:
+		return wav.Config{}, fmt.Errorf("%w: '%s'", ErrFormatNotSupported, f.FormatID[:])
+	}
+
+	if f.ChannelLayout != nil {
+		cfg.ChannelMask = f.ChannelLayout.ChannelMask(cfg.Channels)
+	}
+
+	return cfg, nil
+}
+
+// SampleReader returns a SampleReader over the audio data.
+func (f *File) SampleReader() (*wav.SampleReader, error) {
+	file, err := f.WAV()
+	if err != nil {
+		return nil, err
+	}
+
+	return file.SampleReader()
+}
+
+// WAV converts the file to WAV. The channel layout, if it can be expressed as
+// channel mask, selects the extensible format, and text fields of the
+// information chunk become LIST INFO fields. Other chunks are dropped.
+func (f *File) WAV() (*wav.WAVEFileFormat, error) {
+	cfg, err := f.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	var file *wav.WAVEFileFormat
+
+	if f.FormatID == FormatIMA4 {
+		file, err = wav.New(cfg, f.decodeIMA4())
+	} else {
+		layout := wav.RawLayout{BigEndian: f.FormatFlags&FlagLittleEndian == 0, Signed8: true}
+		file, err = wav.DecodeRaw(bytes.NewReader(f.Data), cfg, layout)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.exportInfo(file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// FromWAV converts a WAV file to CAF with little endian linear PCM, floating
+// point or companded audio data. It is the inverse of WAV for those formats.
+func FromWAV(file *wav.WAVEFileFormat) (*File, error) {
+	cfg := file.Config()
+
+	f := &File{
+		Description: Description{
+			SampleRate:       float64(cfg.SampleRate),
+			FormatID:         FormatLinearPCM,
+			FormatFlags:      FlagLittleEndian,
+			BytesPerPacket:   uint32(cfg.Channels * cfg.BitDepth / 8),
+			FramesPerPacket:  1,
+			ChannelsPerFrame: uint32(cfg.Channels),
+			BitsPerChannel:   uint32(cfg.BitDepth),
+		},
+	}
+
+	switch cfg.Format {
+	case wav.FormatPCM:
+	case wav.FormatIEEEFloat:
+		f.FormatFlags |= FlagFloat
+	case wav.FormatALaw:
+		f.FormatID, f.FormatFlags = FormatALaw, 0
+	case wav.FormatMuLaw:
+		f.FormatID, f.FormatFlags = FormatMuLaw, 0
+	default:
+		return nil, fmt.Errorf("%w: format 0x%04X", ErrSampleFormatNotSupported, cfg.Format)
+	}
+
+	var data bytes.Buffer
+
+	if err := file.EncodeRaw(&data, wav.RawLayout{Signed8: true}); err != nil {
+		return nil, err
+	}
+
+	f.Data = data.Bytes()
+
+	if cfg.ChannelMask != 0 {
+		f.ChannelLayout = &ChannelLayout{Tag: LayoutUseChannelBitmap, Bitmap: cfg.ChannelMask}
+	}
+
+	if err := f.importInfo(file); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// infoKeys maps information chunk keys to LIST INFO fields.
+var infoKeys = []struct {
+	key  string
+	info [4]byte
+}{
+	{"title", wav.InfoTitle},
+	{"artist", wav.InfoArtist},
+	{"album", wav.InfoProduct},
+	{"track number", wav.InfoTrackNumber},
+	{"genre", wav.InfoGenre},
+	{"comments", wav.InfoComment},
+	{"copyright", wav.InfoCopyright},
+	{"recorded date", wav.InfoCreationDate},
+	{"encoding application", wav.InfoSoftware},
+}
+
+func (f *File) exportInfo(file *wav.WAVEFileFormat) error {
+	info := &wav.InfoChunk{}
+
+	for _, entry := range f.Info {
+		for _, key := range infoKeys {
+			if entry.Key == key.key {
+				info.Set(key.info, entry.Value)
+			}
+		}
+	}
+
+	if len(info.Entries) == 0 {
+		return nil
+	}
+
+	return file.SetInfo(info)
+}
+
+func (f *File) importInfo(file *wav.WAVEFileFormat) error {
+	info, err := file.Info()
+	if errors.Is(err, wav.ErrSubChunkNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, key := range infoKeys {
+		if value, ok := info.Get(key.info); ok {
+			f.Info = append(f.Info, InfoEntry{Key: key.key, Value: value})
+		}
+	}
+
+	return nil
+}
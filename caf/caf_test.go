@@ -0,0 +1,163 @@
+package caf_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/caf"
+)
+
+func TestConversion(t *testing.T) {
+	configs := []wav.Config{
+		{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatPCM},
+		{Channels: 2, SampleRate: 44100, BitDepth: 16, Format: wav.FormatPCM},
+		{Channels: 2, SampleRate: 48000, BitDepth: 24, Format: wav.FormatPCM, ChannelMask: wav.SpeakerFrontLeft | wav.SpeakerFrontRight},
+		{Channels: 1, SampleRate: 96000, BitDepth: 32, Format: wav.FormatIEEEFloat, FloatingPoint: true},
+		{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatALaw},
+		{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatMuLaw},
+	}
+
+	for _, cfg := range configs {
+		data := make([]byte, 50*cfg.Channels*cfg.BitDepth/8)
+		for i := range data {
+			data[i] = byte(i * 29)
+		}
+
+		if cfg.Format == wav.FormatIEEEFloat {
+			for i := 0; i < len(data); i += 4 {
+				binary.LittleEndian.PutUint32(data[i:], math.Float32bits(float32(math.Sin(float64(i)))))
+			}
+		}
+
+		original, err := wav.New(cfg, data)
+		if err != nil {
+			t.Fatalf("%+v: creating file: %s", cfg, err.Error())
+		}
+
+		if err := original.SetInfo(&wav.InfoChunk{Entries: []wav.InfoEntry{{ID: wav.InfoTitle, Value: "memo"}}}); err != nil {
+			t.Fatalf("%+v: setting info: %s", cfg, err.Error())
+		}
+
+		file, err := caf.FromWAV(original)
+		if err != nil {
+			t.Fatalf("%+v: converting to caf: %s", cfg, err.Error())
+		}
+
+		var encoded bytes.Buffer
+
+		if err := file.Encode(&encoded); err != nil {
+			t.Fatalf("%+v: encoding: %s", cfg, err.Error())
+		}
+
+		decoded := &caf.File{}
+
+		if err := decoded.Decode(&encoded); err != nil {
+			t.Fatalf("%+v: decoding: %s", cfg, err.Error())
+		}
+
+		converted, err := decoded.WAV()
+		if err != nil {
+			t.Fatalf("%+v: converting to wav: %s", cfg, err.Error())
+		}
+
+		if got := converted.Config(); got != cfg {
+			t.Errorf("config: got %+v, want %+v", got, cfg)
+		}
+
+		if !bytes.Equal(converted.DataChunk.Data, data) {
+			t.Errorf("%+v: audio data differs after conversion", cfg)
+		}
+
+		if info, err := converted.Info(); err != nil {
+			t.Errorf("%+v: info: %s", cfg, err.Error())
+		} else if title, _ := info.Get(wav.InfoTitle); title != "memo" {
+			t.Errorf("%+v: title: got %q, want %q", cfg, title, "memo")
+		}
+	}
+}
+
+func TestBigEndian(t *testing.T) {
+	file := &caf.File{
+		Description: caf.Description{
+			SampleRate:       16000,
+			FormatID:         caf.FormatLinearPCM,
+			BytesPerPacket:   2,
+			FramesPerPacket:  1,
+			ChannelsPerFrame: 1,
+			BitsPerChannel:   16,
+		},
+		Data: []byte{0x40, 0x00, 0xC0, 0x00},
+	}
+
+	reader, err := file.SampleReader()
+	if err != nil {
+		t.Fatalf("creating sample reader: %s", err.Error())
+	}
+
+	samples := make([]float64, 2)
+
+	if _, err := reader.ReadFrames(samples); err != nil {
+		t.Fatalf("reading frames: %s", err.Error())
+	}
+
+	if samples[0] != 0.5 || samples[1] != -0.5 {
+		t.Errorf("samples: got %v, want [0.5 -0.5]", samples)
+	}
+}
+
+func TestChannelMask(t *testing.T) {
+	tests := []struct {
+		layout   caf.ChannelLayout
+		channels int
+		want     uint32
+	}{
+		{caf.ChannelLayout{Tag: caf.LayoutMono}, 1, wav.SpeakerFrontCenter},
+		{caf.ChannelLayout{Tag: caf.LayoutMPEG51A}, 6, 0x3F},
+		{caf.ChannelLayout{Tag: caf.LayoutMPEG51A}, 2, 0},
+		{caf.ChannelLayout{Tag: caf.LayoutUseChannelBitmap, Bitmap: 0x603}, 4, 0x603},
+		{caf.ChannelLayout{Descriptions: []caf.ChannelDescription{{Label: 1}, {Label: 2}, {Label: 4}}}, 3, 0x0B},
+		{caf.ChannelLayout{Descriptions: []caf.ChannelDescription{{Label: 2}, {Label: 1}}}, 2, 0},
+	}
+
+	for _, test := range tests {
+		if got := test.layout.ChannelMask(test.channels); got != test.want {
+			t.Errorf("%+v with %d channels: got 0x%X, want 0x%X", test.layout, test.channels, got, test.want)
+		}
+	}
+}
+
+func TestIMA4(t *testing.T) {
+	// Two packets of one channel, of which the first frame is priming
+	block := make([]byte, 34)
+	block[2] = 0x04
+
+	file := &caf.File{
+		Description: caf.Description{
+			SampleRate:       8000,
+			FormatID:         caf.FormatIMA4,
+			BytesPerPacket:   34,
+			FramesPerPacket:  64,
+			ChannelsPerFrame: 1,
+		},
+		Data:        append(bytes.Clone(block), block...),
+		PacketTable: &caf.PacketTable{Packets: 2, ValidFrames: 100, PrimingFrames: 1},
+	}
+
+	converted, err := file.WAV()
+	if err != nil {
+		t.Fatalf("converting: %s", err.Error())
+	}
+
+	if converted.Frames() != 100 {
+		t.Errorf("frames: got %d, want 100", converted.Frames())
+	}
+
+	// Nibble 4 adds the step of 7, after which the step index rises to 2
+	// and nibble 0 adds an eighth of the step of 9
+	if got := int16(binary.LittleEndian.Uint16(converted.DataChunk.Data)); got != 8 {
+		t.Errorf("first frame: got %d, want 8", got)
+	}
+}
@@ -0,0 +1,140 @@
+package caf
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+
+	"github.com/samborkent/wav"
+)
+
+// Channel layout tags, of which the predefined layouts hold the number of
+// channels in the lower 16 bits
+const (
+	LayoutUseChannelDescriptions = 0
+	LayoutUseChannelBitmap       = 1 << 16
+	LayoutMono                   = 100<<16 | 1
+	LayoutStereo                 = 101<<16 | 2
+	LayoutStereoHeadphones       = 102<<16 | 2
+	LayoutQuadraphonic           = 108<<16 | 4
+	LayoutMPEG30A                = 113<<16 | 3
+	LayoutMPEG40A                = 115<<16 | 4
+	LayoutMPEG50A                = 117<<16 | 5
+	LayoutMPEG51A                = 121<<16 | 6
+	LayoutMPEG61A                = 125<<16 | 7
+)
+
+// Size of a channel description
+const channelDescriptionSize = 20
+
+// ChannelLayout is the channel layout chunk.
+type ChannelLayout struct {
+	Tag          uint32
+	Bitmap       uint32 // Speaker positions if Tag is LayoutUseChannelBitmap
+	Descriptions []ChannelDescription
+}
+
+// ChannelDescription describes a channel if the layout tag is
+// LayoutUseChannelDescriptions.
+type ChannelDescription struct {
+	Label       uint32
+	Flags       uint32
+	Coordinates [3]float32
+}
+
+// layoutMasks holds the channel mask of the predefined layouts.
+var layoutMasks = map[uint32]uint32{
+	LayoutMono:             wav.SpeakerFrontCenter,
+	LayoutStereo:           wav.SpeakerFrontLeft | wav.SpeakerFrontRight,
+	LayoutStereoHeadphones: wav.SpeakerFrontLeft | wav.SpeakerFrontRight,
+	LayoutQuadraphonic:     wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerBackLeft | wav.SpeakerBackRight,
+	LayoutMPEG30A:          wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerFrontCenter,
+	LayoutMPEG40A:          wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerFrontCenter | wav.SpeakerBackCenter,
+	LayoutMPEG50A:          wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerFrontCenter | wav.SpeakerBackLeft | wav.SpeakerBackRight,
+	LayoutMPEG51A:          wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerFrontCenter | wav.SpeakerLowFrequency | wav.SpeakerBackLeft | wav.SpeakerBackRight,
+	LayoutMPEG61A:          wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerFrontCenter | wav.SpeakerLowFrequency | wav.SpeakerBackLeft | wav.SpeakerBackRight | wav.SpeakerBackCenter,
+}
+
+func (l *ChannelLayout) decode(data []byte) error {
+	if len(data) < 12 {
+		return ErrDecodeChannelLayout
+	}
+
+	l.Tag = binary.BigEndian.Uint32(data[0:4])
+	l.Bitmap = binary.BigEndian.Uint32(data[4:8])
+
+	count := int(binary.BigEndian.Uint32(data[8:12]))
+	if count > (len(data)-12)/channelDescriptionSize {
+		return ErrDecodeChannelLayout
+	}
+
+	l.Descriptions = make([]ChannelDescription, count)
+
+	for i := range l.Descriptions {
+		description := data[12+i*channelDescriptionSize:]
+
+		l.Descriptions[i] = ChannelDescription{
+			Label: binary.BigEndian.Uint32(description[0:4]),
+			Flags: binary.BigEndian.Uint32(description[4:8]),
+		}
+
+		for axis := range l.Descriptions[i].Coordinates {
+			value := binary.BigEndian.Uint32(description[8+4*axis:])
+			l.Descriptions[i].Coordinates[axis] = math.Float32frombits(value)
+		}
+	}
+
+	return nil
+}
+
+func (l *ChannelLayout) encode() []byte {
+	data := make([]byte, 0, 12+len(l.Descriptions)*channelDescriptionSize)
+	data = binary.BigEndian.AppendUint32(data, l.Tag)
+	data = binary.BigEndian.AppendUint32(data, l.Bitmap)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(l.Descriptions)))
+
+	for _, description := range l.Descriptions {
+		data = binary.BigEndian.AppendUint32(data, description.Label)
+		data = binary.BigEndian.AppendUint32(data, description.Flags)
+
+		for _, coordinate := range description.Coordinates {
+			data = binary.BigEndian.AppendUint32(data, math.Float32bits(coordinate))
+		}
+	}
+
+	return data
+}
+
+// ChannelMask returns the WAV channel mask of the layout for the number of
+// channels, or zero if the layout has no equivalent. Channel labels map onto
+// speaker positions only if they are in the order of the mask bits.
+func (l *ChannelLayout) ChannelMask(channels int) uint32 {
+	var mask uint32
+
+	switch l.Tag {
+	case LayoutUseChannelBitmap:
+		mask = l.Bitmap
+	case LayoutUseChannelDescriptions:
+		for _, description := range l.Descriptions {
+			// Labels from left to top back right match the mask bits
+			if description.Label < 1 || description.Label > 18 {
+				return 0
+			}
+
+			speaker := uint32(1) << (description.Label - 1)
+			if speaker <= mask {
+				return 0
+			}
+
+			mask |= speaker
+		}
+	default:
+		mask = layoutMasks[l.Tag]
+	}
+
+	if bits.OnesCount32(mask) != channels {
+		return 0
+	}
+
+	return mask
+}
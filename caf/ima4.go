@@ -0,0 +1,83 @@
+package caf
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// IMA4 packets hold 64 frames of each channel in consecutive blocks
+const (
+	ima4Frames    = 64
+	ima4BlockSize = 34
+)
+
+var imaStepTable = [89]int32{
+	7, 8, 9, 10, 11, 12, 13, 14, 16, 17,
+	19, 21, 23, 25, 28, 31, 34, 37, 41, 45,
+	50, 55, 60, 66, 73, 80, 88, 97, 107, 118,
+	130, 143, 157, 173, 190, 209, 230, 253, 279, 307,
+	337, 371, 408, 449, 494, 544, 598, 658, 724, 796,
+	876, 963, 1060, 1166, 1282, 1411, 1552, 1707, 1878, 2066,
+	2272, 2499, 2749, 3024, 3327, 3660, 4026, 4428, 4871, 5358,
+	5894, 6484, 7132, 7845, 8630, 9493, 10442, 11487, 12635, 13899,
+	15289, 16818, 18500, 20350, 22385, 24623, 27086, 29794, 32767,
+}
+
+var imaIndexTable = [16]int{-1, -1, -1, -1, 2, 4, 6, 8, -1, -1, -1, -1, 2, 4, 6, 8}
+
+// decodeIMA4 decodes IMA4 packets to interleaved little endian 16-bit PCM,
+// without the priming and remainder frames of the packet table.
+func (f *File) decodeIMA4() []byte {
+	channels := int(f.ChannelsPerFrame)
+	packets := len(f.Data) / (channels * ima4BlockSize)
+	data := make([]byte, packets*ima4Frames*channels*2)
+
+	for packet := range packets {
+		for channel := range channels {
+			block := f.Data[(packet*channels+channel)*ima4BlockSize:][:ima4BlockSize]
+
+			// Predictor in the upper 9 bits and step index in the lower 7 bits
+			header := binary.BigEndian.Uint16(block[0:2])
+			predictor := int32(int16(header & 0xFF80))
+			index := min(int(header&0x7F), len(imaStepTable)-1)
+
+			for i := range ima4Frames {
+				// Low nibble first
+				nibble := block[2+i/2] >> (4 * (i % 2)) & 0x0F
+				step := imaStepTable[index]
+
+				diff := step >> 3
+				if nibble&1 != 0 {
+					diff += step >> 2
+				}
+
+				if nibble&2 != 0 {
+					diff += step >> 1
+				}
+
+				if nibble&4 != 0 {
+					diff += step
+				}
+
+				if nibble&8 != 0 {
+					diff = -diff
+				}
+
+				predictor = min(max(predictor+diff, math.MinInt16), math.MaxInt16)
+				index = min(max(index+imaIndexTable[nibble], 0), len(imaStepTable)-1)
+
+				offset := ((packet*ima4Frames+i)*channels + channel) * 2
+				binary.LittleEndian.PutUint16(data[offset:], uint16(int16(predictor)))
+			}
+		}
+	}
+
+	if f.PacketTable == nil {
+		return data
+	}
+
+	start := min(max(int(f.PacketTable.PrimingFrames), 0), packets*ima4Frames)
+	end := min(start+max(int(f.PacketTable.ValidFrames), 0), packets*ima4Frames)
+
+	return data[start*channels*2 : end*channels*2]
+}
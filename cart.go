@@ -0,0 +1,142 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// AES46 radio traffic data extension
+const (
+	CartChunkSize  = 2048 // Without tag text
+	CartVersion    = "0101"
+	CartPostTimers = 8
+)
+
+var ErrDecodeCartSize = errors.New("cart sub-chunk size must be at least 2048 bytes")
+
+// CartTimer is a post timer marking a position in the audio data, e.g. the
+// end of an intro.
+type CartTimer struct {
+	Usage string // Four characters, e.g. INT or SEG, empty if unused
+	Value uint32 // Sample frames from the start of the audio data
+}
+
+// CartChunk holds the AES46 cart metadata used by radio automation systems.
+type CartChunk struct {
+	Version            string // ASCII, four digits, e.g. 0101 for 1.01
+	Title              string // ASCII, at most 64 characters
+	Artist             string // ASCII, at most 64 characters
+	CutID              string // ASCII, at most 64 characters
+	ClientID           string // ASCII, at most 64 characters
+	Category           string // ASCII, at most 64 characters
+	Classification     string // ASCII, at most 64 characters
+	OutCue             string // ASCII, at most 64 characters
+	StartDate          string // yyyy/mm/dd
+	StartTime          string // hh:mm:ss
+	EndDate            string // yyyy/mm/dd
+	EndTime            string // hh:mm:ss
+	ProducerAppID      string // ASCII, at most 64 characters
+	ProducerAppVersion string // ASCII, at most 64 characters
+	UserDef            string // ASCII, at most 64 characters
+	LevelReference     int32  // Sample value of 0 dB reference level
+	PostTimers         [CartPostTimers]CartTimer
+	URL                string // ASCII, at most 1024 characters
+	TagText            string // ASCII, free form lines ending in CR LF
+}
+
+// Decode decodes the cart sub-chunk data.
+func (c *CartChunk) Decode(data []byte) error {
+	if len(data) < CartChunkSize {
+		return ErrDecodeCartSize
+	}
+
+	c.Version = decodeString(data[0:4])
+	c.Title = decodeString(data[4:68])
+	c.Artist = decodeString(data[68:132])
+	c.CutID = decodeString(data[132:196])
+	c.ClientID = decodeString(data[196:260])
+	c.Category = decodeString(data[260:324])
+	c.Classification = decodeString(data[324:388])
+	c.OutCue = decodeString(data[388:452])
+	c.StartDate = decodeString(data[452:462])
+	c.StartTime = decodeString(data[462:470])
+	c.EndDate = decodeString(data[470:480])
+	c.EndTime = decodeString(data[480:488])
+	c.ProducerAppID = decodeString(data[488:552])
+	c.ProducerAppVersion = decodeString(data[552:616])
+	c.UserDef = decodeString(data[616:680])
+	c.LevelReference = int32(binary.LittleEndian.Uint32(data[680:684]))
+
+	for i := range c.PostTimers {
+		timer := data[684+8*i : 692+8*i]
+
+		c.PostTimers[i] = CartTimer{
+			Usage: decodeString(timer[0:4]),
+			Value: binary.LittleEndian.Uint32(timer[4:8]),
+		}
+	}
+
+	// 276 reserved bytes
+	c.URL = decodeString(data[1024:2048])
+	c.TagText = decodeString(data[CartChunkSize:])
+
+	return nil
+}
+
+// Encode encodes the cart sub-chunk data. Strings exceeding their field are
+// truncated, and an empty version is encoded as CartVersion.
+func (c *CartChunk) Encode() []byte {
+	data := make([]byte, CartChunkSize, CartChunkSize+len(c.TagText))
+
+	version := c.Version
+	if version == "" {
+		version = CartVersion
+	}
+
+	copy(data[0:4], version)
+	copy(data[4:68], c.Title)
+	copy(data[68:132], c.Artist)
+	copy(data[132:196], c.CutID)
+	copy(data[196:260], c.ClientID)
+	copy(data[260:324], c.Category)
+	copy(data[324:388], c.Classification)
+	copy(data[388:452], c.OutCue)
+	copy(data[452:462], c.StartDate)
+	copy(data[462:470], c.StartTime)
+	copy(data[470:480], c.EndDate)
+	copy(data[480:488], c.EndTime)
+	copy(data[488:552], c.ProducerAppID)
+	copy(data[552:616], c.ProducerAppVersion)
+	copy(data[616:680], c.UserDef)
+	binary.LittleEndian.PutUint32(data[680:684], uint32(c.LevelReference))
+
+	for i, timer := range c.PostTimers {
+		copy(data[684+8*i:688+8*i], timer.Usage)
+		binary.LittleEndian.PutUint32(data[688+8*i:692+8*i], timer.Value)
+	}
+
+	copy(data[1024:2048], c.URL)
+
+	return append(data, c.TagText...)
+}
+
+// Cart returns the decoded cart sub-chunk.
+func (f *WAVEFileFormat) Cart() (*CartChunk, error) {
+	data, err := f.SubChunk([4]byte{'c', 'a', 'r', 't'})
+	if err != nil {
+		return nil, err
+	}
+
+	cart := &CartChunk{}
+
+	if err := cart.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// SetCart encodes the cart sub-chunk, replacing an existing one.
+func (f *WAVEFileFormat) SetCart(cart *CartChunk) error {
+	return f.SetSubChunk([4]byte{'c', 'a', 'r', 't'}, cart.Encode())
+}
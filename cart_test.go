@@ -0,0 +1,111 @@
+package wav_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+// testCart returns a cart sub-chunk laid out by hand following AES46.
+func testCart() []byte {
+	data := make([]byte, 2048)
+
+	// Version, Title, Artist, CutID, ClientID, Category, Classification
+	// and OutCue
+	copy(data[0:], "0101")
+	copy(data[4:], "Morning jingle")
+	copy(data[68:], "Station")
+	copy(data[132:], "J0042")
+	copy(data[196:], "CL7")
+	copy(data[260:], "JINGLE")
+	copy(data[324:], "Promo")
+	copy(data[388:], "fades out")
+
+	// StartDate, StartTime, EndDate and EndTime
+	copy(data[452:], "2024/01/01")
+	copy(data[462:], "06:00:00")
+	copy(data[470:], "2024/12/31")
+	copy(data[480:], "23:59:59")
+
+	// ProducerAppID, ProducerAppVersion, UserDef and LevelReference
+	copy(data[488:], "Playout")
+	copy(data[552:], "3.1")
+	copy(data[616:], "user")
+	binary.LittleEndian.PutUint32(data[680:], 0xFFFFFF00)
+
+	// First two post timers, followed by reserved bytes and the URL
+	copy(data[684:], "INT")
+	binary.LittleEndian.PutUint32(data[688:], 4000)
+	copy(data[692:], "SEG")
+	binary.LittleEndian.PutUint32(data[696:], 12000)
+	copy(data[1024:], "https://example.com/j0042")
+
+	return append(data, "spot\r\n"...)
+}
+
+func TestCart(t *testing.T) {
+	layout := testCart()
+
+	cart := &wav.CartChunk{}
+
+	if err := cart.Decode(layout); err != nil {
+		t.Fatalf("decoding cart: %s", err.Error())
+	}
+
+	want := wav.CartChunk{
+		Version:            "0101",
+		Title:              "Morning jingle",
+		Artist:             "Station",
+		CutID:              "J0042",
+		ClientID:           "CL7",
+		Category:           "JINGLE",
+		Classification:     "Promo",
+		OutCue:             "fades out",
+		StartDate:          "2024/01/01",
+		StartTime:          "06:00:00",
+		EndDate:            "2024/12/31",
+		EndTime:            "23:59:59",
+		ProducerAppID:      "Playout",
+		ProducerAppVersion: "3.1",
+		UserDef:            "user",
+		LevelReference:     -256,
+		URL:                "https://example.com/j0042",
+		TagText:            "spot\r\n",
+	}
+	want.PostTimers[0] = wav.CartTimer{Usage: "INT", Value: 4000}
+	want.PostTimers[1] = wav.CartTimer{Usage: "SEG", Value: 12000}
+
+	if *cart != want {
+		t.Errorf("cart: got %+v, want %+v", *cart, want)
+	}
+
+	if encoded := cart.Encode(); !bytes.Equal(encoded, layout) {
+		t.Errorf("encoded cart does not match the AES46 layout")
+	}
+
+	if encoded := (&wav.CartChunk{}).Encode(); string(encoded[0:4]) != wav.CartVersion || len(encoded) != wav.CartChunkSize {
+		t.Errorf("encoded empty cart: got version %q and %d bytes, want %q and %d bytes", encoded[0:4], len(encoded), wav.CartVersion, wav.CartChunkSize)
+	}
+
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, []byte{1, 2})
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	if err := waveFile.SetCart(cart); err != nil {
+		t.Fatalf("setting cart sub-chunk: %s", err.Error())
+	}
+
+	if data, err := waveFile.SubChunk([4]byte{'c', 'a', 'r', 't'}); err != nil {
+		t.Fatalf("cart sub-chunk: %s", err.Error())
+	} else if !bytes.Equal(data, layout) {
+		t.Errorf("cart sub-chunk does not match the AES46 layout")
+	}
+
+	if err := (&wav.CartChunk{}).Decode(layout[:wav.CartChunkSize-1]); !errors.Is(err, wav.ErrDecodeCartSize) {
+		t.Errorf("decoding short cart: got %v, want %v", err, wav.ErrDecodeCartSize)
+	}
+}
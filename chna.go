@@ -0,0 +1,121 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+const ChnaAudioIDSize = 40
+
+var (
+	ErrDecodeChnaSize = errors.New("chna sub-chunk size does not match its number of audio ids")
+	ErrChnaTooLarge   = errors.New("chna sub-chunk exceeds 65535 audio ids")
+)
+
+// ChnaAudioID allocates a track of the audio data to an ADM audioTrackUID.
+type ChnaAudioID struct {
+	TrackIndex     uint16 // Track in the audio data, starting at 1
+	UID            string // audioTrackUID, e.g. ATU_00000001
+	TrackFormatRef string // audioTrackFormatID, e.g. AT_00010001_01
+	PackFormatRef  string // audioPackFormatID, e.g. AP_00010002
+}
+
+// ChnaChunk holds the channel allocation of BW64 files, which links the
+// tracks of the audio data to the ADM metadata of the axml sub-chunk.
+type ChnaChunk struct {
+	AudioIDs []ChnaAudioID
+}
+
+// Decode decodes the chna sub-chunk data. Unused audio ids, which have a
+// track index of zero, are skipped.
+func (c *ChnaChunk) Decode(data []byte) error {
+	if len(data) < 4 {
+		return ErrDecodeChnaSize
+	}
+
+	// Number of tracks and audio ids
+	count := int(binary.LittleEndian.Uint16(data[2:4]))
+	data = data[4:]
+
+	if len(data) < count*ChnaAudioIDSize {
+		return ErrDecodeChnaSize
+	}
+
+	c.AudioIDs = nil
+
+	for i := range count {
+		entry := data[i*ChnaAudioIDSize : (i+1)*ChnaAudioIDSize]
+
+		id := ChnaAudioID{
+			TrackIndex:     binary.LittleEndian.Uint16(entry[0:2]),
+			UID:            decodeString(entry[2:14]),
+			TrackFormatRef: decodeString(entry[14:28]),
+			PackFormatRef:  decodeString(entry[28:39]),
+			// Padding byte
+		}
+
+		if id.TrackIndex != 0 {
+			c.AudioIDs = append(c.AudioIDs, id)
+		}
+	}
+
+	return nil
+}
+
+// Encode encodes the chna sub-chunk data. References exceeding their field
+// are truncated.
+func (c *ChnaChunk) Encode() []byte {
+	data := make([]byte, 4, 4+len(c.AudioIDs)*ChnaAudioIDSize)
+	binary.LittleEndian.PutUint16(data[0:2], uint16(c.Tracks()))
+	binary.LittleEndian.PutUint16(data[2:4], uint16(len(c.AudioIDs)))
+
+	for _, id := range c.AudioIDs {
+		entry := make([]byte, ChnaAudioIDSize)
+
+		binary.LittleEndian.PutUint16(entry[0:2], id.TrackIndex)
+		copy(entry[2:14], id.UID)
+		copy(entry[14:28], id.TrackFormatRef)
+		copy(entry[28:39], id.PackFormatRef)
+
+		data = append(data, entry...)
+	}
+
+	return data
+}
+
+// Tracks returns the number of distinct tracks with audio ids.
+func (c *ChnaChunk) Tracks() int {
+	tracks := make(map[uint16]struct{}, len(c.AudioIDs))
+
+	for _, id := range c.AudioIDs {
+		tracks[id.TrackIndex] = struct{}{}
+	}
+
+	return len(tracks)
+}
+
+// Chna returns the decoded chna sub-chunk.
+func (f *WAVEFileFormat) Chna() (*ChnaChunk, error) {
+	data, err := f.SubChunk([4]byte{'c', 'h', 'n', 'a'})
+	if err != nil {
+		return nil, err
+	}
+
+	chna := &ChnaChunk{}
+
+	if err := chna.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return chna, nil
+}
+
+// SetChna encodes the chna sub-chunk, replacing an existing one.
+func (f *WAVEFileFormat) SetChna(chna *ChnaChunk) error {
+	if len(chna.AudioIDs) > math.MaxUint16 {
+		return ErrChnaTooLarge
+	}
+
+	return f.SetSubChunk([4]byte{'c', 'h', 'n', 'a'}, chna.Encode())
+}
@@ -0,0 +1,357 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var ErrSubChunkNotFound = errors.New("sub-chunk not found")
+
+// SubChunk is a RIFF sub-chunk that is stored as is, such as a metadata chunk.
+type SubChunk struct {
+	Chunk
+	Data []byte // Without padding byte
+}
+
+func decodeSubChunk(reader io.Reader, chunk Chunk) (SubChunk, error) {
+	subChunk := SubChunk{
+		Chunk: chunk,
+		Data:  make([]byte, binary.LittleEndian.Uint32(chunk.Size[:])),
+	}
+
+	if _, err := io.ReadFull(reader, subChunk.Data); err != nil {
+		return SubChunk{}, fmt.Errorf("reading '%s' sub-chunk: data: %w", chunk.ID[:], err)
+	}
+
+	// Padding byte, which is often omitted at the end of the file
+	if len(subChunk.Data)%2 != 0 {
+		if _, err := io.ReadFull(reader, make([]byte, 1)); err != nil && !errors.Is(err, io.EOF) {
+			return SubChunk{}, fmt.Errorf("reading '%s' sub-chunk: padding byte: %w", chunk.ID[:], err)
+		}
+	}
+
+	return subChunk, nil
+}
+
+// Encode writes the sub-chunk including its padding byte.
+func (c *SubChunk) Encode(writer io.Writer) error {
+	n, err := writer.Write(c.Chunk.ID[:])
+	if err != nil {
+		return fmt.Errorf("writing '%s' sub-chunk: id: %w", c.Chunk.ID[:], err)
+	} else if n != len(c.Chunk.ID) {
+		return fmt.Errorf("writing '%s' sub-chunk: id: %w", c.Chunk.ID[:], io.ErrShortWrite)
+	}
+
+	n, err = writer.Write(c.Chunk.Size[:])
+	if err != nil {
+		return fmt.Errorf("writing '%s' sub-chunk: size: %w", c.Chunk.ID[:], err)
+	} else if n != len(c.Chunk.Size) {
+		return fmt.Errorf("writing '%s' sub-chunk: size: %w", c.Chunk.ID[:], io.ErrShortWrite)
+	}
+
+	n, err = writer.Write(c.Data)
+	if err != nil {
+		return fmt.Errorf("writing '%s' sub-chunk: data: %w", c.Chunk.ID[:], err)
+	} else if n != len(c.Data) {
+		return fmt.Errorf("writing '%s' sub-chunk: data: %w", c.Chunk.ID[:], io.ErrShortWrite)
+	}
+
+	if len(c.Data)%2 != 0 {
+		n, err = writer.Write([]byte{0})
+		if err != nil {
+			return fmt.Errorf("writing '%s' sub-chunk: padding byte: %w", c.Chunk.ID[:], err)
+		} else if n != 1 {
+			return fmt.Errorf("writing '%s' sub-chunk: padding byte: %w", c.Chunk.ID[:], io.ErrShortWrite)
+		}
+	}
+
+	return nil
+}
+
+// size returns the size of the sub-chunk data including its padding byte.
+func (c *SubChunk) size() uint32 {
+	return uint32(len(c.Data) + len(c.Data)%2)
+}
+
+// SubChunk returns the data of the first sub-chunk with the given ID.
+func (f *WAVEFileFormat) SubChunk(id [4]byte) ([]byte, error) {
+	for _, subChunk := range f.SubChunks {
+		if subChunk.Chunk.ID == id {
+			return subChunk.Data, nil
+		}
+	}
+
+	return nil, fmt.Errorf("'%s': %w", id[:], ErrSubChunkNotFound)
+}
+
+// SetSubChunk replaces the data of the first sub-chunk with the given ID, or
+// appends a new sub-chunk, and updates the RIFF chunk size.
+func (f *WAVEFileFormat) SetSubChunk(id [4]byte, data []byte) error {
+	if len(data) > math.MaxUint32-1 {
+		return ErrDataTooLarge
+	}
+
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(data)))
+
+	subChunk := SubChunk{
+		Chunk: Chunk{
+			ID:   id,
+			Size: size,
+		},
+		Data: data,
+	}
+
+	index := -1
+
+	for i := range f.SubChunks {
+		if f.SubChunks[i].Chunk.ID == id {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		f.SubChunks = append(f.SubChunks, subChunk)
+	} else {
+		f.SubChunks[index] = subChunk
+	}
+
+	return f.updateSize()
+}
+
+// RemoveSubChunk removes all sub-chunks with the given ID and updates the
+// RIFF chunk size.
+func (f *WAVEFileFormat) RemoveSubChunk(id [4]byte) error {
+	subChunks := f.SubChunks[:0]
+
+	for _, subChunk := range f.SubChunks {
+		if subChunk.Chunk.ID != id {
+			subChunks = append(subChunks, subChunk)
+		}
+	}
+
+	f.SubChunks = subChunks
+
+	return f.updateSize()
+}
+
+// ListChunk returns the data following the list type of the first LIST
+// sub-chunk of the given type, such as INFO.
+func (f *WAVEFileFormat) ListChunk(listType [4]byte) ([]byte, error) {
+	if i := f.listChunk(listType); i >= 0 {
+		return f.SubChunks[i].Data[4:], nil
+	}
+
+	return nil, fmt.Errorf("LIST '%s': %w", listType[:], ErrSubChunkNotFound)
+}
+
+// SetListChunk replaces the data of the first LIST sub-chunk of the given
+// type, or appends a new LIST sub-chunk, and updates the RIFF chunk size.
+func (f *WAVEFileFormat) SetListChunk(listType [4]byte, data []byte) error {
+	if len(data) > math.MaxUint32-5 {
+		return ErrDataTooLarge
+	}
+
+	subChunk := SubChunk{
+		Chunk: Chunk{
+			ID: [4]byte{'L', 'I', 'S', 'T'},
+		},
+		Data: append(listType[:], data...),
+	}
+
+	binary.LittleEndian.PutUint32(subChunk.Chunk.Size[:], uint32(len(subChunk.Data)))
+
+	if i := f.listChunk(listType); i >= 0 {
+		f.SubChunks[i] = subChunk
+	} else {
+		f.SubChunks = append(f.SubChunks, subChunk)
+	}
+
+	return f.updateSize()
+}
+
+// RemoveListChunk removes all LIST sub-chunks of the given type and updates
+// the RIFF chunk size.
+func (f *WAVEFileFormat) RemoveListChunk(listType [4]byte) error {
+	subChunks := f.SubChunks[:0]
+
+	for _, subChunk := range f.SubChunks {
+		if !subChunk.isList(listType) {
+			subChunks = append(subChunks, subChunk)
+		}
+	}
+
+	f.SubChunks = subChunks
+
+	return f.updateSize()
+}
+
+func (f *WAVEFileFormat) listChunk(listType [4]byte) int {
+	for i := range f.SubChunks {
+		if f.SubChunks[i].isList(listType) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (c *SubChunk) isList(listType [4]byte) bool {
+	return c.Chunk.ID == [4]byte{'L', 'I', 'S', 'T'} && len(c.Data) >= 4 && [4]byte(c.Data[0:4]) == listType
+}
+
+// updateSize recalculates the RIFF chunk size from the sizes of all
+// sub-chunks, as written by Encode.
+func (f *WAVEFileFormat) updateSize() error {
+	formatSize := uint64(binary.LittleEndian.Uint32(f.FormatChunk.Chunk.Size[:]))
+	size := 4 + 8 + formatSize + formatSize%2
+
+	if f.FactChunk.Chunk.ID == [4]byte{'f', 'a', 'c', 't'} {
+		size += 8 + FactChunkSize
+	}
+
+	for i := range f.SubChunks {
+		size += 8 + uint64(f.SubChunks[i].size())
+	}
+
+	size += 8 + uint64(len(f.DataChunk.Data)+len(f.DataChunk.Data)%2)
+
+	if size > math.MaxUint32 {
+		return ErrDataTooLarge
+	}
+
+	binary.LittleEndian.PutUint32(f.RIFFChunk.Chunk.Size[:], uint32(size))
+
+	return nil
+}
+
+// withData returns a copy of the file, including its sub-chunks, holding the
+// given audio data with all sizes recalculated.
+func (f *WAVEFileFormat) withData(data []byte) (*WAVEFileFormat, error) {
+	if uint64(len(data)) > math.MaxUint32-1 {
+		return nil, ErrDataTooLarge
+	}
+
+	file := &WAVEFileFormat{
+		RIFFChunk:   f.RIFFChunk,
+		FormatChunk: f.FormatChunk,
+		FactChunk:   f.FactChunk,
+		DataChunk: DataChunk{
+			Chunk: f.DataChunk.Chunk,
+			Data:  data,
+		},
+		SubChunks: make([]SubChunk, len(f.SubChunks)),
+	}
+
+	for i, subChunk := range f.SubChunks {
+		file.SubChunks[i] = SubChunk{
+			Chunk: subChunk.Chunk,
+			Data:  bytes.Clone(subChunk.Data),
+		}
+	}
+
+	binary.LittleEndian.PutUint32(file.DataChunk.Chunk.Size[:], uint32(len(data)))
+
+	if file.FactChunk.Chunk.ID == [4]byte{'f', 'a', 'c', 't'} {
+		binary.LittleEndian.PutUint32(file.FactChunk.SampleLength[:], uint32(file.Frames()))
+	}
+
+	if err := file.updateSize(); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ChunkInfo describes the location of a chunk in a file.
+type ChunkInfo struct {
+	ID     [4]byte
+	Offset int64  // Offset of the chunk header from the start of the file
+	Size   uint32 // Size of the chunk data, without padding byte
+}
+
+// ScanChunks lists the RIFF chunk and all of its sub-chunks in file order,
+// without decoding or validating them. Sub-chunk data is skipped by seeking
+// if reader implements io.Seeker. If the file is truncated, the chunks found
+// so far are returned together with the error.
+func ScanChunks(reader io.Reader) ([]ChunkInfo, error) {
+	var header [12]byte
+
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, fmt.Errorf("reading riff chunk: %w", err)
+	}
+
+	chunks := []ChunkInfo{{
+		ID:     [4]byte(header[0:4]),
+		Offset: 0,
+		Size:   binary.LittleEndian.Uint32(header[4:8]),
+	}}
+
+	if chunks[0].ID != [4]byte{'R', 'I', 'F', 'F'} {
+		return nil, ErrDecodeRIFFID
+	}
+
+	offset := int64(len(header))
+
+	// Seeking beyond the end of the file does not fail, so the end is
+	// needed to detect truncated sub-chunks
+	seeker, canSeek := reader.(io.Seeker)
+	end := int64(math.MaxInt64)
+
+	if canSeek {
+		current, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("seeking: %w", err)
+		}
+
+		if end, err = seeker.Seek(0, io.SeekEnd); err != nil {
+			return nil, fmt.Errorf("seeking: %w", err)
+		}
+
+		// End relative to the start of the RIFF chunk
+		end -= current - offset
+
+		if _, err := seeker.Seek(current, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("seeking: %w", err)
+		}
+	}
+
+	for {
+		var chunk [8]byte
+
+		if _, err := io.ReadFull(reader, chunk[:]); errors.Is(err, io.EOF) {
+			return chunks, nil
+		} else if err != nil {
+			return chunks, fmt.Errorf("reading sub-chunk at offset %d: %w", offset, err)
+		}
+
+		info := ChunkInfo{
+			ID:     [4]byte(chunk[0:4]),
+			Offset: offset,
+			Size:   binary.LittleEndian.Uint32(chunk[4:8]),
+		}
+
+		chunks = append(chunks, info)
+
+		skip := int64(info.Size) + int64(info.Size%2)
+		offset += 8 + skip
+
+		if canSeek {
+			if _, err := seeker.Seek(skip, io.SeekCurrent); err != nil {
+				return chunks, fmt.Errorf("skipping '%s' sub-chunk: %w", info.ID[:], err)
+			}
+
+			// The padding byte of the last sub-chunk is often omitted
+			if offset-int64(info.Size%2) > end {
+				return chunks, fmt.Errorf("skipping '%s' sub-chunk: %w", info.ID[:], io.ErrUnexpectedEOF)
+			}
+		} else if n, err := io.CopyN(io.Discard, reader, skip); err != nil && n != int64(info.Size) {
+			return chunks, fmt.Errorf("skipping '%s' sub-chunk: %w", info.ID[:], io.ErrUnexpectedEOF)
+		}
+	}
+}
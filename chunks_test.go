@@ -0,0 +1,80 @@
+package wav_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestSubChunks(t *testing.T) {
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, []byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	if err := waveFile.SetSubChunk([4]byte{'t', 'e', 's', 't'}, []byte("odd")); err != nil {
+		t.Fatalf("setting sub-chunk: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	if encoded.Len() != 8+waveFile.Size() {
+		t.Errorf("encoded size: got %d, want %d", encoded.Len(), 8+waveFile.Size())
+	}
+
+	chunks, err := wav.ScanChunks(bytes.NewReader(encoded.Bytes()))
+	if err != nil {
+		t.Fatalf("scanning chunks: %s", err.Error())
+	}
+
+	want := []wav.ChunkInfo{
+		{ID: [4]byte{'R', 'I', 'F', 'F'}, Offset: 0, Size: uint32(waveFile.Size())},
+		{ID: [4]byte{'f', 'm', 't', ' '}, Offset: 12, Size: wav.FormatChunkSizePCM},
+		{ID: [4]byte{'t', 'e', 's', 't'}, Offset: 36, Size: 3},
+		{ID: [4]byte{'d', 'a', 't', 'a'}, Offset: 48, Size: 3},
+	}
+
+	if !slices.Equal(chunks, want) {
+		t.Errorf("chunks: got %v, want %v", chunks, want)
+	}
+
+	decoded := &wav.WAVEFileFormat{}
+
+	if err := decoded.DecodeMetadata(bytes.NewReader(encoded.Bytes())); err != nil {
+		t.Fatalf("decoding metadata: %s", err.Error())
+	}
+
+	if data, err := decoded.SubChunk([4]byte{'t', 'e', 's', 't'}); err != nil {
+		t.Errorf("decoding sub-chunk: %s", err.Error())
+	} else if string(data) != "odd" {
+		t.Errorf("sub-chunk data: got %q, want %q", data, "odd")
+	}
+
+	if decoded.DataSize() != 3 || decoded.Data() != nil {
+		t.Errorf("data: got size %d and %v, want size 3 and no data", decoded.DataSize(), decoded.Data())
+	}
+
+	if err := decoded.Encode(io.Discard); !errors.Is(err, wav.ErrDataNotLoaded) {
+		t.Errorf("encoding without audio data: got %v, want %v", err, wav.ErrDataNotLoaded)
+	}
+
+	if err := decoded.Decode(bytes.NewReader(encoded.Bytes())); err != nil {
+		t.Fatalf("decoding wav file: %s", err.Error())
+	}
+
+	if !bytes.Equal(decoded.Data(), []byte{1, 2, 3}) {
+		t.Errorf("data: got %v, want %v", decoded.Data(), []byte{1, 2, 3})
+	}
+
+	if len(decoded.SubChunks) != 1 {
+		t.Errorf("sub-chunks after decoding twice: got %d, want 1", len(decoded.SubChunks))
+	}
+}
@@ -0,0 +1,365 @@
+// Command wavconv converts the sample format, sample rate and channel layout
+// of a WAV file. The audio data is streamed, so inputs of any size can be
+// converted. Metadata sub-chunks are copied to the output, with sample
+// positions converted to the output sample rate. The ADM sub-chunks are
+// dropped if the channels change.
+//
+// Usage:
+//
+//	wavconv [-format pcm|float|alaw|mulaw] [-bits n] [-rate hz] [-channels n] in.wav out.wav
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/internal/cli"
+)
+
+// Frames converted per block
+const blockFrames = 4096
+
+var formats = map[string]uint16{
+	"pcm":   wav.FormatPCM,
+	"float": wav.FormatIEEEFloat,
+	"alaw":  wav.FormatALaw,
+	"mulaw": wav.FormatMuLaw,
+}
+
+func main() {
+	format := flag.String("format", "", "sample format: pcm, float, alaw or mulaw (default: input format)")
+	bits := flag.Int("bits", 0, "bits per sample (default: input bit depth, or 8 for A-law and mu-law)")
+	rate := flag.Int("rate", 0, "sample rate in Hz (default: input sample rate)")
+	channels := flag.Int("channels", 0, "number of channels (default: input channels)")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: wavconv [flags] in.wav out.wav\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	formatTag := uint16(wav.FormatUnknown)
+
+	if *format != "" {
+		var ok bool
+
+		formatTag, ok = formats[*format]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "wavconv: unknown format %q\n", *format)
+			os.Exit(2)
+		}
+	}
+
+	cfg := wav.Config{
+		Channels:   *channels,
+		SampleRate: *rate,
+		BitDepth:   *bits,
+		Format:     formatTag,
+	}
+
+	if err := convert(flag.Arg(0), flag.Arg(1), cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "wavconv: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// convert converts the input file to the output configuration, where zero
+// values are taken from the input.
+func convert(inputPath, outputPath string, cfg wav.Config) error {
+	if same, err := cli.SamePath(inputPath, outputPath); err != nil {
+		return err
+	} else if same {
+		return errors.New("input and output must be different files")
+	}
+
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	// Metadata may follow the audio data, so it is decoded first
+	metadata := &wav.WAVEFileFormat{}
+
+	if err := metadata.DecodeMetadata(input); err != nil {
+		return fmt.Errorf("decoding %s: %w", inputPath, err)
+	}
+
+	if _, err := input.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	inputFile, reader, err := wav.DecodeSamples(input)
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", inputPath, err)
+	}
+
+	inputConfig := inputFile.Config()
+	cfg = outputConfig(inputConfig, cfg)
+
+	header, err := wav.New(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	header.SubChunks = metadata.SubChunks
+
+	if err := scaleTimeReference(header, inputConfig.SampleRate, cfg.SampleRate); err != nil {
+		return err
+	}
+
+	if err := scalePositions(header, inputConfig.SampleRate, cfg.SampleRate); err != nil {
+		return err
+	}
+
+	// Track allocations of the input channels do not apply to the output
+	if inputConfig.Channels != cfg.Channels {
+		if err := header.RemoveSubChunk([4]byte{'c', 'h', 'n', 'a'}); err != nil {
+			return err
+		}
+
+		if err := header.RemoveSubChunk([4]byte{'a', 'x', 'm', 'l'}); err != nil {
+			return err
+		}
+	}
+
+	output, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+
+	if err := stream(reader, output, header, inputConfig, cfg); err != nil {
+		output.Close()
+		os.Remove(outputPath)
+
+		return err
+	}
+
+	return output.Close()
+}
+
+func stream(reader *wav.SampleReader, output io.WriteSeeker, header *wav.WAVEFileFormat, inputConfig, cfg wav.Config) error {
+	writer, err := wav.NewWriter(output, header)
+	if err != nil {
+		return err
+	}
+
+	matrix := mixMatrix(inputConfig.Channels, cfg.Channels)
+	resampler := wav.NewResampler(cfg.Channels, inputConfig.SampleRate, cfg.SampleRate)
+
+	buffer := make([]float64, blockFrames*inputConfig.Channels)
+	mixed := make([]float64, 0, blockFrames*cfg.Channels)
+	var resampled []float64
+
+	for {
+		frames, err := reader.ReadFrames(buffer)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		mixed = mix(mixed[:0], buffer[:frames*inputConfig.Channels], matrix)
+		resampled = resampler.Resample(resampled[:0], mixed)
+
+		if _, err := writer.WriteFrames(resampled); err != nil {
+			return err
+		}
+	}
+
+	if _, err := writer.WriteFrames(resampler.Flush(resampled[:0])); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// outputConfig fills the unset fields of cfg from the input configuration,
+// keeping the speaker layout if the number of channels is unchanged.
+func outputConfig(input, cfg wav.Config) wav.Config {
+	if cfg.Channels == 0 {
+		cfg.Channels = input.Channels
+	}
+
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = input.SampleRate
+	}
+
+	if cfg.Format == wav.FormatUnknown {
+		cfg.Format = input.Format
+	}
+
+	if cfg.BitDepth == 0 {
+		switch {
+		case cfg.Format == wav.FormatALaw || cfg.Format == wav.FormatMuLaw:
+			cfg.BitDepth = 8
+		case cfg.Format == wav.FormatIEEEFloat && input.Format != wav.FormatIEEEFloat:
+			cfg.BitDepth = 32
+		case cfg.Format == wav.FormatPCM && input.Format != wav.FormatPCM:
+			cfg.BitDepth = 16
+		default:
+			cfg.BitDepth = input.BitDepth
+		}
+	}
+
+	cfg.FloatingPoint = cfg.Format == wav.FormatIEEEFloat
+
+	// The speaker layout only applies to the same channels
+	if cfg.Channels == input.Channels {
+		cfg.ChannelMask = input.ChannelMask
+		cfg.Ambisonic = input.Ambisonic
+	}
+
+	return cfg
+}
+
+// mixMatrix returns the gain of each input channel in each output channel.
+// Mono is spread over all outputs and all inputs are averaged into mono;
+// 5.1 is downmixed to stereo with the ITU-R BS.775 coefficients. Other
+// layouts keep the common channels and leave the rest silent.
+func mixMatrix(inputs, outputs int) [][]float64 {
+	matrix := make([][]float64, outputs)
+	for output := range matrix {
+		matrix[output] = make([]float64, inputs)
+	}
+
+	switch {
+	case inputs == outputs:
+		for channel := range outputs {
+			matrix[channel][channel] = 1
+		}
+	case inputs == 1:
+		for output := range outputs {
+			matrix[output][0] = 1
+		}
+	case outputs == 1:
+		for input := range inputs {
+			matrix[0][input] = 1 / float64(inputs)
+		}
+	case inputs == 6 && outputs == 2:
+		// L, R, C, LFE, Ls, Rs, normalized to avoid clipping
+		const center = math.Sqrt2 / 2
+		const gain = 1 / (1 + 2*center)
+
+		matrix[0] = []float64{gain, 0, center * gain, 0, center * gain, 0}
+		matrix[1] = []float64{0, gain, center * gain, 0, 0, center * gain}
+	default:
+		for channel := range min(inputs, outputs) {
+			matrix[channel][channel] = 1
+		}
+	}
+
+	return matrix
+}
+
+// mix appends the input frames mixed to the output channels to dst.
+func mix(dst, input []float64, matrix [][]float64) []float64 {
+	inputs := len(matrix[0])
+
+	for frame := 0; frame+inputs <= len(input); frame += inputs {
+		for _, gains := range matrix {
+			var sample float64
+
+			for channel, gain := range gains {
+				sample += gain * input[frame+channel]
+			}
+
+			dst = append(dst, sample)
+		}
+	}
+
+	return dst
+}
+
+// scaleTimeReference converts the bext time reference, which is counted in
+// samples, to the output sample rate.
+func scaleTimeReference(header *wav.WAVEFileFormat, inputRate, outputRate int) error {
+	if inputRate == outputRate {
+		return nil
+	}
+
+	bext, err := header.Bext()
+	if errors.Is(err, wav.ErrSubChunkNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	bext.TimeReference = uint64(math.Round(float64(bext.TimeReference) * float64(outputRate) / float64(inputRate)))
+
+	return header.SetBext(bext)
+}
+
+// scalePositions converts the cue points, region and playlist lengths and
+// cart post timers, which are counted in frames, to the output sample rate.
+func scalePositions(header *wav.WAVEFileFormat, inputRate, outputRate int) error {
+	if inputRate == outputRate {
+		return nil
+	}
+
+	scale := func(frames uint32) uint32 {
+		return uint32(min(math.Round(float64(frames)*float64(outputRate)/float64(inputRate)), math.MaxUint32))
+	}
+
+	if cue, err := header.Cue(); err == nil {
+		for i := range cue.Points {
+			cue.Points[i].Position = scale(cue.Points[i].Position)
+			cue.Points[i].SampleOffset = scale(cue.Points[i].SampleOffset)
+		}
+
+		if err := header.SetCue(cue); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		return err
+	}
+
+	if adtl, err := header.AssociatedData(); err == nil {
+		for i := range adtl.Regions {
+			adtl.Regions[i].SampleLength = scale(adtl.Regions[i].SampleLength)
+		}
+
+		if err := header.SetAssociatedData(adtl); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		return err
+	}
+
+	if playlist, err := header.Playlist(); err == nil {
+		for i := range playlist.Segments {
+			playlist.Segments[i].Length = scale(playlist.Segments[i].Length)
+		}
+
+		if err := header.SetPlaylist(playlist); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		return err
+	}
+
+	if cart, err := header.Cart(); err == nil {
+		for i := range cart.PostTimers {
+			cart.PostTimers[i].Value = scale(cart.PostTimers[i].Value)
+		}
+
+		if err := header.SetCart(cart); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		return err
+	}
+
+	return nil
+}
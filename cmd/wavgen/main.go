@@ -0,0 +1,124 @@
+// Command wavgen writes test signals to a WAV file: sine tones, sine
+// sweeps, white and pink noise, impulses and channel identification tones.
+//
+// Usage:
+//
+//	wavgen [-signal sine|sweep|linear-sweep|white|pink|impulse|id] [flags] out.wav
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/generator"
+)
+
+var formats = map[string]uint16{
+	"pcm":   wav.FormatPCM,
+	"float": wav.FormatIEEEFloat,
+	"alaw":  wav.FormatALaw,
+	"mulaw": wav.FormatMuLaw,
+}
+
+var signals = map[string]func(generator.Config) (*generator.Signal, error){
+	"sine":         generator.Sine,
+	"sweep":        func(cfg generator.Config) (*generator.Signal, error) { return generator.Sweep(cfg, true) },
+	"linear-sweep": func(cfg generator.Config) (*generator.Signal, error) { return generator.Sweep(cfg, false) },
+	"white":        generator.WhiteNoise,
+	"pink":         generator.PinkNoise,
+	"impulse":      generator.Impulse,
+	"id":           generator.ChannelID,
+}
+
+func main() {
+	signalName := flag.String("signal", "sine", "signal: sine, sweep, linear-sweep, white, pink, impulse or id")
+	frequency := flag.Float64("freq", 1000, "frequency in Hz, start frequency of sweeps or impulse rate (0 for a single impulse)")
+	endFrequency := flag.Float64("end-freq", 20000, "end frequency of sweeps in Hz")
+	level := flag.Float64("level", -6, "peak level in dBFS")
+	duration := flag.Duration("duration", 10*time.Second, "duration, per channel for identification tones")
+	rate := flag.Int("rate", 48000, "sample rate in Hz")
+	channels := flag.Int("channels", 1, "number of channels")
+	format := flag.String("format", "pcm", "sample format: pcm, float, alaw or mulaw")
+	bits := flag.Int("bits", 0, "bits per sample (default: 24 for pcm, 32 for float, 8 for A-law and mu-law)")
+	seed := flag.Uint64("seed", 1, "seed of noise signals")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: wavgen [flags] out.wav\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	generate, ok := signals[*signalName]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "wavgen: unknown signal %q\n", *signalName)
+		os.Exit(2)
+	}
+
+	formatTag, ok := formats[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "wavgen: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+
+	if *bits == 0 {
+		switch formatTag {
+		case wav.FormatPCM:
+			*bits = 24
+		case wav.FormatIEEEFloat:
+			*bits = 32
+		default:
+			*bits = 8
+		}
+	}
+
+	signal, err := generate(generator.Config{
+		Channels:     *channels,
+		SampleRate:   *rate,
+		Duration:     *duration,
+		Amplitude:    math.Pow(10, *level/20),
+		Frequency:    *frequency,
+		EndFrequency: *endFrequency,
+		Seed:         *seed,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wavgen: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	cfg := wav.Config{
+		BitDepth:      *bits,
+		FloatingPoint: formatTag == wav.FormatIEEEFloat,
+		Format:        formatTag,
+	}
+
+	if err := write(flag.Arg(0), signal, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "wavgen: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func write(path string, signal *generator.Signal, cfg wav.Config) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := generator.Write(file, signal, cfg); err != nil {
+		file.Close()
+		os.Remove(path)
+
+		return err
+	}
+
+	return file.Close()
+}
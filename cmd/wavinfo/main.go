@@ -0,0 +1,638 @@
+// Command wavinfo prints the format, every chunk and the decoded metadata of
+// a WAV file.
+//
+// Usage:
+//
+//	wavinfo [-json] file.wav
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samborkent/wav"
+)
+
+var formatNames = map[uint16]string{
+	wav.FormatUnknown:    "unknown",
+	wav.FormatPCM:        "PCM",
+	wav.FormatIEEEFloat:  "IEEE float",
+	wav.FormatALaw:       "A-law",
+	wav.FormatMuLaw:      "mu-law",
+	wav.FormatMPEG:       "MPEG",
+	wav.FormatMP3:        "MPEG layer 3",
+	wav.FormatAAC:        "AAC",
+	wav.FormatHEAAC:      "HE-AAC",
+	wav.FormatOpus:       "Opus",
+	wav.FormatMPEG4:      "MPEG-4",
+	wav.FormatFLAC:       "FLAC",
+	wav.FormatExtensible: "extensible",
+}
+
+type info struct {
+	File     string      `json:"file"`
+	Size     int64       `json:"size"`
+	Format   *formatInfo `json:"format,omitempty"`
+	Chunks   []chunkInfo `json:"chunks"`
+	Metadata metadata    `json:"metadata"`
+	Errors   []string    `json:"errors,omitempty"`
+}
+
+type formatInfo struct {
+	Name               string  `json:"name"`
+	Tag                uint16  `json:"tag"`
+	Channels           int     `json:"channels"`
+	SampleRate         int     `json:"sampleRate"`
+	ByteRate           int     `json:"byteRate"`
+	BlockAlign         int     `json:"blockAlign"`
+	BitsPerSample      int     `json:"bitsPerSample"`
+	ExtensionSize      *int    `json:"extensionSize,omitempty"`
+	ValidBitsPerSample *int    `json:"validBitsPerSample,omitempty"`
+	ChannelMask        *uint32 `json:"channelMask,omitempty"`
+	SubFormat          string  `json:"subFormat,omitempty"`
+	SubFormatName      string  `json:"subFormatName,omitempty"`
+	AmbisonicOrder     *int    `json:"ambisonicOrder,omitempty"`
+	Extension          string  `json:"extension,omitempty"`
+	SampleLength       *uint32 `json:"sampleLength,omitempty"`
+	Frames             int     `json:"frames"`
+	Duration           float64 `json:"duration"`
+}
+
+type chunkInfo struct {
+	ID     string `json:"id"`
+	Offset int64  `json:"offset"`
+	Size   uint32 `json:"size"`
+}
+
+type metadata struct {
+	Info []textField    `json:"info,omitempty"`
+	Bext *bextInfo      `json:"bext,omitempty"`
+	Cart []textField    `json:"cart,omitempty"`
+	Acid []textField    `json:"acid,omitempty"`
+	IXML []textField    `json:"ixml,omitempty"`
+	Cues []cueInfo      `json:"cues,omitempty"`
+	Plst []playlistInfo `json:"playlist,omitempty"`
+	ADM  []admTrack     `json:"adm,omitempty"`
+}
+
+// admTrack is a track of the chna sub-chunk with the names of its axml objects.
+type admTrack struct {
+	Track      uint16   `json:"track"`
+	UID        string   `json:"uid"`
+	PackFormat string   `json:"packFormat"`
+	Objects    []string `json:"objects,omitempty"`
+}
+
+type playlistInfo struct {
+	CueID   uint32 `json:"cueId"`
+	Length  uint32 `json:"length"`
+	Repeats uint32 `json:"repeats"`
+}
+
+type cueInfo struct {
+	ID     uint32 `json:"id"`
+	Frame  uint32 `json:"frame"`
+	Label  string `json:"label,omitempty"`
+	Length uint32 `json:"length,omitempty"`
+}
+
+// textField is a LIST INFO field, a cart or acid field or an iXML element
+// value.
+type textField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type bextInfo struct {
+	Description          string `json:"description"`
+	Originator           string `json:"originator"`
+	OriginatorReference  string `json:"originatorReference"`
+	OriginationDate      string `json:"originationDate"`
+	OriginationTime      string `json:"originationTime"`
+	TimeReference        uint64 `json:"timeReference"`
+	Version              uint16 `json:"version"`
+	UMID                 string `json:"umid"`
+	LoudnessValue        int16  `json:"loudnessValue"`
+	LoudnessRange        int16  `json:"loudnessRange"`
+	MaxTruePeakLevel     int16  `json:"maxTruePeakLevel"`
+	MaxMomentaryLoudness int16  `json:"maxMomentaryLoudness"`
+	MaxShortTermLoudness int16  `json:"maxShortTermLoudness"`
+	CodingHistory        string `json:"codingHistory"`
+}
+
+func main() {
+	jsonOutput := flag.Bool("json", false, "print as JSON")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: wavinfo [-json] file.wav\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fileInfo, err := inspect(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wavinfo: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(fileInfo); err != nil {
+			fmt.Fprintf(os.Stderr, "wavinfo: encoding json: %s\n", err.Error())
+			os.Exit(1)
+		}
+	} else {
+		printInfo(os.Stdout, fileInfo)
+	}
+
+	if len(fileInfo.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+// inspect lists the chunks of the file and decodes its metadata. Errors in
+// the file itself are collected, so as much as possible is reported.
+func inspect(path string) (*info, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo := &info{
+		File:   path,
+		Size:   stat.Size(),
+		Chunks: []chunkInfo{},
+	}
+
+	chunks, err := wav.ScanChunks(file)
+	if err != nil {
+		fileInfo.Errors = append(fileInfo.Errors, err.Error())
+	}
+
+	for _, chunk := range chunks {
+		fileInfo.Chunks = append(fileInfo.Chunks, chunkInfo{
+			ID:     string(chunk.ID[:]),
+			Offset: chunk.Offset,
+			Size:   chunk.Size,
+		})
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	waveFile := &wav.WAVEFileFormat{}
+
+	if err := waveFile.DecodeMetadata(file); err != nil {
+		fileInfo.Errors = append(fileInfo.Errors, err.Error())
+
+		// The format is still meaningful if it was decoded before the error
+		if waveFile.FormatChunk.Chunk.ID != [4]byte{'f', 'm', 't', ' '} {
+			return fileInfo, nil
+		}
+	}
+
+	fileInfo.Format = newFormatInfo(waveFile)
+	fileInfo.Metadata = newMetadata(waveFile, fileInfo)
+
+	return fileInfo, nil
+}
+
+func newFormatInfo(waveFile *wav.WAVEFileFormat) *formatInfo {
+	format := &waveFile.FormatChunk
+	tag := binary.LittleEndian.Uint16(format.Format[:])
+
+	name, ok := formatNames[tag]
+	if !ok {
+		name = fmt.Sprintf("0x%04X", tag)
+	}
+
+	config := waveFile.Config()
+
+	formatInfo := &formatInfo{
+		Name:          name,
+		Tag:           tag,
+		Channels:      config.Channels,
+		SampleRate:    config.SampleRate,
+		ByteRate:      int(binary.LittleEndian.Uint32(format.ByteRate[:])),
+		BlockAlign:    int(binary.LittleEndian.Uint16(format.BlockAlign[:])),
+		BitsPerSample: config.BitDepth,
+		Frames:        waveFile.Frames(),
+	}
+
+	if config.SampleRate > 0 {
+		formatInfo.Duration = float64(formatInfo.Frames) / float64(config.SampleRate)
+	}
+
+	if binary.LittleEndian.Uint32(format.Chunk.Size[:]) > wav.FormatChunkSizePCM {
+		extensionSize := int(binary.LittleEndian.Uint16(format.ExtensionSize[:]))
+		formatInfo.ExtensionSize = &extensionSize
+	}
+
+	if tag == wav.FormatExtensible {
+		validBitsPerSample := int(binary.LittleEndian.Uint16(format.ValidBitsPerSample[:]))
+		channelMask := binary.LittleEndian.Uint32(format.ChannelMask[:])
+
+		formatInfo.ValidBitsPerSample = &validBitsPerSample
+		formatInfo.ChannelMask = &channelMask
+		formatInfo.SubFormat = format.SubFormat.String()
+
+		if subFormat, ok := wav.LookupSubFormat(format.SubFormat); ok {
+			formatInfo.SubFormatName = subFormat.Name
+		}
+
+		if config.Ambisonic {
+			if order, err := wav.AmbisonicOrder(config.Channels); err == nil {
+				formatInfo.AmbisonicOrder = &order
+			}
+		}
+	}
+
+	if extension := format.Extension(); len(extension) > 0 {
+		formatInfo.Extension = hex.EncodeToString(extension)
+	}
+
+	if waveFile.FactChunk.Chunk.ID == [4]byte{'f', 'a', 'c', 't'} {
+		sampleLength := binary.LittleEndian.Uint32(waveFile.FactChunk.SampleLength[:])
+		formatInfo.SampleLength = &sampleLength
+
+		// Frames of compressed audio data are only known from the fact sub-chunk
+		switch config.Format {
+		case wav.FormatPCM, wav.FormatIEEEFloat, wav.FormatALaw, wav.FormatMuLaw:
+		default:
+			formatInfo.Frames = int(sampleLength)
+			formatInfo.Duration = float64(sampleLength) / float64(max(config.SampleRate, 1))
+		}
+	}
+
+	return formatInfo
+}
+
+func newMetadata(waveFile *wav.WAVEFileFormat, fileInfo *info) metadata {
+	var meta metadata
+
+	info, err := waveFile.Info()
+	if err == nil {
+		for _, entry := range info.Entries {
+			meta.Info = append(meta.Info, textField{Name: string(entry.ID[:]), Value: entry.Value})
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		fileInfo.Errors = append(fileInfo.Errors, fmt.Sprintf("decoding LIST INFO sub-chunk: %s", err.Error()))
+	}
+
+	bext, err := waveFile.Bext()
+	if err == nil {
+		meta.Bext = &bextInfo{
+			Description:          bext.Description,
+			Originator:           bext.Originator,
+			OriginatorReference:  bext.OriginatorReference,
+			OriginationDate:      bext.OriginationDate,
+			OriginationTime:      bext.OriginationTime,
+			TimeReference:        bext.TimeReference,
+			Version:              bext.Version,
+			UMID:                 hex.EncodeToString(bext.UMID[:]),
+			LoudnessValue:        bext.LoudnessValue,
+			LoudnessRange:        bext.LoudnessRange,
+			MaxTruePeakLevel:     bext.MaxTruePeakLevel,
+			MaxMomentaryLoudness: bext.MaxMomentaryLoudness,
+			MaxShortTermLoudness: bext.MaxShortTermLoudness,
+			CodingHistory:        bext.CodingHistory,
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		fileInfo.Errors = append(fileInfo.Errors, fmt.Sprintf("decoding bext sub-chunk: %s", err.Error()))
+	}
+
+	cart, err := waveFile.Cart()
+	if err == nil {
+		meta.Cart = cartFields(cart)
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		fileInfo.Errors = append(fileInfo.Errors, fmt.Sprintf("decoding cart sub-chunk: %s", err.Error()))
+	}
+
+	acid, err := waveFile.Acid()
+	if err == nil {
+		meta.Acid = acidFields(acid)
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		fileInfo.Errors = append(fileInfo.Errors, fmt.Sprintf("decoding acid sub-chunk: %s", err.Error()))
+	}
+
+	ixml, err := waveFile.IXML()
+	if err == nil {
+		meta.IXML = ixmlFields(meta.IXML, "", ixml.Elements)
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		fileInfo.Errors = append(fileInfo.Errors, fmt.Sprintf("decoding iXML sub-chunk: %s", err.Error()))
+	}
+
+	cue, err := waveFile.Cue()
+	if err == nil {
+		adtl, err := waveFile.AssociatedData()
+		if err != nil && !errors.Is(err, wav.ErrSubChunkNotFound) {
+			fileInfo.Errors = append(fileInfo.Errors, fmt.Sprintf("decoding LIST adtl sub-chunk: %s", err.Error()))
+		}
+
+		for _, point := range cue.Points {
+			cueInfo := cueInfo{ID: point.ID, Frame: point.SampleOffset}
+
+			if adtl != nil {
+				cueInfo.Label, _ = adtl.Label(point.ID)
+
+				for _, region := range adtl.Regions {
+					if region.CueID == point.ID {
+						cueInfo.Length = region.SampleLength
+					}
+				}
+			}
+
+			meta.Cues = append(meta.Cues, cueInfo)
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		fileInfo.Errors = append(fileInfo.Errors, fmt.Sprintf("decoding cue sub-chunk: %s", err.Error()))
+	}
+
+	playlist, err := waveFile.Playlist()
+	if err == nil {
+		for _, segment := range playlist.Segments {
+			meta.Plst = append(meta.Plst, playlistInfo(segment))
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		fileInfo.Errors = append(fileInfo.Errors, fmt.Sprintf("decoding plst sub-chunk: %s", err.Error()))
+	}
+
+	tracks, err := waveFile.ADMTracks()
+	if err == nil {
+		for _, track := range tracks {
+			admTrack := admTrack{Track: track.TrackIndex, UID: track.UID, PackFormat: track.PackFormatRef}
+
+			for _, object := range track.Objects {
+				admTrack.Objects = append(admTrack.Objects, object.Name)
+			}
+
+			meta.ADM = append(meta.ADM, admTrack)
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		fileInfo.Errors = append(fileInfo.Errors, fmt.Sprintf("decoding chna and axml sub-chunks: %s", err.Error()))
+	}
+
+	return meta
+}
+
+// cartFields returns the non-empty fields of the cart sub-chunk.
+func cartFields(cart *wav.CartChunk) []textField {
+	var fields []textField
+
+	for _, field := range []textField{
+		{"Version", cart.Version},
+		{"Title", cart.Title},
+		{"Artist", cart.Artist},
+		{"Cut ID", cart.CutID},
+		{"Client ID", cart.ClientID},
+		{"Category", cart.Category},
+		{"Classification", cart.Classification},
+		{"Out cue", cart.OutCue},
+		{"Start", strings.TrimSpace(cart.StartDate + " " + cart.StartTime)},
+		{"End", strings.TrimSpace(cart.EndDate + " " + cart.EndTime)},
+		{"Producer app", strings.TrimSpace(cart.ProducerAppID + " " + cart.ProducerAppVersion)},
+		{"User defined", cart.UserDef},
+		{"Level reference", strconv.Itoa(int(cart.LevelReference))},
+	} {
+		if field.Value != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	for _, timer := range cart.PostTimers {
+		if timer.Usage != "" {
+			fields = append(fields, textField{Name: "Post timer " + timer.Usage, Value: strconv.FormatUint(uint64(timer.Value), 10)})
+		}
+	}
+
+	if cart.URL != "" {
+		fields = append(fields, textField{Name: "URL", Value: cart.URL})
+	}
+
+	if tagText := strings.TrimSpace(cart.TagText); tagText != "" {
+		fields = append(fields, textField{Name: "Tag text", Value: tagText})
+	}
+
+	return fields
+}
+
+// acidFields returns the fields of the acid sub-chunk.
+func acidFields(acid *wav.AcidChunk) []textField {
+	kind := "loop"
+	if !acid.Loop() {
+		kind = "one-shot"
+	}
+
+	fields := []textField{
+		{"Type", kind},
+		{"Tempo", fmt.Sprintf("%.2f BPM", acid.Tempo)},
+		{"Beats", strconv.FormatUint(uint64(acid.Beats), 10)},
+		{"Meter", fmt.Sprintf("%d/%d", acid.MeterNumerator, acid.MeterDenominator)},
+	}
+
+	if key, ok := acid.Key(); ok {
+		fields = append(fields, textField{Name: "Key", Value: fmt.Sprintf("%s (note %d)", key, acid.RootNote)})
+	}
+
+	return fields
+}
+
+// ixmlFields appends the values of all elements without children, named by
+// their path.
+func ixmlFields(fields []textField, prefix string, elements []wav.IXMLElement) []textField {
+	for _, element := range elements {
+		if len(element.Elements) == 0 {
+			fields = append(fields, textField{Name: prefix + element.Name, Value: element.Value})
+		} else {
+			fields = ixmlFields(fields, prefix+element.Name+"/", element.Elements)
+		}
+	}
+
+	return fields
+}
+
+func printInfo(writer io.Writer, fileInfo *info) {
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(table, "File:\t%s\n", fileInfo.File)
+	fmt.Fprintf(table, "Size:\t%d bytes\n", fileInfo.Size)
+
+	if format := fileInfo.Format; format != nil {
+		fmt.Fprintf(table, "Format:\t%s (0x%04X)\n", format.Name, format.Tag)
+		fmt.Fprintf(table, "Channels:\t%d\n", format.Channels)
+		fmt.Fprintf(table, "Sample rate:\t%d Hz\n", format.SampleRate)
+		fmt.Fprintf(table, "Byte rate:\t%d bytes/s\n", format.ByteRate)
+		fmt.Fprintf(table, "Block align:\t%d bytes\n", format.BlockAlign)
+		fmt.Fprintf(table, "Bits per sample:\t%d\n", format.BitsPerSample)
+
+		if format.ExtensionSize != nil {
+			fmt.Fprintf(table, "Extension size:\t%d bytes\n", *format.ExtensionSize)
+		}
+
+		if format.ValidBitsPerSample != nil {
+			fmt.Fprintf(table, "Valid bits per sample:\t%d\n", *format.ValidBitsPerSample)
+			fmt.Fprintf(table, "Channel mask:\t0x%08X\n", *format.ChannelMask)
+			if format.SubFormatName != "" {
+				fmt.Fprintf(table, "Sub-format:\t%s (%s)\n", format.SubFormatName, format.SubFormat)
+			} else {
+				fmt.Fprintf(table, "Sub-format:\t%s\n", format.SubFormat)
+			}
+		}
+
+		if format.AmbisonicOrder != nil {
+			fmt.Fprintf(table, "Ambisonic order:\t%d\n", *format.AmbisonicOrder)
+		}
+
+		if format.Extension != "" {
+			fmt.Fprintf(table, "Extension:\t%s\n", format.Extension)
+		}
+
+		if format.SampleLength != nil {
+			fmt.Fprintf(table, "Sample length:\t%d\n", *format.SampleLength)
+		}
+
+		fmt.Fprintf(table, "Frames:\t%d\n", format.Frames)
+		fmt.Fprintf(table, "Duration:\t%s\n", time.Duration(format.Duration*float64(time.Second)).Round(time.Millisecond))
+	}
+
+	table.Flush()
+
+	fmt.Fprintf(writer, "\nChunks:\n")
+
+	table = tabwriter.NewWriter(writer, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(table, "\tID\tOffset\tSize\t\n")
+
+	for _, chunk := range fileInfo.Chunks {
+		fmt.Fprintf(table, "\t%q\t%d\t%d\t\n", chunk.ID, chunk.Offset, chunk.Size)
+	}
+
+	table.Flush()
+
+	printTextFields(writer, "Information (LIST INFO)", fileInfo.Metadata.Info)
+
+	if bext := fileInfo.Metadata.Bext; bext != nil {
+		fmt.Fprintf(writer, "\nBroadcast extension (bext):\n")
+
+		table = tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(table, "  Description:\t%s\n", bext.Description)
+		fmt.Fprintf(table, "  Originator:\t%s\n", bext.Originator)
+		fmt.Fprintf(table, "  Originator reference:\t%s\n", bext.OriginatorReference)
+		fmt.Fprintf(table, "  Origination date:\t%s\n", bext.OriginationDate)
+		fmt.Fprintf(table, "  Origination time:\t%s\n", bext.OriginationTime)
+		fmt.Fprintf(table, "  Time reference:\t%d\n", bext.TimeReference)
+		fmt.Fprintf(table, "  Version:\t%d\n", bext.Version)
+		fmt.Fprintf(table, "  UMID:\t%s\n", bext.UMID)
+
+		if bext.Version >= wav.BextVersion {
+			fmt.Fprintf(table, "  Loudness value:\t%s\n", bextLevel(bext.LoudnessValue, "LUFS"))
+			fmt.Fprintf(table, "  Loudness range:\t%s\n", bextLevel(bext.LoudnessRange, "LU"))
+			fmt.Fprintf(table, "  Max true peak level:\t%s\n", bextLevel(bext.MaxTruePeakLevel, "dBTP"))
+			fmt.Fprintf(table, "  Max momentary loudness:\t%s\n", bextLevel(bext.MaxMomentaryLoudness, "LUFS"))
+			fmt.Fprintf(table, "  Max short-term loudness:\t%s\n", bextLevel(bext.MaxShortTermLoudness, "LUFS"))
+		}
+
+		fmt.Fprintf(table, "  Coding history:\t%s\n", strings.TrimSpace(bext.CodingHistory))
+		table.Flush()
+	}
+
+	printTextFields(writer, "Radio traffic (cart)", fileInfo.Metadata.Cart)
+	printTextFields(writer, "Loop (acid)", fileInfo.Metadata.Acid)
+	printTextFields(writer, "Production (iXML)", fileInfo.Metadata.IXML)
+
+	if cues := fileInfo.Metadata.Cues; len(cues) > 0 {
+		fmt.Fprintf(writer, "\nCue points:\n")
+
+		table = tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(table, "  ID\tFrame\tLength\tLabel\n")
+
+		for _, cue := range cues {
+			fmt.Fprintf(table, "  %d\t%d\t%d\t%s\n", cue.ID, cue.Frame, cue.Length, cue.Label)
+		}
+
+		table.Flush()
+	}
+
+	if segments := fileInfo.Metadata.Plst; len(segments) > 0 {
+		fmt.Fprintf(writer, "\nPlaylist (plst):\n")
+
+		table = tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(table, "  Cue ID\tLength\tRepeats\n")
+
+		for _, segment := range segments {
+			fmt.Fprintf(table, "  %d\t%d\t%d\n", segment.CueID, segment.Length, segment.Repeats)
+		}
+
+		table.Flush()
+	}
+
+	if tracks := fileInfo.Metadata.ADM; len(tracks) > 0 {
+		fmt.Fprintf(writer, "\nAudio Definition Model (chna, axml):\n")
+
+		table = tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(table, "  Track\tUID\tPack format\tObjects\n")
+
+		for _, track := range tracks {
+			fmt.Fprintf(table, "  %d\t%s\t%s\t%s\n", track.Track, track.UID, track.PackFormat, strings.Join(track.Objects, ", "))
+		}
+
+		table.Flush()
+	}
+
+	if len(fileInfo.Errors) > 0 {
+		fmt.Fprintf(writer, "\nErrors:\n")
+
+		for _, err := range fileInfo.Errors {
+			fmt.Fprintf(writer, "  %s\n", err)
+		}
+	}
+}
+
+func printTextFields(writer io.Writer, title string, fields []textField) {
+	if len(fields) == 0 {
+		return
+	}
+
+	fmt.Fprintf(writer, "\n%s:\n", title)
+
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+
+	for _, field := range fields {
+		fmt.Fprintf(table, "  %s:\t%s\n", field.Name, field.Value)
+	}
+
+	table.Flush()
+}
+
+// bextLevel formats a bext loudness field given in hundredths of unit.
+func bextLevel(value int16, unit string) string {
+	if value == wav.BextLoudnessUnknown {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%.2f %s", float64(value)/100, unit)
+}
@@ -0,0 +1,103 @@
+// Command wavjoin concatenates WAV files of the same format into one. The
+// metadata sub-chunks of the first file are kept, including its bext time
+// reference, and the cue points of all files are moved to their position in
+// the joined file.
+//
+// Usage:
+//
+//	wavjoin -o out.wav in.wav...
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/internal/cli"
+)
+
+func main() {
+	output := flag.String("o", "", "output file")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: wavjoin -o out.wav in.wav...\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *output == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := join(*output, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "wavjoin: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func join(outputPath string, inputPaths []string) error {
+	files := make([]*wav.WAVEFileFormat, len(inputPaths))
+
+	for i, path := range inputPaths {
+		if same, err := cli.SamePath(path, outputPath); err != nil {
+			return err
+		} else if same {
+			return fmt.Errorf("output %s is also an input", outputPath)
+		}
+
+		file, err := cli.Decode(path)
+		if err != nil {
+			return err
+		}
+
+		files[i] = file
+	}
+
+	joined, err := wav.Concat(files...)
+	if errors.Is(err, wav.ErrFormatMismatch) {
+		return fmt.Errorf("%w: all inputs must have the format of %s", err, inputPaths[0])
+	} else if err != nil {
+		return err
+	}
+
+	warnDiscontinuities(inputPaths, files)
+
+	output, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+
+	if err := joined.Encode(output); err != nil {
+		output.Close()
+		os.Remove(outputPath)
+
+		return fmt.Errorf("encoding %s: %w", outputPath, err)
+	}
+
+	return output.Close()
+}
+
+// warnDiscontinuities reports inputs whose bext time reference does not
+// continue from the preceding input, as the joined file only keeps the time
+// reference of the first input.
+func warnDiscontinuities(paths []string, files []*wav.WAVEFileFormat) {
+	var expected uint64
+
+	for i, file := range files {
+		bext, err := file.Bext()
+		if err != nil {
+			return
+		}
+
+		if i > 0 && bext.TimeReference != expected {
+			fmt.Fprintf(os.Stderr, "wavjoin: warning: time reference of %s is %d, not %d following the preceding input\n",
+				paths[i], bext.TimeReference, expected)
+		}
+
+		expected = bext.TimeReference + uint64(file.Frames())
+	}
+}
@@ -0,0 +1,662 @@
+// Command wavmeta views and edits the LIST INFO, bext and iXML metadata of
+// WAV files. Edited metadata is written in place when it fits before the
+// audio data, otherwise the file is rewritten with room for later edits.
+//
+// Fields are named after their sub-chunk: info.<ID> or one of the INFO
+// aliases such as info.title, bext.<field> such as bext.description, and
+// ixml.<path> such as ixml.SPEED/TIMECODE_RATE. A sub-chunk name on its own
+// stands for all of its fields.
+//
+// Usage:
+//
+//	wavmeta get [-json] file.wav [field...]
+//	wavmeta set [-reserve n] file.wav field=value...
+//	wavmeta delete [-reserve n] file.wav field...
+//	wavmeta copy [-reserve n] source.wav file.wav [field...]
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/samborkent/wav"
+)
+
+// Default space reserved for metadata when a file must be rewritten
+const defaultReserve = 4096
+
+var infoAliases = map[string][4]byte{
+	"title":     wav.InfoTitle,
+	"artist":    wav.InfoArtist,
+	"album":     wav.InfoProduct,
+	"track":     wav.InfoTrackNumber,
+	"genre":     wav.InfoGenre,
+	"comment":   wav.InfoComment,
+	"copyright": wav.InfoCopyright,
+	"date":      wav.InfoCreationDate,
+	"engineer":  wav.InfoEngineer,
+	"keywords":  wav.InfoKeywords,
+	"software":  wav.InfoSoftware,
+	"source":    wav.InfoSource,
+	"subject":   wav.InfoSubject,
+}
+
+type bextField struct {
+	name string
+	get  func(bext *wav.BextChunk) string
+	set  func(bext *wav.BextChunk, value string) error
+}
+
+var bextFields = []bextField{
+	{"description", func(b *wav.BextChunk) string { return b.Description }, textSetter(256, func(b *wav.BextChunk) *string { return &b.Description })},
+	{"originator", func(b *wav.BextChunk) string { return b.Originator }, textSetter(32, func(b *wav.BextChunk) *string { return &b.Originator })},
+	{"originatorReference", func(b *wav.BextChunk) string { return b.OriginatorReference }, textSetter(32, func(b *wav.BextChunk) *string { return &b.OriginatorReference })},
+	{"originationDate", func(b *wav.BextChunk) string { return b.OriginationDate }, textSetter(10, func(b *wav.BextChunk) *string { return &b.OriginationDate })},
+	{"originationTime", func(b *wav.BextChunk) string { return b.OriginationTime }, textSetter(8, func(b *wav.BextChunk) *string { return &b.OriginationTime })},
+	{"timeReference", func(b *wav.BextChunk) string { return strconv.FormatUint(b.TimeReference, 10) }, setTimeReference},
+	{"umid", func(b *wav.BextChunk) string { return hex.EncodeToString(b.UMID[:]) }, setUMID},
+	{"loudnessValue", func(b *wav.BextChunk) string { return formatLevel(b.LoudnessValue) }, levelSetter(func(b *wav.BextChunk) *int16 { return &b.LoudnessValue })},
+	{"loudnessRange", func(b *wav.BextChunk) string { return formatLevel(b.LoudnessRange) }, levelSetter(func(b *wav.BextChunk) *int16 { return &b.LoudnessRange })},
+	{"maxTruePeakLevel", func(b *wav.BextChunk) string { return formatLevel(b.MaxTruePeakLevel) }, levelSetter(func(b *wav.BextChunk) *int16 { return &b.MaxTruePeakLevel })},
+	{"maxMomentaryLoudness", func(b *wav.BextChunk) string { return formatLevel(b.MaxMomentaryLoudness) }, levelSetter(func(b *wav.BextChunk) *int16 { return &b.MaxMomentaryLoudness })},
+	{"maxShortTermLoudness", func(b *wav.BextChunk) string { return formatLevel(b.MaxShortTermLoudness) }, levelSetter(func(b *wav.BextChunk) *int16 { return &b.MaxShortTermLoudness })},
+	{"codingHistory", func(b *wav.BextChunk) string { return b.CodingHistory }, textSetter(math.MaxInt32, func(b *wav.BextChunk) *string { return &b.CodingHistory })},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "get":
+		err = get(args)
+	case "set":
+		err = set(args)
+	case "delete":
+		err = del(args)
+	case "copy":
+		err = copyFields(args)
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wavmeta: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage:
+	wavmeta get [-json] file.wav [field...]
+	wavmeta set [-reserve n] file.wav field=value...
+	wavmeta delete [-reserve n] file.wav field...
+	wavmeta copy [-reserve n] source.wav file.wav [field...]
+`)
+	os.Exit(2)
+}
+
+func parseFlags(flags *flag.FlagSet, args []string, minArgs int) {
+	flags.Usage = usage
+
+	if err := flags.Parse(args); err != nil || flags.NArg() < minArgs {
+		usage()
+	}
+}
+
+func get(args []string) error {
+	flags := flag.NewFlagSet("get", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "print fields as a JSON object")
+	parseFlags(flags, args, 1)
+
+	meta, err := load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fields := meta.fields()
+
+	if names := flags.Args()[1:]; len(names) > 0 {
+		fields, err = meta.selectFields(fields, names)
+		if err != nil {
+			return err
+		}
+
+		if len(names) == 1 && len(fields) == 1 && !*jsonOutput && strings.Contains(names[0], ".") {
+			fmt.Println(fields[0].value)
+			return nil
+		}
+	}
+
+	if *jsonOutput {
+		object := make(map[string]string, len(fields))
+		for _, field := range fields {
+			object[field.name] = field.value
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(object)
+	}
+
+	for _, field := range fields {
+		fmt.Printf("%s=%s\n", field.name, quote(field.value))
+	}
+
+	return nil
+}
+
+func set(args []string) error {
+	flags := flag.NewFlagSet("set", flag.ExitOnError)
+	reserve := flags.Int("reserve", defaultReserve, "bytes reserved for metadata if the file must be rewritten")
+	parseFlags(flags, args, 2)
+
+	meta, err := load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range flags.Args()[1:] {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return fmt.Errorf("%q is not of the form field=value", assignment)
+		}
+
+		if err := meta.set(name, value); err != nil {
+			return err
+		}
+	}
+
+	return meta.save(*reserve)
+}
+
+func del(args []string) error {
+	flags := flag.NewFlagSet("delete", flag.ExitOnError)
+	reserve := flags.Int("reserve", defaultReserve, "bytes reserved for metadata if the file must be rewritten")
+	parseFlags(flags, args, 2)
+
+	meta, err := load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	for _, name := range flags.Args()[1:] {
+		if err := meta.delete(name); err != nil {
+			return err
+		}
+	}
+
+	return meta.save(*reserve)
+}
+
+func copyFields(args []string) error {
+	flags := flag.NewFlagSet("copy", flag.ExitOnError)
+	reserve := flags.Int("reserve", defaultReserve, "bytes reserved for metadata if the file must be rewritten")
+	parseFlags(flags, args, 2)
+
+	source, err := load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	meta, err := load(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	names := flags.Args()[2:]
+	if len(names) == 0 {
+		names = []string{"info", "bext", "ixml"}
+	}
+
+	for _, name := range names {
+		fields, err := source.selectFields(source.fields(), []string{name})
+		if err != nil {
+			return err
+		}
+
+		for _, field := range fields {
+			if err := meta.set(field.name, field.value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return meta.save(*reserve)
+}
+
+// metadata holds the decoded metadata sub-chunks of a file, which are nil if
+// absent.
+type metadata struct {
+	path   string
+	header *wav.WAVEFileFormat
+	info   *wav.InfoChunk
+	bext   *wav.BextChunk
+	ixml   *wav.IXMLChunk
+
+	changed map[string]bool // Sub-chunks to encode on save
+}
+
+type field struct {
+	name  string
+	value string
+}
+
+func load(path string) (*metadata, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	meta := &metadata{
+		path:    path,
+		header:  &wav.WAVEFileFormat{},
+		changed: make(map[string]bool),
+	}
+
+	if err := meta.header.DecodeMetadata(file); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	if meta.info, err = meta.header.Info(); err != nil && !errors.Is(err, wav.ErrSubChunkNotFound) {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	if meta.bext, err = meta.header.Bext(); err != nil && !errors.Is(err, wav.ErrSubChunkNotFound) {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	if meta.ixml, err = meta.header.IXML(); err != nil && !errors.Is(err, wav.ErrSubChunkNotFound) {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	return meta, nil
+}
+
+// fields lists all fields in file order.
+func (m *metadata) fields() []field {
+	var fields []field
+
+	if m.info != nil {
+		for _, entry := range m.info.Entries {
+			fields = append(fields, field{"info." + string(entry.ID[:]), entry.Value})
+		}
+	}
+
+	if m.bext != nil {
+		for _, bextField := range bextFields {
+			fields = append(fields, field{"bext." + bextField.name, bextField.get(m.bext)})
+		}
+	}
+
+	if m.ixml != nil {
+		fields = appendIXMLFields(fields, "ixml.", m.ixml.Elements)
+	}
+
+	return fields
+}
+
+func appendIXMLFields(fields []field, prefix string, elements []wav.IXMLElement) []field {
+	for _, element := range elements {
+		if len(element.Elements) == 0 {
+			fields = append(fields, field{prefix + element.Name, element.Value})
+		} else {
+			fields = appendIXMLFields(fields, prefix+element.Name+"/", element.Elements)
+		}
+	}
+
+	return fields
+}
+
+// selectFields returns the fields matching the given names, which are either
+// field names or sub-chunk names.
+func (m *metadata) selectFields(fields []field, names []string) ([]field, error) {
+	var selected []field
+
+	for _, name := range names {
+		name, err := canonicalName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+
+		for _, field := range fields {
+			if field.name == name || strings.HasPrefix(field.name, name+".") || strings.HasPrefix(field.name, name+"/") {
+				selected = append(selected, field)
+				found = true
+			}
+		}
+
+		if !found && strings.Contains(name, ".") {
+			return nil, fmt.Errorf("%s: field %s not found", m.path, name)
+		}
+	}
+
+	return selected, nil
+}
+
+// canonicalName resolves INFO aliases and validates the sub-chunk name.
+func canonicalName(name string) (string, error) {
+	chunk, rest, _ := strings.Cut(name, ".")
+
+	switch chunk {
+	case "info":
+		if id, ok := infoAliases[rest]; ok {
+			return "info." + string(id[:]), nil
+		}
+
+		if rest != "" && len(rest) != 4 {
+			return "", fmt.Errorf("INFO field %q must be an alias or a four character ID", rest)
+		}
+	case "bext":
+		if rest != "" && findBextField(rest) == nil {
+			return "", fmt.Errorf("unknown bext field %q", rest)
+		}
+	case "ixml":
+	default:
+		return "", fmt.Errorf("unknown sub-chunk %q, must be info, bext or ixml", chunk)
+	}
+
+	return name, nil
+}
+
+func findBextField(name string) *bextField {
+	for i := range bextFields {
+		if bextFields[i].name == name {
+			return &bextFields[i]
+		}
+	}
+
+	return nil
+}
+
+func (m *metadata) set(name, value string) error {
+	name, err := canonicalName(name)
+	if err != nil {
+		return err
+	}
+
+	chunk, rest, _ := strings.Cut(name, ".")
+	if rest == "" {
+		return fmt.Errorf("%s: a field name is required to set a value", name)
+	}
+
+	m.changed[chunk] = true
+
+	switch chunk {
+	case "info":
+		if m.info == nil {
+			m.info = &wav.InfoChunk{}
+		}
+
+		m.info.Set([4]byte([]byte(rest)), value)
+	case "bext":
+		if m.bext == nil {
+			m.bext = &wav.BextChunk{
+				Version:              wav.BextVersion,
+				LoudnessValue:        wav.BextLoudnessUnknown,
+				LoudnessRange:        wav.BextLoudnessUnknown,
+				MaxTruePeakLevel:     wav.BextLoudnessUnknown,
+				MaxMomentaryLoudness: wav.BextLoudnessUnknown,
+				MaxShortTermLoudness: wav.BextLoudnessUnknown,
+			}
+		}
+
+		if err := findBextField(rest).set(m.bext, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	case "ixml":
+		if m.ixml == nil {
+			m.ixml = &wav.IXMLChunk{}
+		}
+
+		if err := m.ixml.Set(rest, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *metadata) delete(name string) error {
+	name, err := canonicalName(name)
+	if err != nil {
+		return err
+	}
+
+	chunk, rest, _ := strings.Cut(name, ".")
+	m.changed[chunk] = true
+
+	if rest == "" {
+		switch chunk {
+		case "info":
+			m.info = nil
+		case "bext":
+			m.bext = nil
+		case "ixml":
+			m.ixml = nil
+		}
+
+		return nil
+	}
+
+	switch chunk {
+	case "info":
+		if m.info != nil {
+			m.info.Delete([4]byte([]byte(rest)))
+		}
+	case "bext":
+		if m.bext != nil {
+			return findBextField(rest).set(m.bext, "")
+		}
+	case "ixml":
+		if m.ixml != nil {
+			m.ixml.Delete(rest)
+		}
+	}
+
+	return nil
+}
+
+// save encodes the changed sub-chunks and writes them to the file, in place
+// if possible.
+func (m *metadata) save(reserve int) error {
+	if err := m.encode(); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(m.path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = wav.UpdateMetadata(file, m.header)
+	if errors.Is(err, wav.ErrMetadataSpace) {
+		return m.rewrite(file, reserve)
+	} else if err != nil {
+		return fmt.Errorf("writing %s: %w", m.path, err)
+	}
+
+	return file.Close()
+}
+
+func (m *metadata) encode() error {
+	if m.changed["info"] {
+		var err error
+
+		if m.info == nil || len(m.info.Entries) == 0 {
+			err = m.header.RemoveListChunk([4]byte{'I', 'N', 'F', 'O'})
+		} else {
+			err = m.header.SetInfo(m.info)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.changed["bext"] {
+		var err error
+
+		if m.bext == nil {
+			err = m.header.RemoveSubChunk([4]byte{'b', 'e', 'x', 't'})
+		} else {
+			err = m.header.SetBext(m.bext)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.changed["ixml"] {
+		var err error
+
+		if m.ixml == nil || len(m.ixml.Elements) == 0 {
+			err = m.header.RemoveSubChunk([4]byte{'i', 'X', 'M', 'L'})
+		} else {
+			err = m.header.SetIXML(m.ixml)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rewrite writes the file with its new metadata to a temporary file next to
+// it, which then replaces the file.
+func (m *metadata) rewrite(file *os.File, reserve int) error {
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(m.path), ".wavmeta-*.wav")
+	if err != nil {
+		return err
+	}
+
+	if err := wav.RewriteMetadata(temp, file, m.header, reserve); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+
+		return fmt.Errorf("writing %s: %w", m.path, err)
+	}
+
+	if err := temp.Chmod(stat.Mode().Perm()); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+
+		return err
+	}
+
+	return os.Rename(temp.Name(), m.path)
+}
+
+func textSetter(length int, field func(bext *wav.BextChunk) *string) func(*wav.BextChunk, string) error {
+	return func(bext *wav.BextChunk, value string) error {
+		if len(value) > length {
+			return fmt.Errorf("value exceeds %d characters", length)
+		}
+
+		*field(bext) = value
+
+		return nil
+	}
+}
+
+func setTimeReference(bext *wav.BextChunk, value string) error {
+	if value == "" {
+		bext.TimeReference = 0
+		return nil
+	}
+
+	timeReference, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	bext.TimeReference = timeReference
+
+	return nil
+}
+
+func setUMID(bext *wav.BextChunk, value string) error {
+	umid, err := hex.DecodeString(value)
+	if err != nil {
+		return err
+	} else if len(umid) > len(bext.UMID) {
+		return fmt.Errorf("UMID exceeds %d bytes", len(bext.UMID))
+	}
+
+	bext.UMID = [64]byte{}
+	copy(bext.UMID[:], umid)
+
+	return nil
+}
+
+// formatLevel formats a loudness field in 0.01 LU, which is empty if unknown.
+func formatLevel(value int16) string {
+	if value == wav.BextLoudnessUnknown {
+		return ""
+	}
+
+	return strconv.FormatFloat(float64(value)/100, 'f', 2, 64)
+}
+
+func levelSetter(field func(bext *wav.BextChunk) *int16) func(*wav.BextChunk, string) error {
+	return func(bext *wav.BextChunk, value string) error {
+		if value == "" {
+			*field(bext) = wav.BextLoudnessUnknown
+			return nil
+		}
+
+		level, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+
+		level = math.Round(level * 100)
+		if level < math.MinInt16 || level >= wav.BextLoudnessUnknown {
+			return fmt.Errorf("level %s out of range", value)
+		}
+
+		*field(bext) = int16(level)
+		bext.Version = max(bext.Version, wav.BextVersion)
+
+		return nil
+	}
+}
+
+// quote quotes values that would otherwise be ambiguous on a single line.
+func quote(value string) string {
+	if strings.TrimSpace(value) != value || strings.ContainsFunc(value, func(r rune) bool { return r < ' ' || r == '"' }) {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
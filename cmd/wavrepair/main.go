@@ -0,0 +1,127 @@
+// Command wavrepair repairs WAV files with broken headers, such as recordings
+// interrupted by a crash or power loss before the chunk sizes were written.
+// Chunk sizes are recomputed from the file length, partial trailing frames
+// and cut off sub-chunks are removed and a missing padding byte is added.
+// Without an output path the file is repaired in place.
+//
+// Usage:
+//
+//	wavrepair [-n] in.wav [out.wav]
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/internal/cli"
+)
+
+func main() {
+	dryRun := flag.Bool("n", false, "report problems without repairing")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: wavrepair [flags] in.wav [out.wav]\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() < 1 || flag.NArg() > 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), flag.Arg(1), *dryRun); err != nil {
+		fmt.Fprintf(os.Stderr, "wavrepair: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(inputPath, outputPath string, dryRun bool) error {
+	if outputPath != "" {
+		if same, err := cli.SamePath(inputPath, outputPath); err != nil {
+			return err
+		} else if same {
+			return errors.New("output is the input file, omit the output path to repair in place")
+		}
+	}
+
+	flags := os.O_RDWR
+	if dryRun || outputPath != "" {
+		flags = os.O_RDONLY
+	}
+
+	input, err := os.OpenFile(inputPath, flags, 0)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	repair, err := wav.DiagnoseRepair(input)
+	if err != nil {
+		return fmt.Errorf("diagnosing %s: %w", inputPath, err)
+	}
+
+	for _, problem := range repair.Problems {
+		fmt.Printf("%s: %s\n", inputPath, problem)
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	if outputPath == "" {
+		if repair.Valid() {
+			return nil
+		}
+
+		if err := repair.Apply(input); err != nil {
+			return fmt.Errorf("repairing %s: %w", inputPath, err)
+		}
+
+		fmt.Printf("%s: repaired\n", inputPath)
+
+		return input.Close()
+	}
+
+	if err := copyRepaired(input, outputPath, repair); err != nil {
+		os.Remove(outputPath)
+
+		return fmt.Errorf("repairing %s: %w", outputPath, err)
+	}
+
+	fmt.Printf("%s: written\n", outputPath)
+
+	return nil
+}
+
+// copyRepaired copies the part of the input that is kept to the output path
+// and repairs the copy.
+func copyRepaired(input io.ReadSeeker, outputPath string, repair *wav.Repair) error {
+	if _, err := input.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	output, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.CopyN(output, input, repair.Size); err != nil {
+		output.Close()
+
+		return err
+	}
+
+	if err := repair.Apply(output); err != nil {
+		output.Close()
+
+		return err
+	}
+
+	return output.Close()
+}
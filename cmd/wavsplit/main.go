@@ -0,0 +1,210 @@
+// Command wavsplit splits a WAV file into numbered segments, either of a
+// fixed duration, at its cue points, or around silence. Metadata sub-chunks
+// are copied to every segment, with the bext time reference moved to the
+// start of the segment and the cue points of the segment kept.
+//
+// Usage:
+//
+//	wavsplit -duration d [-prefix path] in.wav
+//	wavsplit -cues [-prefix path] in.wav
+//	wavsplit -silence dBFS [-min-silence d] [-hold d] [-prefix path] in.wav
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/internal/cli"
+)
+
+func main() {
+	duration := flag.Duration("duration", 0, "split into segments of this duration")
+	cues := flag.Bool("cues", false, "split at cue points")
+	silence := flag.Float64("silence", math.NaN(), "split around silence below this level in dBFS, which is removed")
+	minSilence := flag.Duration("min-silence", time.Second, "shortest silence to split at")
+	hold := flag.Duration("hold", 100*time.Millisecond, "silence kept next to audio")
+	prefix := flag.String("prefix", "", "output path prefix, numbered outputs are prefix-001.wav and so on (default: input path without extension)")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: wavsplit (-duration d | -cues | -silence dBFS) [flags] in.wav\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	modes := 0
+
+	for _, set := range []bool{*duration > 0, *cues, !math.IsNaN(*silence)} {
+		if set {
+			modes++
+		}
+	}
+
+	if flag.NArg() != 1 || modes != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	inputPath := flag.Arg(0)
+
+	if *prefix == "" {
+		*prefix = strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
+	}
+
+	waveFile, err := cli.Decode(inputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wavsplit: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	var segments []wav.FrameRange
+
+	switch {
+	case *duration > 0:
+		segments = splitDuration(waveFile, *duration)
+	case *cues:
+		segments, err = splitCues(waveFile)
+	default:
+		segments, err = splitSilence(waveFile, wav.SilenceConfig{
+			Threshold:   *silence,
+			MinDuration: *minSilence,
+			Hold:        *hold,
+		})
+	}
+
+	if err == nil {
+		err = write(waveFile, segments, *prefix)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wavsplit: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// splitDuration returns consecutive segments of the given duration, of which
+// the last one may be shorter.
+func splitDuration(waveFile *wav.WAVEFileFormat, duration time.Duration) []wav.FrameRange {
+	frames := waveFile.Frames()
+	step := max(1, int(math.Round(duration.Seconds()*float64(waveFile.Config().SampleRate))))
+
+	var segments []wav.FrameRange
+
+	for start := 0; start < frames; start += step {
+		segments = append(segments, wav.FrameRange{Start: start, End: min(start+step, frames)})
+	}
+
+	return segments
+}
+
+// splitCues returns the segments between consecutive cue points, and from
+// the start and up to the end of the audio data.
+func splitCues(waveFile *wav.WAVEFileFormat) ([]wav.FrameRange, error) {
+	cue, err := waveFile.Cue()
+	if errors.Is(err, wav.ErrSubChunkNotFound) {
+		return nil, errors.New("file has no cue points")
+	} else if err != nil {
+		return nil, err
+	}
+
+	frames := waveFile.Frames()
+	boundaries := []int{0, frames}
+
+	for _, point := range cue.Points {
+		if offset := int(point.SampleOffset); offset < frames {
+			boundaries = append(boundaries, offset)
+		}
+	}
+
+	slices.Sort(boundaries)
+	boundaries = slices.Compact(boundaries)
+
+	segments := make([]wav.FrameRange, 0, len(boundaries)-1)
+
+	for i := range len(boundaries) - 1 {
+		segments = append(segments, wav.FrameRange{Start: boundaries[i], End: boundaries[i+1]})
+	}
+
+	return segments, nil
+}
+
+// splitSilence returns the segments between silent regions.
+func splitSilence(waveFile *wav.WAVEFileFormat, cfg wav.SilenceConfig) ([]wav.FrameRange, error) {
+	silences, err := waveFile.DetectSilence(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []wav.FrameRange
+
+	start := 0
+
+	for _, silence := range silences {
+		if silence.Start > start {
+			segments = append(segments, wav.FrameRange{Start: start, End: silence.Start})
+		}
+
+		start = silence.End
+	}
+
+	if frames := waveFile.Frames(); frames > start {
+		segments = append(segments, wav.FrameRange{Start: start, End: frames})
+	}
+
+	return segments, nil
+}
+
+func write(waveFile *wav.WAVEFileFormat, segments []wav.FrameRange, prefix string) error {
+	if len(segments) == 0 {
+		return errors.New("no segments found")
+	}
+
+	sampleRate := float64(waveFile.Config().SampleRate)
+	digits := max(3, len(fmt.Sprint(len(segments))))
+
+	for i, segment := range segments {
+		slice, err := waveFile.Slice(segment.Start, segment.End)
+		if err != nil {
+			return err
+		}
+
+		path := fmt.Sprintf("%s-%0*d.wav", prefix, digits, i+1)
+
+		if err := encode(path, slice); err != nil {
+			return err
+		}
+
+		fmt.Printf("%s\t%s\t%s\n", path,
+			seconds(float64(segment.Start)/sampleRate),
+			seconds(float64(segment.Frames())/sampleRate))
+	}
+
+	return nil
+}
+
+func encode(path string, waveFile *wav.WAVEFileFormat) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := waveFile.Encode(file); err != nil {
+		file.Close()
+
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+
+	return file.Close()
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second)).Round(time.Millisecond)
+}
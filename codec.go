@@ -0,0 +1,289 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	MPEGLayer3ExtensionSize = 12
+	HEAACExtensionSize      = 12
+	OpusHeaderSize          = 19
+)
+
+// MPEG layer 3 format identifiers
+const (
+	MPEGLayer3IDUnknown           = 0
+	MPEGLayer3IDMPEG              = 1
+	MPEGLayer3IDConstantFrameSize = 2
+)
+
+// MPEG layer 3 padding flags
+const (
+	MPEGLayer3FlagPaddingISO = 0
+	MPEGLayer3FlagPaddingOn  = 1
+	MPEGLayer3FlagPaddingOff = 2
+)
+
+// HE-AAC payload types
+const (
+	AACPayloadRaw  = 0
+	AACPayloadADTS = 1
+	AACPayloadADIF = 2
+	AACPayloadLOAS = 3
+)
+
+var (
+	ErrFormatExtensionNotSupported = errors.New("format has no known codec-specific extension")
+	ErrDecodeMPEGLayer3Extension   = errors.New("mpeg layer 3 format extension must be 12 bytes")
+	ErrDecodeHEAACExtension        = errors.New("he-aac format extension must be at least 12 bytes")
+	ErrDecodeOpusHeader            = errors.New("opus format extension does not hold an 'OpusHead' header")
+)
+
+// FormatExtension is the codec-specific extension of the format sub-chunk of
+// a compressed format.
+type FormatExtension interface {
+	FormatTag() uint16
+	Decode(data []byte) error
+	Encode() []byte
+}
+
+// MPEGLayer3Format is the extension of the MPEG layer 3 format, as in
+// MPEGLAYER3WAVEFORMAT.
+type MPEGLayer3Format struct {
+	ID             uint16
+	Flags          uint32
+	BlockSize      uint16 // Bytes per block
+	FramesPerBlock uint16
+	CodecDelay     uint16 // Samples of encoder delay
+}
+
+// FormatTag returns FormatMP3.
+func (c *MPEGLayer3Format) FormatTag() uint16 {
+	return FormatMP3
+}
+
+// Decode decodes the MPEG layer 3 format extension.
+func (c *MPEGLayer3Format) Decode(data []byte) error {
+	if len(data) != MPEGLayer3ExtensionSize {
+		return ErrDecodeMPEGLayer3Extension
+	}
+
+	*c = MPEGLayer3Format{
+		ID:             binary.LittleEndian.Uint16(data[0:2]),
+		Flags:          binary.LittleEndian.Uint32(data[2:6]),
+		BlockSize:      binary.LittleEndian.Uint16(data[6:8]),
+		FramesPerBlock: binary.LittleEndian.Uint16(data[8:10]),
+		CodecDelay:     binary.LittleEndian.Uint16(data[10:12]),
+	}
+
+	return nil
+}
+
+// Encode encodes the MPEG layer 3 format extension.
+func (c *MPEGLayer3Format) Encode() []byte {
+	data := make([]byte, 0, MPEGLayer3ExtensionSize)
+	data = binary.LittleEndian.AppendUint16(data, c.ID)
+	data = binary.LittleEndian.AppendUint32(data, c.Flags)
+	data = binary.LittleEndian.AppendUint16(data, c.BlockSize)
+	data = binary.LittleEndian.AppendUint16(data, c.FramesPerBlock)
+
+	return binary.LittleEndian.AppendUint16(data, c.CodecDelay)
+}
+
+// AACFormat is the extension of the raw AAC format, which holds the MPEG-4
+// AudioSpecificConfig.
+type AACFormat struct {
+	AudioSpecificConfig []byte
+}
+
+// FormatTag returns FormatAAC.
+func (c *AACFormat) FormatTag() uint16 {
+	return FormatAAC
+}
+
+// Decode decodes the raw AAC format extension.
+func (c *AACFormat) Decode(data []byte) error {
+	c.AudioSpecificConfig = bytes.Clone(data)
+	return nil
+}
+
+// Encode encodes the raw AAC format extension.
+func (c *AACFormat) Encode() []byte {
+	return bytes.Clone(c.AudioSpecificConfig)
+}
+
+// HEAACFormat is the extension of the HE-AAC format, as in HEAACWAVEFORMAT.
+type HEAACFormat struct {
+	PayloadType         uint16 // E.g. AACPayloadADTS
+	ProfileLevel        uint16 // Audio profile and level indication
+	StructType          uint16 // Zero if followed by AudioSpecificConfig
+	AudioSpecificConfig []byte // Optional
+}
+
+// FormatTag returns FormatHEAAC.
+func (c *HEAACFormat) FormatTag() uint16 {
+	return FormatHEAAC
+}
+
+// Decode decodes the HE-AAC format extension.
+func (c *HEAACFormat) Decode(data []byte) error {
+	if len(data) < HEAACExtensionSize {
+		return ErrDecodeHEAACExtension
+	}
+
+	// Reserved fields of 2 and 4 bytes follow the struct type
+	*c = HEAACFormat{
+		PayloadType:         binary.LittleEndian.Uint16(data[0:2]),
+		ProfileLevel:        binary.LittleEndian.Uint16(data[2:4]),
+		StructType:          binary.LittleEndian.Uint16(data[4:6]),
+		AudioSpecificConfig: bytes.Clone(data[HEAACExtensionSize:]),
+	}
+
+	return nil
+}
+
+// Encode encodes the HE-AAC format extension.
+func (c *HEAACFormat) Encode() []byte {
+	data := make([]byte, 0, HEAACExtensionSize+len(c.AudioSpecificConfig))
+	data = binary.LittleEndian.AppendUint16(data, c.PayloadType)
+	data = binary.LittleEndian.AppendUint16(data, c.ProfileLevel)
+	data = binary.LittleEndian.AppendUint16(data, c.StructType)
+	data = append(data, 0, 0, 0, 0, 0, 0)
+
+	return append(data, c.AudioSpecificConfig...)
+}
+
+// OpusFormat is the extension of the Opus format, which holds the Ogg Opus
+// identification header.
+type OpusFormat struct {
+	Version         uint8
+	Channels        uint8
+	PreSkip         uint16 // Samples at 48 kHz to discard at the start
+	InputSampleRate uint32
+	OutputGain      int16 // Q7.8 in dB
+	MappingFamily   uint8
+	StreamCount     uint8  // Only for mapping families other than zero
+	CoupledCount    uint8  // Only for mapping families other than zero
+	Mapping         []byte // Only for mapping families other than zero
+}
+
+// FormatTag returns FormatOpus.
+func (c *OpusFormat) FormatTag() uint16 {
+	return FormatOpus
+}
+
+// Decode decodes the Opus format extension.
+func (c *OpusFormat) Decode(data []byte) error {
+	if len(data) < OpusHeaderSize || !bytes.Equal(data[0:8], []byte("OpusHead")) {
+		return ErrDecodeOpusHeader
+	}
+
+	*c = OpusFormat{
+		Version:         data[8],
+		Channels:        data[9],
+		PreSkip:         binary.LittleEndian.Uint16(data[10:12]),
+		InputSampleRate: binary.LittleEndian.Uint32(data[12:16]),
+		OutputGain:      int16(binary.LittleEndian.Uint16(data[16:18])),
+		MappingFamily:   data[18],
+	}
+
+	if c.MappingFamily == 0 {
+		return nil
+	}
+
+	// Channel mapping table
+	if len(data) != OpusHeaderSize+2+int(c.Channels) {
+		return ErrDecodeOpusHeader
+	}
+
+	c.StreamCount = data[19]
+	c.CoupledCount = data[20]
+	c.Mapping = bytes.Clone(data[21:])
+
+	return nil
+}
+
+// Encode encodes the Opus format extension.
+func (c *OpusFormat) Encode() []byte {
+	data := make([]byte, 0, OpusHeaderSize+2+len(c.Mapping))
+	data = append(data, "OpusHead"...)
+	data = append(data, c.Version, c.Channels)
+	data = binary.LittleEndian.AppendUint16(data, c.PreSkip)
+	data = binary.LittleEndian.AppendUint32(data, c.InputSampleRate)
+	data = binary.LittleEndian.AppendUint16(data, uint16(c.OutputGain))
+	data = append(data, c.MappingFamily)
+
+	if c.MappingFamily == 0 {
+		return data
+	}
+
+	data = append(data, c.StreamCount, c.CoupledCount)
+
+	return append(data, c.Mapping...)
+}
+
+// FormatExtension decodes the codec-specific extension of the format
+// sub-chunk into the type matching the format.
+func (f *WAVEFileFormat) FormatExtension() (FormatExtension, error) {
+	var extension FormatExtension
+
+	switch format := binary.LittleEndian.Uint16(f.FormatChunk.Format[:]); format {
+	case FormatMP3:
+		extension = &MPEGLayer3Format{}
+	case FormatAAC:
+		extension = &AACFormat{}
+	case FormatHEAAC:
+		extension = &HEAACFormat{}
+	case FormatOpus:
+		extension = &OpusFormat{}
+	default:
+		return nil, fmt.Errorf("%w: format 0x%04X", ErrFormatExtensionNotSupported, format)
+	}
+
+	if err := extension.Decode(f.FormatChunk.Extension()); err != nil {
+		return nil, err
+	}
+
+	return extension, nil
+}
+
+// Extension returns a copy of the codec-specific extension of non-PCM
+// formats, which is empty if absent.
+func (c *FormatChunk) Extension() []byte {
+	return []byte(c.extension)
+}
+
+// SetExtension stores the codec-specific extension of non-PCM formats and
+// updates the extension and sub-chunk sizes.
+func (c *FormatChunk) SetExtension(data []byte) error {
+	if len(data) > math.MaxUint16-FormatChunkSizeNonPCM {
+		return ErrDataTooLarge
+	}
+
+	c.extension = string(data)
+	binary.LittleEndian.PutUint16(c.ExtensionSize[:], uint16(len(data)))
+	binary.LittleEndian.PutUint32(c.Chunk.Size[:], uint32(FormatChunkSizeNonPCM+len(data)))
+
+	return nil
+}
+
+// SetFormatExtension sets the format of the format sub-chunk to that of the
+// extension and stores the encoded extension.
+func (f *WAVEFileFormat) SetFormatExtension(extension FormatExtension) error {
+	if err := f.FormatChunk.SetExtension(extension.Encode()); err != nil {
+		return err
+	}
+
+	binary.LittleEndian.PutUint16(f.FormatChunk.Format[:], extension.FormatTag())
+
+	// Fields of the extensible format do not apply
+	f.FormatChunk.ValidBitsPerSample = [2]byte{}
+	f.FormatChunk.ChannelMask = [4]byte{}
+	f.FormatChunk.SubFormat = [16]byte{}
+
+	return f.updateSize()
+}
@@ -0,0 +1,262 @@
+package wav
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	ErrNotCompressed           = errors.New("audio data is not compressed")
+	ErrFramingNotSupported     = errors.New("frames of the compressed format cannot be delimited")
+	ErrDecodeCompressedFrame   = errors.New("compressed frame header is malformed")
+	ErrCompressedFrameTooShort = errors.New("compressed frame exceeds audio data")
+)
+
+// CompressedFrame is a frame of compressed audio data, e.g. an MPEG audio
+// frame or an ADTS frame.
+type CompressedFrame struct {
+	Offset     int64 // Position in the audio data
+	Data       []byte
+	Samples    int // Samples per channel, zero if unknown
+	SampleRate int // Zero if unknown
+}
+
+// CompressedFrameReader iterates over the frames of compressed audio data
+// without decoding them. MPEG audio and ADTS frames are delimited by their
+// headers, frames of other formats by the block align of the format. AAC
+// without ADTS headers cannot be delimited.
+type CompressedFrameReader struct {
+	reader     io.Reader
+	next       func(header []byte) (CompressedFrame, error)
+	headerSize int
+	offset     int64
+}
+
+// NewCompressedFrameReader returns a CompressedFrameReader over the audio
+// data read from reader. The reader should be limited to the data sub-chunk,
+// e.g. using io.LimitReader after DecodeHeader.
+func NewCompressedFrameReader(reader io.Reader, format FormatChunk) (*CompressedFrameReader, error) {
+	r := &CompressedFrameReader{reader: reader}
+
+	switch tag := format.EffectiveFormat(); tag {
+	case FormatPCM, FormatIEEEFloat, FormatALaw, FormatMuLaw:
+		return nil, fmt.Errorf("%w: format 0x%04X", ErrNotCompressed, tag)
+	case FormatMPEG, FormatMP3:
+		r.headerSize, r.next = 4, mpegFrame
+	case FormatAAC, FormatHEAAC:
+		if extension := format.Extension(); tag == FormatHEAAC && len(extension) >= 2 && binary.LittleEndian.Uint16(extension) != AACPayloadADTS {
+			return nil, fmt.Errorf("%w: he-aac payload type %d", ErrFramingNotSupported, binary.LittleEndian.Uint16(extension))
+		}
+
+		// Raw access units described by the AudioSpecificConfig cannot be
+		// delimited, but AAC is commonly stored as ADTS stream instead
+		if tag == FormatAAC {
+			buffered := bufio.NewReader(reader)
+
+			if sync, _ := buffered.Peek(2); len(sync) < 2 || sync[0] != 0xFF || sync[1]&0xF6 != 0xF0 {
+				return nil, fmt.Errorf("%w: aac without adts headers", ErrFramingNotSupported)
+			}
+
+			r.reader = buffered
+		}
+
+		r.headerSize, r.next = 7, adtsFrame
+	default:
+		blockAlign := int(binary.LittleEndian.Uint16(format.BlockAlign[:]))
+		if blockAlign <= 1 {
+			return nil, fmt.Errorf("%w: format 0x%04X with block align %d", ErrFramingNotSupported, tag, blockAlign)
+		}
+
+		r.headerSize = blockAlign
+		r.next = func([]byte) (CompressedFrame, error) {
+			return CompressedFrame{Data: make([]byte, blockAlign)}, nil
+		}
+	}
+
+	return r, nil
+}
+
+// ReadFrame reads the next frame, returning io.EOF at the end of the audio
+// data.
+func (r *CompressedFrameReader) ReadFrame() (CompressedFrame, error) {
+	header := make([]byte, r.headerSize)
+
+	if n, err := io.ReadFull(r.reader, header); err == io.EOF {
+		return CompressedFrame{}, io.EOF
+	} else if err != nil {
+		return CompressedFrame{}, fmt.Errorf("reading frame at offset %d: %w", r.offset+int64(n), err)
+	}
+
+	frame, err := r.next(header)
+	if err != nil {
+		return CompressedFrame{}, fmt.Errorf("reading frame at offset %d: %w", r.offset, err)
+	}
+
+	frame.Offset = r.offset
+	copy(frame.Data, header)
+
+	if _, err := io.ReadFull(r.reader, frame.Data[len(header):]); err != nil {
+		return CompressedFrame{}, fmt.Errorf("reading frame at offset %d: %w", r.offset, ErrCompressedFrameTooShort)
+	}
+
+	r.offset += int64(len(frame.Data))
+
+	return frame, nil
+}
+
+// CompressedFrames returns the frames of the compressed audio data.
+func (f *WAVEFileFormat) CompressedFrames() ([]CompressedFrame, error) {
+	reader, err := NewCompressedFrameReader(bytes.NewReader(f.DataChunk.Data), f.FormatChunk)
+	if err != nil {
+		return nil, err
+	}
+
+	var frames []CompressedFrame
+
+	for {
+		frame, err := reader.ReadFrame()
+		if errors.Is(err, io.EOF) {
+			return frames, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		frames = append(frames, frame)
+	}
+}
+
+// NewCompressed returns a WAV file holding compressed audio data in the
+// format of the codec-specific extension. Unless the audio data cannot be
+// delimited into frames, the byte rate and the sample length of the fact
+// sub-chunk are derived from its frames.
+func NewCompressed(cfg Config, extension FormatExtension, data []byte) (*WAVEFileFormat, error) {
+	cfg.Format = extension.FormatTag()
+	cfg.ChannelMask = 0
+
+	file, err := New(cfg, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := file.SetFormatExtension(extension); err != nil {
+		return nil, err
+	}
+
+	// Compressed frames are not aligned to whole samples
+	if binary.LittleEndian.Uint16(file.FormatChunk.BlockAlign[:]) == 0 {
+		binary.LittleEndian.PutUint16(file.FormatChunk.BlockAlign[:], 1)
+	}
+
+	frames, err := file.CompressedFrames()
+	if errors.Is(err, ErrFramingNotSupported) {
+		return file, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var samples uint64
+	for _, frame := range frames {
+		samples += uint64(frame.Samples)
+	}
+
+	if samples > math.MaxUint32 {
+		return nil, ErrDataTooLarge
+	}
+
+	binary.LittleEndian.PutUint32(file.FactChunk.SampleLength[:], uint32(samples))
+
+	if samples > 0 {
+		byteRate := uint64(len(data)) * uint64(cfg.SampleRate) / samples
+		binary.LittleEndian.PutUint32(file.FormatChunk.ByteRate[:], uint32(min(byteRate, math.MaxUint32)))
+	}
+
+	return file, nil
+}
+
+// Bit rates in kbit/s of MPEG-1 and MPEG-2 audio by layer and bit rate index
+var (
+	mpeg1BitRates = [3][15]int{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	}
+	mpeg2BitRates = [3][15]int{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+)
+
+// mpegFrame returns the frame of the 4-byte MPEG audio frame header.
+func mpegFrame(header []byte) (CompressedFrame, error) {
+	if header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return CompressedFrame{}, fmt.Errorf("%w: missing mpeg sync word", ErrDecodeCompressedFrame)
+	}
+
+	version := header[1] >> 3 & 0x03 // 0: MPEG-2.5, 2: MPEG-2, 3: MPEG-1
+	layer := 3 - int(header[1]>>1&0x03)
+	bitRateIndex := header[2] >> 4
+	sampleRateIndex := header[2] >> 2 & 0x03
+	padding := int(header[2] >> 1 & 0x01)
+
+	if version == 1 || layer == 3 || bitRateIndex == 0 || bitRateIndex == 15 || sampleRateIndex == 3 {
+		return CompressedFrame{}, fmt.Errorf("%w: reserved or free format mpeg header", ErrDecodeCompressedFrame)
+	}
+
+	sampleRate := [3]int{44100, 48000, 32000}[sampleRateIndex]
+	bitRate := mpeg1BitRates[layer][bitRateIndex]
+
+	switch version {
+	case 0:
+		sampleRate /= 4
+		bitRate = mpeg2BitRates[layer][bitRateIndex]
+	case 2:
+		sampleRate /= 2
+		bitRate = mpeg2BitRates[layer][bitRateIndex]
+	}
+
+	// Layer I frames consist of 4-byte slots
+	samples := [3]int{384, 1152, 1152}[layer]
+	if layer == 2 && version != 3 {
+		samples = 576
+	}
+
+	size := samples / 8 * bitRate * 1000 / sampleRate
+	if layer == 0 {
+		size = size/4*4 + padding*4
+	} else {
+		size += padding
+	}
+
+	return CompressedFrame{Data: make([]byte, size), Samples: samples, SampleRate: sampleRate}, nil
+}
+
+// Sampling frequencies of ADTS frames by index
+var adtsSampleRates = [13]int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// adtsFrame returns the frame of the 7-byte ADTS header.
+func adtsFrame(header []byte) (CompressedFrame, error) {
+	if header[0] != 0xFF || header[1]&0xF6 != 0xF0 {
+		return CompressedFrame{}, fmt.Errorf("%w: missing adts sync word", ErrDecodeCompressedFrame)
+	}
+
+	sampleRateIndex := int(header[2] >> 2 & 0x0F)
+	if sampleRateIndex >= len(adtsSampleRates) {
+		return CompressedFrame{}, fmt.Errorf("%w: reserved adts sampling frequency", ErrDecodeCompressedFrame)
+	}
+
+	size := int(header[3]&0x03)<<11 | int(header[4])<<3 | int(header[5]>>5)
+	if size < len(header) {
+		return CompressedFrame{}, fmt.Errorf("%w: adts frame length %d", ErrDecodeCompressedFrame, size)
+	}
+
+	// Raw data blocks of 1024 samples each
+	blocks := int(header[6]&0x03) + 1
+
+	return CompressedFrame{Data: make([]byte, size), Samples: blocks * 1024, SampleRate: adtsSampleRates[sampleRateIndex]}, nil
+}
@@ -0,0 +1,217 @@
+package wav_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+// mpegFrames returns MPEG-1 layer III frames of 128 kbit/s at 44.1 kHz, of
+// which the second is padded.
+func mpegFrames() []byte {
+	var data []byte
+
+	for i := range 3 {
+		frame := make([]byte, 417)
+		frame[0], frame[1], frame[2], frame[3] = 0xFF, 0xFB, 0x90, 0x64
+
+		if i == 1 {
+			frame[2] |= 0x02
+			frame = append(frame, 0)
+		}
+
+		data = append(data, frame...)
+	}
+
+	return data
+}
+
+// adtsFrame returns an AAC LC frame of 44.1 kHz stereo with the payload.
+func adtsFrame(payload []byte) []byte {
+	size := 7 + len(payload)
+
+	header := []byte{
+		0xFF, 0xF1,
+		1<<6 | 4<<2,           // Profile LC, 44.1 kHz
+		2<<6 | byte(size>>11), // Stereo
+		byte(size >> 3),
+		byte(size)<<5 | 0x1F,
+		0xFC, // One raw data block
+	}
+
+	return append(header, payload...)
+}
+
+func roundTrip(t *testing.T, file *wav.WAVEFileFormat) *wav.WAVEFileFormat {
+	t.Helper()
+
+	var buffer bytes.Buffer
+
+	if err := file.Encode(&buffer); err != nil {
+		t.Fatalf("encoding: %s", err.Error())
+	}
+
+	if buffer.Len() != file.Size()+8 {
+		t.Errorf("encoded size: got %d, want %d", buffer.Len(), file.Size()+8)
+	}
+
+	decoded := &wav.WAVEFileFormat{}
+
+	if err := decoded.Decode(bytes.NewReader(buffer.Bytes())); err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	return decoded
+}
+
+func TestCompressedMP3(t *testing.T) {
+	extension := &wav.MPEGLayer3Format{
+		ID:             wav.MPEGLayer3IDMPEG,
+		Flags:          wav.MPEGLayer3FlagPaddingISO,
+		BlockSize:      417,
+		FramesPerBlock: 1,
+		CodecDelay:     1105,
+	}
+
+	file, err := wav.NewCompressed(wav.Config{Channels: 2, SampleRate: 44100}, extension, mpegFrames())
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	decoded := roundTrip(t, file)
+
+	if size := binary.LittleEndian.Uint32(decoded.FormatChunk.Chunk.Size[:]); size != wav.FormatChunkSizeNonPCM+wav.MPEGLayer3ExtensionSize {
+		t.Errorf("format size: got %d, want %d", size, wav.FormatChunkSizeNonPCM+wav.MPEGLayer3ExtensionSize)
+	}
+
+	// Format sub-chunks with extensions remain comparable
+	if decoded.FormatChunk != file.FormatChunk {
+		t.Errorf("format sub-chunk: got %+v, want %+v", decoded.FormatChunk, file.FormatChunk)
+	}
+
+	got, err := decoded.FormatExtension()
+	if err != nil {
+		t.Fatalf("format extension: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(got, extension) {
+		t.Errorf("format extension: got %+v, want %+v", got, extension)
+	}
+
+	frames, err := decoded.CompressedFrames()
+	if err != nil {
+		t.Fatalf("frames: %s", err.Error())
+	}
+
+	if len(frames) != 3 || len(frames[1].Data) != 418 || frames[2].Offset != 835 || frames[0].Samples != 1152 || frames[0].SampleRate != 44100 {
+		t.Errorf("frames: got %d frames, want 3 frames of 1152 samples at 44.1 kHz", len(frames))
+	}
+
+	if length := binary.LittleEndian.Uint32(decoded.FactChunk.SampleLength[:]); length != 3*1152 {
+		t.Errorf("sample length: got %d, want %d", length, 3*1152)
+	}
+
+	if byteRate := binary.LittleEndian.Uint32(decoded.FormatChunk.ByteRate[:]); byteRate < 15900 || byteRate > 16100 {
+		t.Errorf("byte rate: got %d, want about 16000", byteRate)
+	}
+}
+
+func TestCompressedAAC(t *testing.T) {
+	// Odd-sized extension, which is padded
+	extension := &wav.AACFormat{AudioSpecificConfig: []byte{0x12, 0x10, 0x56}}
+	data := append(adtsFrame(make([]byte, 100)), adtsFrame(make([]byte, 50))...)
+
+	file, err := wav.NewCompressed(wav.Config{Channels: 2, SampleRate: 44100, BitDepth: 16}, extension, data)
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	decoded := roundTrip(t, file)
+
+	got, err := decoded.FormatExtension()
+	if err != nil {
+		t.Fatalf("format extension: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(got, extension) {
+		t.Errorf("format extension: got %+v, want %+v", got, extension)
+	}
+
+	frames, err := decoded.CompressedFrames()
+	if err != nil {
+		t.Fatalf("frames: %s", err.Error())
+	}
+
+	if len(frames) != 2 || len(frames[0].Data) != 107 || frames[1].Samples != 1024 {
+		t.Errorf("frames: got %d frames, want 2 frames of 1024 samples", len(frames))
+	}
+
+	// Truncated last frame
+	file.DataChunk.Data = data[:len(data)-1]
+
+	if _, err := file.CompressedFrames(); !errors.Is(err, wav.ErrCompressedFrameTooShort) {
+		t.Errorf("truncated frame: got %v, want %v", err, wav.ErrCompressedFrameTooShort)
+	}
+
+	// Raw access units without ADTS headers
+	raw, err := wav.NewCompressed(wav.Config{Channels: 2, SampleRate: 44100, BitDepth: 16}, extension, []byte{0x21, 0x10, 0x05, 0x00})
+	if err != nil {
+		t.Fatalf("creating raw aac file: %s", err.Error())
+	}
+
+	if _, err := raw.CompressedFrames(); !errors.Is(err, wav.ErrFramingNotSupported) {
+		t.Errorf("raw aac frames: got %v, want %v", err, wav.ErrFramingNotSupported)
+	}
+}
+
+func TestCompressedOpus(t *testing.T) {
+	extension := &wav.OpusFormat{
+		Version:         1,
+		Channels:        3,
+		PreSkip:         312,
+		InputSampleRate: 48000,
+		OutputGain:      -256,
+		MappingFamily:   1,
+		StreamCount:     2,
+		CoupledCount:    1,
+		Mapping:         []byte{0, 2, 1},
+	}
+
+	// Frames cannot be delimited without block align
+	file, err := wav.NewCompressed(wav.Config{Channels: 3, SampleRate: 48000}, extension, make([]byte, 10))
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	got, err := roundTrip(t, file).FormatExtension()
+	if err != nil {
+		t.Fatalf("format extension: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(got, extension) {
+		t.Errorf("format extension: got %+v, want %+v", got, extension)
+	}
+
+	if _, err := file.CompressedFrames(); !errors.Is(err, wav.ErrFramingNotSupported) {
+		t.Errorf("frames: got %v, want %v", err, wav.ErrFramingNotSupported)
+	}
+}
+
+func TestCompressedPCM(t *testing.T) {
+	file, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 16}, make([]byte, 4))
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	if _, err := file.CompressedFrames(); !errors.Is(err, wav.ErrNotCompressed) {
+		t.Errorf("frames: got %v, want %v", err, wav.ErrNotCompressed)
+	}
+
+	if _, err := file.FormatExtension(); !errors.Is(err, wav.ErrFormatExtensionNotSupported) {
+		t.Errorf("format extension: got %v, want %v", err, wav.ErrFormatExtensionNotSupported)
+	}
+}
@@ -0,0 +1,453 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Size of a cue point in the cue sub-chunk
+const CuePointSize = 24
+
+var (
+	ErrDecodeCue            = errors.New("cue sub-chunk is malformed")
+	ErrDecodeAssociatedData = errors.New("LIST adtl sub-chunk is malformed")
+)
+
+// CuePoint marks a position in the audio data.
+type CuePoint struct {
+	ID           uint32  // Unique identifier referenced by labels and playlists
+	Position     uint32  // Sample frame position in play order
+	ChunkID      [4]byte // Sub-chunk containing the cue point, usually data
+	ChunkStart   uint32  // Offset of the containing wave list chunk, if any
+	BlockStart   uint32  // Offset of the block containing the cue point
+	SampleOffset uint32  // Sample frame offset within the block
+}
+
+// NewCuePoint returns a cue point at a frame of the audio data.
+func NewCuePoint(id uint32, frame int) CuePoint {
+	return CuePoint{
+		ID:           id,
+		Position:     uint32(frame),
+		ChunkID:      [4]byte{'d', 'a', 't', 'a'},
+		SampleOffset: uint32(frame),
+	}
+}
+
+// CueChunk holds the cue points of a file.
+type CueChunk struct {
+	Points []CuePoint
+}
+
+// Decode decodes the cue sub-chunk data.
+func (c *CueChunk) Decode(data []byte) error {
+	if len(data) < 4 {
+		return ErrDecodeCue
+	}
+
+	count := binary.LittleEndian.Uint32(data[0:4])
+	data = data[4:]
+
+	if uint64(count)*CuePointSize > uint64(len(data)) {
+		return fmt.Errorf("%w: %d cue points exceed the sub-chunk", ErrDecodeCue, count)
+	}
+
+	c.Points = make([]CuePoint, count)
+
+	for i := range c.Points {
+		point := data[i*CuePointSize : (i+1)*CuePointSize]
+
+		c.Points[i] = CuePoint{
+			ID:           binary.LittleEndian.Uint32(point[0:4]),
+			Position:     binary.LittleEndian.Uint32(point[4:8]),
+			ChunkID:      [4]byte(point[8:12]),
+			ChunkStart:   binary.LittleEndian.Uint32(point[12:16]),
+			BlockStart:   binary.LittleEndian.Uint32(point[16:20]),
+			SampleOffset: binary.LittleEndian.Uint32(point[20:24]),
+		}
+	}
+
+	return nil
+}
+
+// Encode encodes the cue sub-chunk data.
+func (c *CueChunk) Encode() []byte {
+	data := make([]byte, 4, 4+len(c.Points)*CuePointSize)
+	binary.LittleEndian.PutUint32(data, uint32(len(c.Points)))
+
+	for _, point := range c.Points {
+		data = binary.LittleEndian.AppendUint32(data, point.ID)
+		data = binary.LittleEndian.AppendUint32(data, point.Position)
+		data = append(data, point.ChunkID[:]...)
+		data = binary.LittleEndian.AppendUint32(data, point.ChunkStart)
+		data = binary.LittleEndian.AppendUint32(data, point.BlockStart)
+		data = binary.LittleEndian.AppendUint32(data, point.SampleOffset)
+	}
+
+	return data
+}
+
+// Point returns the cue point with the given ID.
+func (c *CueChunk) Point(id uint32) (CuePoint, bool) {
+	for _, point := range c.Points {
+		if point.ID == id {
+			return point, true
+		}
+	}
+
+	return CuePoint{}, false
+}
+
+// CueLabel is a label or note text of a cue point.
+type CueLabel struct {
+	CueID uint32
+	Text  string
+}
+
+// CueRegion is a labeled text spanning a number of frames from a cue point.
+type CueRegion struct {
+	CueID        uint32
+	SampleLength uint32  // Length of the region in sample frames
+	Purpose      [4]byte // Such as rgn
+	Country      uint16
+	Language     uint16
+	Dialect      uint16
+	CodePage     uint16
+	Text         string
+}
+
+// AssociatedDataChunk holds the labels, notes and regions of cue points
+// stored in a LIST adtl sub-chunk. Other sub-chunks of the list are not
+// preserved.
+type AssociatedDataChunk struct {
+	Labels  []CueLabel
+	Notes   []CueLabel
+	Regions []CueRegion
+}
+
+// Decode decodes the LIST adtl sub-chunk data following the list type.
+func (c *AssociatedDataChunk) Decode(data []byte) error {
+	*c = AssociatedDataChunk{}
+
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return ErrDecodeAssociatedData
+		}
+
+		id := [4]byte(data[0:4])
+		size := binary.LittleEndian.Uint32(data[4:8])
+		data = data[8:]
+
+		if uint64(size) > uint64(len(data)) {
+			return fmt.Errorf("%w: '%s' sub-chunk exceeds the list", ErrDecodeAssociatedData, id[:])
+		}
+
+		body := data[:size]
+		data = data[min(int(size)+int(size)%2, len(data)):]
+
+		switch id {
+		case [4]byte{'l', 'a', 'b', 'l'}, [4]byte{'n', 'o', 't', 'e'}:
+			if len(body) < 4 {
+				return fmt.Errorf("%w: '%s' sub-chunk is too short", ErrDecodeAssociatedData, id[:])
+			}
+
+			label := CueLabel{
+				CueID: binary.LittleEndian.Uint32(body[0:4]),
+				Text:  decodeString(body[4:]),
+			}
+
+			if id == [4]byte{'l', 'a', 'b', 'l'} {
+				c.Labels = append(c.Labels, label)
+			} else {
+				c.Notes = append(c.Notes, label)
+			}
+		case [4]byte{'l', 't', 'x', 't'}:
+			if len(body) < 20 {
+				return fmt.Errorf("%w: 'ltxt' sub-chunk is too short", ErrDecodeAssociatedData)
+			}
+
+			c.Regions = append(c.Regions, CueRegion{
+				CueID:        binary.LittleEndian.Uint32(body[0:4]),
+				SampleLength: binary.LittleEndian.Uint32(body[4:8]),
+				Purpose:      [4]byte(body[8:12]),
+				Country:      binary.LittleEndian.Uint16(body[12:14]),
+				Language:     binary.LittleEndian.Uint16(body[14:16]),
+				Dialect:      binary.LittleEndian.Uint16(body[16:18]),
+				CodePage:     binary.LittleEndian.Uint16(body[18:20]),
+				Text:         decodeString(body[20:]),
+			})
+		}
+	}
+
+	return nil
+}
+
+// Encode encodes the LIST adtl sub-chunk data following the list type.
+func (c *AssociatedDataChunk) Encode() []byte {
+	var data []byte
+
+	appendSubChunk := func(id [4]byte, body []byte) {
+		data = append(data, id[:]...)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(body)))
+		data = append(data, body...)
+
+		if len(body)%2 != 0 {
+			data = append(data, 0)
+		}
+	}
+
+	for _, label := range c.Labels {
+		appendSubChunk([4]byte{'l', 'a', 'b', 'l'}, encodeCueLabel(label))
+	}
+
+	for _, note := range c.Notes {
+		appendSubChunk([4]byte{'n', 'o', 't', 'e'}, encodeCueLabel(note))
+	}
+
+	for _, region := range c.Regions {
+		body := binary.LittleEndian.AppendUint32(nil, region.CueID)
+		body = binary.LittleEndian.AppendUint32(body, region.SampleLength)
+		body = append(body, region.Purpose[:]...)
+		body = binary.LittleEndian.AppendUint16(body, region.Country)
+		body = binary.LittleEndian.AppendUint16(body, region.Language)
+		body = binary.LittleEndian.AppendUint16(body, region.Dialect)
+		body = binary.LittleEndian.AppendUint16(body, region.CodePage)
+
+		if region.Text != "" {
+			body = append(append(body, region.Text...), 0)
+		}
+
+		appendSubChunk([4]byte{'l', 't', 'x', 't'}, body)
+	}
+
+	return data
+}
+
+func encodeCueLabel(label CueLabel) []byte {
+	body := binary.LittleEndian.AppendUint32(nil, label.CueID)
+
+	return append(append(body, label.Text...), 0)
+}
+
+// Label returns the label of the cue point with the given ID.
+func (c *AssociatedDataChunk) Label(id uint32) (string, bool) {
+	for _, label := range c.Labels {
+		if label.CueID == id {
+			return label.Text, true
+		}
+	}
+
+	return "", false
+}
+
+// Region returns the region of the cue point with the given ID.
+func (c *AssociatedDataChunk) Region(id uint32) (CueRegion, bool) {
+	for _, region := range c.Regions {
+		if region.CueID == id {
+			return region, true
+		}
+	}
+
+	return CueRegion{}, false
+}
+
+// Cue returns the decoded cue sub-chunk.
+func (f *WAVEFileFormat) Cue() (*CueChunk, error) {
+	data, err := f.SubChunk([4]byte{'c', 'u', 'e', ' '})
+	if err != nil {
+		return nil, err
+	}
+
+	cue := &CueChunk{}
+
+	if err := cue.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return cue, nil
+}
+
+// SetCue encodes the cue sub-chunk, replacing an existing one.
+func (f *WAVEFileFormat) SetCue(cue *CueChunk) error {
+	return f.SetSubChunk([4]byte{'c', 'u', 'e', ' '}, cue.Encode())
+}
+
+// AssociatedData returns the decoded LIST adtl sub-chunk.
+func (f *WAVEFileFormat) AssociatedData() (*AssociatedDataChunk, error) {
+	data, err := f.ListChunk([4]byte{'a', 'd', 't', 'l'})
+	if err != nil {
+		return nil, err
+	}
+
+	adtl := &AssociatedDataChunk{}
+
+	if err := adtl.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return adtl, nil
+}
+
+// SetAssociatedData encodes the LIST adtl sub-chunk, replacing an existing
+// one.
+func (f *WAVEFileFormat) SetAssociatedData(adtl *AssociatedDataChunk) error {
+	return f.SetListChunk([4]byte{'a', 'd', 't', 'l'}, adtl.Encode())
+}
+
+// cues returns the cue points and associated data of the file, which are
+// empty if absent.
+func (f *WAVEFileFormat) cues() (*CueChunk, *AssociatedDataChunk, error) {
+	cue, err := f.Cue()
+	if errors.Is(err, ErrSubChunkNotFound) {
+		cue = &CueChunk{}
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	adtl, err := f.AssociatedData()
+	if errors.Is(err, ErrSubChunkNotFound) {
+		adtl = &AssociatedDataChunk{}
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return cue, adtl, nil
+}
+
+// setCues replaces the cue points and associated data, removing the
+// sub-chunks if empty.
+func (f *WAVEFileFormat) setCues(cue *CueChunk, adtl *AssociatedDataChunk) error {
+	if len(cue.Points) == 0 {
+		if err := f.RemoveSubChunk([4]byte{'c', 'u', 'e', ' '}); err != nil {
+			return err
+		}
+	} else if err := f.SetCue(cue); err != nil {
+		return err
+	}
+
+	if len(adtl.Labels)+len(adtl.Notes)+len(adtl.Regions) == 0 {
+		return f.RemoveListChunk([4]byte{'a', 'd', 't', 'l'})
+	}
+
+	return f.SetAssociatedData(adtl)
+}
+
+// sliceCues keeps the cue points within the frames from start up to but not
+// including end, moved relative to start, together with their associated
+// data. Regions are shortened to end at the slice.
+func (f *WAVEFileFormat) sliceCues(start, end int) error {
+	if _, err := f.SubChunk([4]byte{'c', 'u', 'e', ' '}); errors.Is(err, ErrSubChunkNotFound) {
+		return nil
+	}
+
+	cue, adtl, err := f.cues()
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[uint32]uint32)
+	points := cue.Points[:0]
+
+	for _, point := range cue.Points {
+		frame := int(point.SampleOffset)
+
+		if frame < start || frame >= end {
+			continue
+		}
+
+		// The play order position may precede the slice
+		point.Position -= min(point.Position, uint32(start))
+		point.SampleOffset -= uint32(start)
+		points = append(points, point)
+		kept[point.ID] = point.SampleOffset
+	}
+
+	cue.Points = points
+
+	adtl.Labels = keepCueLabels(adtl.Labels, kept)
+	adtl.Notes = keepCueLabels(adtl.Notes, kept)
+
+	regions := adtl.Regions[:0]
+
+	for _, region := range adtl.Regions {
+		if offset, ok := kept[region.CueID]; ok {
+			region.SampleLength = min(region.SampleLength, uint32(end-start)-offset)
+			regions = append(regions, region)
+		}
+	}
+
+	adtl.Regions = regions
+
+	return f.setCues(cue, adtl)
+}
+
+func keepCueLabels(labels []CueLabel, kept map[uint32]uint32) []CueLabel {
+	result := labels[:0]
+
+	for _, label := range labels {
+		if _, ok := kept[label.CueID]; ok {
+			result = append(result, label)
+		}
+	}
+
+	return result
+}
+
+// joinCues sets the cue points and associated data of all files, moved by
+// the given frame offsets of each file. Cue points are renumbered from one
+// to keep their IDs unique.
+func (f *WAVEFileFormat) joinCues(files []*WAVEFileFormat, offsets []int) error {
+	joinedCue := &CueChunk{}
+	joinedAdtl := &AssociatedDataChunk{}
+	found := false
+
+	for i, file := range files {
+		if _, err := file.SubChunk([4]byte{'c', 'u', 'e', ' '}); errors.Is(err, ErrSubChunkNotFound) {
+			continue
+		}
+
+		found = true
+
+		cue, adtl, err := file.cues()
+		if err != nil {
+			return fmt.Errorf("file %d: %w", i, err)
+		}
+
+		ids := make(map[uint32]uint32, len(cue.Points))
+
+		for _, point := range cue.Points {
+			id := uint32(len(joinedCue.Points) + 1)
+			ids[point.ID] = id
+
+			point.ID = id
+			point.Position += uint32(offsets[i])
+			point.SampleOffset += uint32(offsets[i])
+			joinedCue.Points = append(joinedCue.Points, point)
+		}
+
+		for _, label := range adtl.Labels {
+			if id, ok := ids[label.CueID]; ok {
+				joinedAdtl.Labels = append(joinedAdtl.Labels, CueLabel{CueID: id, Text: label.Text})
+			}
+		}
+
+		for _, note := range adtl.Notes {
+			if id, ok := ids[note.CueID]; ok {
+				joinedAdtl.Notes = append(joinedAdtl.Notes, CueLabel{CueID: id, Text: note.Text})
+			}
+		}
+
+		for _, region := range adtl.Regions {
+			if id, ok := ids[region.CueID]; ok {
+				region.CueID = id
+				joinedAdtl.Regions = append(joinedAdtl.Regions, region)
+			}
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	return f.setCues(joinedCue, joinedAdtl)
+}
@@ -0,0 +1,110 @@
+package wav_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestCues(t *testing.T) {
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, make([]byte, 100))
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	cue := &wav.CueChunk{Points: []wav.CuePoint{wav.NewCuePoint(7, 10), wav.NewCuePoint(3, 60)}}
+
+	if err := waveFile.SetCue(cue); err != nil {
+		t.Fatalf("setting cue: %s", err.Error())
+	}
+
+	adtl := &wav.AssociatedDataChunk{
+		Labels:  []wav.CueLabel{{CueID: 7, Text: "intro"}, {CueID: 3, Text: "verse"}},
+		Notes:   []wav.CueLabel{{CueID: 3, Text: "odd"}},
+		Regions: []wav.CueRegion{{CueID: 3, SampleLength: 30, Purpose: [4]byte{'r', 'g', 'n', ' '}}},
+	}
+
+	if err := waveFile.SetAssociatedData(adtl); err != nil {
+		t.Fatalf("setting associated data: %s", err.Error())
+	}
+
+	decodedAdtl, err := waveFile.AssociatedData()
+	if err != nil {
+		t.Fatalf("decoding associated data: %s", err.Error())
+	}
+
+	if !slices.Equal(decodedAdtl.Labels, adtl.Labels) || !slices.Equal(decodedAdtl.Notes, adtl.Notes) || !slices.Equal(decodedAdtl.Regions, adtl.Regions) {
+		t.Errorf("associated data: got %+v, want %+v", decodedAdtl, adtl)
+	}
+
+	first, err := waveFile.Slice(0, 50)
+	if err != nil {
+		t.Fatalf("slicing: %s", err.Error())
+	}
+
+	second, err := waveFile.Slice(50, 80)
+	if err != nil {
+		t.Fatalf("slicing: %s", err.Error())
+	}
+
+	if cue, err := second.Cue(); err != nil {
+		t.Fatalf("decoding cue: %s", err.Error())
+	} else if want := []wav.CuePoint{wav.NewCuePoint(3, 10)}; !slices.Equal(cue.Points, want) {
+		t.Errorf("sliced cue points: got %v, want %v", cue.Points, want)
+	}
+
+	if adtl, err := second.AssociatedData(); err != nil {
+		t.Fatalf("decoding associated data: %s", err.Error())
+	} else if label, _ := adtl.Label(3); label != "verse" || len(adtl.Labels) != 1 || adtl.Regions[0].SampleLength != 20 {
+		t.Errorf("sliced associated data: got %+v", adtl)
+	}
+
+	joined, err := wav.Concat(second, first)
+	if err != nil {
+		t.Fatalf("concatenating: %s", err.Error())
+	}
+
+	joinedCue, err := joined.Cue()
+	if err != nil {
+		t.Fatalf("decoding cue: %s", err.Error())
+	}
+
+	if want := []wav.CuePoint{wav.NewCuePoint(1, 10), wav.NewCuePoint(2, 40)}; !slices.Equal(joinedCue.Points, want) {
+		t.Errorf("joined cue points: got %v, want %v", joinedCue.Points, want)
+	}
+
+	if adtl, err := joined.AssociatedData(); err != nil {
+		t.Fatalf("decoding associated data: %s", err.Error())
+	} else if label, _ := adtl.Label(2); label != "intro" {
+		t.Errorf("joined label of cue point 2: got %q, want %q", label, "intro")
+	}
+}
+
+func TestSliceCuePosition(t *testing.T) {
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, make([]byte, 100))
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	point := wav.NewCuePoint(1, 60)
+	point.Position = 20
+
+	if err := waveFile.SetCue(&wav.CueChunk{Points: []wav.CuePoint{point}}); err != nil {
+		t.Fatalf("setting cue: %s", err.Error())
+	}
+
+	sliced, err := waveFile.Slice(50, 80)
+	if err != nil {
+		t.Fatalf("slicing: %s", err.Error())
+	}
+
+	want := wav.NewCuePoint(1, 10)
+	want.Position = 0
+
+	if cue, err := sliced.Cue(); err != nil {
+		t.Fatalf("decoding cue: %s", err.Error())
+	} else if !slices.Equal(cue.Points, []wav.CuePoint{want}) {
+		t.Errorf("sliced cue points: got %v, want %v", cue.Points, []wav.CuePoint{want})
+	}
+}
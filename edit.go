@@ -0,0 +1,145 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrFrameRange     = errors.New("frame range exceeds audio data")
+	ErrFormatMismatch = errors.New("format sub-chunks do not match")
+	ErrNoFiles        = errors.New("no files given")
+	ErrDataNotLoaded  = errors.New("audio data is shorter than the data sub-chunk size")
+)
+
+// Slice returns a copy of the file, including its metadata sub-chunks, with
+// the audio data of the frames from start up to but not including end. All
+// sizes are recalculated and the bext time reference, if any, is moved to
+// the first frame of the slice. Cue points outside the slice are removed
+// and the others are moved along.
+func (f *WAVEFileFormat) Slice(start, end int) (*WAVEFileFormat, error) {
+	if start < 0 || end < start || end > f.Frames() {
+		return nil, fmt.Errorf("%w: frames %d to %d of %d", ErrFrameRange, start, end, f.Frames())
+	}
+
+	data, blockAlign, err := f.frameData()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := f.withData(bytes.Clone(data[start*blockAlign : end*blockAlign]))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := file.shiftTimeReference(start); err != nil {
+		return nil, err
+	}
+
+	if err := file.sliceCues(start, end); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Concat returns a copy of the first file, including its metadata
+// sub-chunks, with the audio data of all files joined in order. The cue
+// points of all files are kept at their position in the joined audio data.
+// The formats of all files must match. Trailing partial frames are dropped,
+// so every file starts on a frame boundary.
+func Concat(files ...*WAVEFileFormat) (*WAVEFileFormat, error) {
+	if len(files) == 0 {
+		return nil, ErrNoFiles
+	}
+
+	frames := make([][]byte, len(files))
+	blockAlign := 0
+	size := 0
+
+	for i, file := range files {
+		if !files[0].FormatChunk.matches(&file.FormatChunk) {
+			return nil, fmt.Errorf("%w: file %d", ErrFormatMismatch, i)
+		}
+
+		data, align, err := file.frameData()
+		if err != nil {
+			return nil, fmt.Errorf("file %d: %w", i, err)
+		}
+
+		frames[i], blockAlign = data, align
+		size += len(data)
+	}
+
+	if uint64(size) > math.MaxUint32-1 {
+		return nil, ErrDataTooLarge
+	}
+
+	data := make([]byte, 0, size)
+	offsets := make([]int, len(files))
+
+	for i := range files {
+		offsets[i] = len(data) / blockAlign
+		data = append(data, frames[i]...)
+	}
+
+	joined, err := files[0].withData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := joined.joinCues(files, offsets); err != nil {
+		return nil, err
+	}
+
+	return joined, nil
+}
+
+// frameData returns the audio data of the whole frames given by the data
+// sub-chunk size, which must be loaded, e.g. not decoded by DecodeHeader.
+func (f *WAVEFileFormat) frameData() ([]byte, int, error) {
+	blockAlign := int(binary.LittleEndian.Uint16(f.FormatChunk.BlockAlign[:]))
+	if blockAlign == 0 {
+		return nil, 0, fmt.Errorf("%w: block align of zero", ErrSampleFormatNotSupported)
+	}
+
+	size := f.Frames() * blockAlign
+	if len(f.DataChunk.Data) < size {
+		return nil, 0, fmt.Errorf("%w: %d of %d bytes", ErrDataNotLoaded, len(f.DataChunk.Data), size)
+	}
+
+	return f.DataChunk.Data[:size], blockAlign, nil
+}
+
+// matches reports whether the audio data described by both format sub-chunks
+// is encoded identically, regardless of the format sub-chunk size.
+func (c *FormatChunk) matches(other *FormatChunk) bool {
+	a, b := *c, *other
+
+	a.Chunk, b.Chunk = Chunk{}, Chunk{}
+	a.ExtensionSize, b.ExtensionSize = [2]byte{}, [2]byte{}
+
+	return a == b
+}
+
+// shiftTimeReference moves the bext time reference, if any, by the given
+// number of frames.
+func (f *WAVEFileFormat) shiftTimeReference(frames int) error {
+	if frames == 0 {
+		return nil
+	}
+
+	bext, err := f.Bext()
+	if errors.Is(err, ErrSubChunkNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	bext.TimeReference += uint64(frames)
+
+	return f.SetBext(bext)
+}
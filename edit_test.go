@@ -0,0 +1,100 @@
+package wav_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestSliceConcat(t *testing.T) {
+	data := make([]byte, 4*100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	waveFile, err := wav.New(wav.Config{Channels: 2, SampleRate: 8000, BitDepth: 16}, data)
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	if err := waveFile.SetBext(&wav.BextChunk{TimeReference: 1000}); err != nil {
+		t.Fatalf("setting bext sub-chunk: %s", err.Error())
+	}
+
+	first, err := waveFile.Slice(0, 30)
+	if err != nil {
+		t.Fatalf("slicing: %s", err.Error())
+	}
+
+	second, err := waveFile.Slice(30, 100)
+	if err != nil {
+		t.Fatalf("slicing: %s", err.Error())
+	}
+
+	if bext, err := second.Bext(); err != nil {
+		t.Fatalf("decoding bext sub-chunk: %s", err.Error())
+	} else if bext.TimeReference != 1030 {
+		t.Errorf("time reference: got %d, want 1030", bext.TimeReference)
+	}
+
+	if _, err := waveFile.Slice(50, 101); !errors.Is(err, wav.ErrFrameRange) {
+		t.Errorf("slicing beyond audio data: got %v, want %v", err, wav.ErrFrameRange)
+	}
+
+	joined, err := wav.Concat(first, second)
+	if err != nil {
+		t.Fatalf("concatenating: %s", err.Error())
+	}
+
+	if !bytes.Equal(joined.Data(), data) {
+		t.Errorf("concatenated audio data does not match original")
+	}
+
+	if joined.Size() != waveFile.Size() || joined.DataSize() != waveFile.DataSize() {
+		t.Errorf("sizes: got %d and %d, want %d and %d", joined.Size(), joined.DataSize(), waveFile.Size(), waveFile.DataSize())
+	}
+
+	mono, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 16}, data)
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	if _, err := wav.Concat(first, mono); !errors.Is(err, wav.ErrFormatMismatch) {
+		t.Errorf("concatenating mismatching formats: got %v, want %v", err, wav.ErrFormatMismatch)
+	}
+}
+
+func TestSliceConcatUnloaded(t *testing.T) {
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 16}, make([]byte, 100))
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	header := &wav.WAVEFileFormat{}
+
+	if err := header.DecodeMetadata(bytes.NewReader(encoded.Bytes())); err != nil {
+		t.Fatalf("decoding metadata: %s", err.Error())
+	}
+
+	if _, err := header.Slice(0, 10); !errors.Is(err, wav.ErrDataNotLoaded) {
+		t.Errorf("slicing unloaded audio data: got %v, want %v", err, wav.ErrDataNotLoaded)
+	}
+
+	if _, err := wav.Concat(waveFile, header); !errors.Is(err, wav.ErrDataNotLoaded) {
+		t.Errorf("concatenating unloaded audio data: got %v, want %v", err, wav.ErrDataNotLoaded)
+	}
+
+	waveFile.FormatChunk.BlockAlign = [2]byte{}
+
+	if _, err := wav.Concat(waveFile, waveFile); !errors.Is(err, wav.ErrSampleFormatNotSupported) {
+		t.Errorf("concatenating with block align of zero: got %v, want %v", err, wav.ErrSampleFormatNotSupported)
+	}
+}
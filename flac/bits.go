@@ -0,0 +1,122 @@
+package flac
+
+import (
+	"io"
+	"math/bits"
+)
+
+// bitReader reads big endian bit fields, updating the CRC-8 and CRC-16 of
+// frame headers and frames over all bytes read.
+type bitReader struct {
+	reader io.ByteReader
+	cache  uint64
+	bits   uint // Unread bits in the lower end of cache, always below 8 between reads
+	crc8   uint8
+	crc16  uint16
+}
+
+func (r *bitReader) fill() error {
+	b, err := r.reader.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	r.cache = r.cache<<8 | uint64(b)
+	r.bits += 8
+	r.crc8 = crc8Table[r.crc8^b]
+	r.crc16 = r.crc16<<8 ^ crc16Table[byte(r.crc16>>8)^b]
+
+	return nil
+}
+
+// readBits reads an unsigned value of up to 56 bits.
+func (r *bitReader) readBits(n uint) (uint64, error) {
+	if n == 0 {
+		return 0, nil
+	}
+
+	for r.bits < n {
+		if err := r.fill(); err != nil {
+			return 0, unexpectedEOF(err)
+		}
+	}
+
+	r.bits -= n
+
+	return r.cache >> r.bits & (1<<n - 1), nil
+}
+
+// readSigned reads a two's complement value of up to 56 bits.
+func (r *bitReader) readSigned(n uint) (int64, error) {
+	value, err := r.readBits(n)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+
+	return int64(value<<(64-n)) >> (64 - n), nil
+}
+
+// readUnary reads the number of zero bits before the next one bit.
+func (r *bitReader) readUnary() (uint64, error) {
+	var count uint64
+
+	for {
+		if r.bits == 0 {
+			if err := r.fill(); err != nil {
+				return 0, unexpectedEOF(err)
+			}
+		}
+
+		value := r.cache & (1<<r.bits - 1)
+		if value == 0 {
+			count += uint64(r.bits)
+			r.bits = 0
+
+			continue
+		}
+
+		zeros := r.bits - uint(bits.Len64(value))
+		count += uint64(zeros)
+		r.bits -= zeros + 1
+
+		return count, nil
+	}
+}
+
+// align skips to the next byte boundary.
+func (r *bitReader) align() {
+	r.bits -= r.bits % 8
+}
+
+func (r *bitReader) resetCRC() {
+	r.crc8, r.crc16 = 0, 0
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+// CRC tables of the polynomials x^8 + x^2 + x + 1 and x^16 + x^15 + x^2 + 1
+var (
+	crc8Table  [256]uint8
+	crc16Table [256]uint16
+)
+
+func init() {
+	for i := range 256 {
+		crc8 := uint8(i)
+		crc16 := uint16(i) << 8
+
+		for range 8 {
+			crc8 = crc8<<1 ^ uint8(0x07*(crc8>>7))
+			crc16 = crc16<<1 ^ 0x8005*(crc16>>15)
+		}
+
+		crc8Table[i] = crc8
+		crc16Table[i] = crc16
+	}
+}
@@ -0,0 +1,348 @@
+package flac
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/samborkent/wav"
+)
+
+// Frames per block of the encoder
+const BlockSize = 4096
+
+// Vendor string of the Vorbis comment written by the encoder
+const vendor = "github.com/samborkent/wav"
+
+// Vorbis comment field holding a channel mask other than the FLAC order
+const channelMaskField = "WAVEFORMATEXTENSIBLE_CHANNEL_MASK"
+
+var (
+	ErrSampleFormatNotSupported = errors.New("sample format cannot be encoded, only 8 to 24-bit pcm with up to 8 channels")
+	ErrEncoderClosed            = errors.New("encoder is closed")
+)
+
+// Encoder encodes audio data in the WAV layout into a FLAC stream. The
+// stream info is written up front and completed when the Encoder is closed.
+type Encoder struct {
+	writer io.WriteSeeker
+	info   StreamInfo
+
+	start          int64 // Position of the stream marker
+	bytesPerSample int
+	buffer         []byte // Audio data of the incomplete block
+	samples        [][]int64
+	md5            hash.Hash
+	frames         uint64
+	frameNumber    uint64
+	closed         bool
+}
+
+// NewEncoder writes the stream info and the LIST INFO fields of header as
+// Vorbis comment to writer, and returns an Encoder for audio data in the
+// format of header. Audio data of the header itself is not written.
+func NewEncoder(writer io.WriteSeeker, header *wav.WAVEFileFormat) (*Encoder, error) {
+	cfg := header.Config()
+
+	if cfg.Format != wav.FormatPCM || cfg.BitDepth < 8 || cfg.BitDepth > 24 || cfg.Channels < 1 || cfg.Channels > 8 {
+		return nil, fmt.Errorf("%w: format 0x%04X with %d channels of %d bits", ErrSampleFormatNotSupported, cfg.Format, cfg.Channels, cfg.BitDepth)
+	}
+
+	if cfg.SampleRate < 1 || cfg.SampleRate >= 1<<20 {
+		return nil, fmt.Errorf("%w: sample rate of %d Hz", ErrSampleFormatNotSupported, cfg.SampleRate)
+	}
+
+	start, err := writer.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("seeking: %w", err)
+	}
+
+	e := &Encoder{
+		writer: writer,
+		info: StreamInfo{
+			MinBlockSize:  BlockSize,
+			MaxBlockSize:  BlockSize,
+			SampleRate:    uint32(cfg.SampleRate),
+			Channels:      cfg.Channels,
+			BitsPerSample: cfg.BitDepth,
+		},
+		start:          start,
+		bytesPerSample: cfg.BitDepth / 8,
+		samples:        make([][]int64, cfg.Channels),
+		md5:            md5.New(),
+	}
+
+	comment, err := newVorbisComment(header)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []byte{'f', 'L', 'a', 'C'}
+	data = appendBlock(data, BlockStreamInfo, false, e.info.encode())
+	data = appendBlock(data, BlockVorbisComment, true, comment.encode())
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("writing metadata: %w", err)
+	}
+
+	return e, nil
+}
+
+// newVorbisComment returns the Vorbis comment of the LIST INFO fields and
+// the channel mask, if it differs from the FLAC channel order.
+func newVorbisComment(header *wav.WAVEFileFormat) (*VorbisComment, error) {
+	comment := &VorbisComment{Vendor: vendor}
+
+	info, err := header.Info()
+	if err == nil {
+		for _, field := range commentFields {
+			if value, ok := info.Get(field.info); ok {
+				comment.Comments = append(comment.Comments, field.name+"="+value)
+			}
+		}
+	} else if !errors.Is(err, wav.ErrSubChunkNotFound) {
+		return nil, err
+	}
+
+	cfg := header.Config()
+	if cfg.ChannelMask != 0 && cfg.ChannelMask != defaultChannelMask(cfg.Channels) {
+		comment.Comments = append(comment.Comments, fmt.Sprintf("%s=0x%X", channelMaskField, cfg.ChannelMask))
+	}
+
+	return comment, nil
+}
+
+// channelMask returns the channel mask of the Vorbis comment field, if any.
+func (c *VorbisComment) channelMask() (uint32, bool) {
+	value, ok := c.Get(channelMaskField)
+	if !ok {
+		return 0, false
+	}
+
+	mask, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(value), "0x"), 16, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint32(mask), true
+}
+
+func appendBlock(data []byte, blockType byte, last bool, block []byte) []byte {
+	if last {
+		blockType |= 0x80
+	}
+
+	data = append(data, blockType, byte(len(block)>>16), byte(len(block)>>8), byte(len(block)))
+
+	return append(data, block...)
+}
+
+func (s *StreamInfo) encode() []byte {
+	data := make([]byte, 0, StreamInfoSize)
+	data = binary.BigEndian.AppendUint16(data, s.MinBlockSize)
+	data = binary.BigEndian.AppendUint16(data, s.MaxBlockSize)
+	data = append(data, byte(s.MinFrameSize>>16), byte(s.MinFrameSize>>8), byte(s.MinFrameSize))
+	data = append(data, byte(s.MaxFrameSize>>16), byte(s.MaxFrameSize>>8), byte(s.MaxFrameSize))
+
+	packed := uint64(s.SampleRate)<<44 | uint64(s.Channels-1)<<41 | uint64(s.BitsPerSample-1)<<36 | s.TotalSamples&(1<<36-1)
+	data = binary.BigEndian.AppendUint64(data, packed)
+
+	return append(data, s.MD5[:]...)
+}
+
+func (c *VorbisComment) encode() []byte {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(len(c.Vendor)))
+	data = append(data, c.Vendor...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(c.Comments)))
+
+	for _, comment := range c.Comments {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(comment)))
+		data = append(data, comment...)
+	}
+
+	return data
+}
+
+// Write encodes audio data in the WAV layout. Partial frames are kept until
+// the next write.
+func (e *Encoder) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, ErrEncoderClosed
+	}
+
+	blockBytes := BlockSize * e.info.Channels * e.bytesPerSample
+	written := len(p)
+
+	for len(e.buffer)+len(p) >= blockBytes {
+		var block []byte
+
+		if len(e.buffer) == 0 {
+			block, p = p[:blockBytes], p[blockBytes:]
+		} else {
+			n := blockBytes - len(e.buffer)
+			e.buffer = append(e.buffer, p[:n]...)
+			block, p = e.buffer, p[n:]
+		}
+
+		if err := e.encodeBlock(block); err != nil {
+			return 0, err
+		}
+
+		e.buffer = e.buffer[:0]
+	}
+
+	e.buffer = append(e.buffer, p...)
+
+	return written, nil
+}
+
+// Close encodes the remaining frames and completes the stream info with the
+// number of frames, the frame sizes and the MD5 of the samples. It does not
+// close the underlying writer.
+func (e *Encoder) Close() error {
+	if e.closed {
+		return nil
+	}
+
+	e.closed = true
+
+	blockAlign := e.info.Channels * e.bytesPerSample
+
+	if frames := len(e.buffer) / blockAlign; frames > 0 {
+		if err := e.encodeBlock(e.buffer[:frames*blockAlign]); err != nil {
+			return err
+		}
+	}
+
+	e.info.TotalSamples = e.frames
+	e.info.MD5 = [16]byte(e.md5.Sum(nil))
+
+	end, err := e.writer.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("seeking: %w", err)
+	}
+
+	// Stream info follows the stream marker and metadata block header
+	if _, err := e.writer.Seek(e.start+8, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking: %w", err)
+	}
+
+	if _, err := e.writer.Write(e.info.encode()); err != nil {
+		return fmt.Errorf("writing stream info: %w", err)
+	}
+
+	if _, err := e.writer.Seek(end, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking: %w", err)
+	}
+
+	return nil
+}
+
+// encodeBlock encodes whole frames of audio data as one FLAC frame.
+func (e *Encoder) encodeBlock(data []byte) error {
+	channels := e.info.Channels
+	blockSize := len(data) / (channels * e.bytesPerSample)
+
+	for channel := range e.samples {
+		e.samples[channel] = e.samples[channel][:0]
+	}
+
+	for i := range blockSize * channels {
+		raw := data[i*e.bytesPerSample : (i+1)*e.bytesPerSample]
+
+		var value int64
+		if e.bytesPerSample == 1 {
+			value = int64(raw[0]) - 128
+		} else {
+			// Sign extension of the most significant byte
+			value = int64(int8(raw[len(raw)-1]))
+			for j := len(raw) - 2; j >= 0; j-- {
+				value = value<<8 | int64(raw[j])
+			}
+		}
+
+		e.samples[i%channels] = append(e.samples[i%channels], value)
+	}
+
+	// The MD5 is computed over signed samples, which only differ from the
+	// audio data for 8 bits
+	if e.bytesPerSample == 1 {
+		signed := make([]byte, len(data))
+		for i, b := range data {
+			signed[i] = b ^ 0x80
+		}
+
+		e.md5.Write(signed)
+	} else {
+		e.md5.Write(data)
+	}
+
+	frame := e.encodeFrame(blockSize)
+
+	if _, err := e.writer.Write(frame); err != nil {
+		return fmt.Errorf("writing frame %d: %w", e.frameNumber, err)
+	}
+
+	size := uint32(len(frame))
+	if e.frameNumber == 0 || size < e.info.MinFrameSize {
+		e.info.MinFrameSize = size
+	}
+
+	e.info.MaxFrameSize = max(e.info.MaxFrameSize, size)
+	e.frames += uint64(blockSize)
+	e.frameNumber++
+
+	return nil
+}
+
+// defaultChannelMask returns the speaker positions of the FLAC channel order,
+// or zero for mono and stereo, which have no fixed positions.
+func defaultChannelMask(channels int) uint32 {
+	if channels >= len(channelMasks) {
+		return 0
+	}
+
+	return channelMasks[channels]
+}
+
+// Encode encodes the audio data and LIST INFO fields of the WAV file as FLAC
+// stream.
+func Encode(writer io.WriteSeeker, file *wav.WAVEFileFormat) error {
+	encoder, err := NewEncoder(writer, file)
+	if err != nil {
+		return err
+	}
+
+	if _, err := encoder.Write(file.DataChunk.Data); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// EncodeStream encodes the WAV file read from reader as FLAC stream without
+// loading the audio data into memory. Only sub-chunks preceding the audio
+// data are taken into account.
+func EncodeStream(writer io.WriteSeeker, reader io.Reader) error {
+	header := &wav.WAVEFileFormat{}
+
+	if err := header.DecodeHeader(reader); err != nil {
+		return err
+	}
+
+	encoder, err := NewEncoder(writer, header)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(encoder, io.LimitReader(reader, int64(header.DataSize()))); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
@@ -0,0 +1,243 @@
+package flac_test
+
+import (
+	"bytes"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/flac"
+)
+
+// testSignal returns a sine with noise in the WAV layout.
+func testSignal(t *testing.T, cfg wav.Config, frames int) *wav.WAVEFileFormat {
+	t.Helper()
+
+	random := rand.New(rand.NewPCG(1, 2))
+	bytesPerSample := cfg.BitDepth / 8
+	data := make([]byte, 0, frames*cfg.Channels*bytesPerSample)
+
+	for i := range frames {
+		for channel := range cfg.Channels {
+			value := 0.5*math.Sin(2*math.Pi*440*float64(i+channel*10)/float64(cfg.SampleRate)) + 0.001*random.NormFloat64()
+			sample := int64(value * float64(int64(1)<<(cfg.BitDepth-1)))
+
+			if bytesPerSample == 1 {
+				sample += 128
+			}
+
+			for j := range bytesPerSample {
+				data = append(data, byte(sample>>(8*j)))
+			}
+		}
+	}
+
+	file, err := wav.New(cfg, data)
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	return file
+}
+
+func encode(t *testing.T, file *wav.WAVEFileFormat) []byte {
+	t.Helper()
+
+	output, err := os.Create(filepath.Join(t.TempDir(), "test.flac"))
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+	defer output.Close()
+
+	if err := flac.Encode(output, file); err != nil {
+		t.Fatalf("encoding: %s", err.Error())
+	}
+
+	stream, err := os.ReadFile(output.Name())
+	if err != nil {
+		t.Fatalf("reading file: %s", err.Error())
+	}
+
+	return stream
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    wav.Config
+		frames int
+	}{
+		{"8-bit mono", wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatPCM}, 1000},
+		{"16-bit stereo", wav.Config{Channels: 2, SampleRate: 44100, BitDepth: 16, Format: wav.FormatPCM}, 3*flac.BlockSize + 123},
+		{"24-bit stereo", wav.Config{Channels: 2, SampleRate: 96000, BitDepth: 24, Format: wav.FormatPCM}, flac.BlockSize},
+		{"16-bit 6 channels", wav.Config{Channels: 6, SampleRate: 48000, BitDepth: 16, Format: wav.FormatPCM, ChannelMask: 0x3F}, 5000},
+		{"odd sample rate", wav.Config{Channels: 1, SampleRate: 12345, BitDepth: 16, Format: wav.FormatPCM}, 2000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := testSignal(t, test.cfg, test.frames)
+			stream := encode(t, file)
+
+			if len(stream) >= len(file.DataChunk.Data) {
+				t.Errorf("size: got %d bytes, want less than %d", len(stream), len(file.DataChunk.Data))
+			}
+
+			decoder, err := flac.NewDecoder(bytes.NewReader(stream))
+			if err != nil {
+				t.Fatalf("creating decoder: %s", err.Error())
+			}
+
+			if decoder.TotalSamples != uint64(test.frames) {
+				t.Errorf("total samples: got %d, want %d", decoder.TotalSamples, test.frames)
+			}
+
+			if decoder.Config() != test.cfg {
+				t.Errorf("config: got %+v, want %+v", decoder.Config(), test.cfg)
+			}
+
+			var data bytes.Buffer
+
+			// Reading the whole stream verifies the MD5
+			if _, err := data.ReadFrom(decoder); err != nil {
+				t.Fatalf("decoding: %s", err.Error())
+			}
+
+			if !bytes.Equal(data.Bytes(), file.DataChunk.Data) {
+				t.Errorf("audio data does not match")
+			}
+		})
+	}
+}
+
+func TestEncodeSilence(t *testing.T) {
+	cfg := wav.Config{Channels: 2, SampleRate: 44100, BitDepth: 16, Format: wav.FormatPCM}
+
+	file, err := wav.New(cfg, make([]byte, 4*flac.BlockSize))
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	decoded, err := flac.Decode(bytes.NewReader(encode(t, file)))
+	if err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	if !bytes.Equal(decoded.DataChunk.Data, file.DataChunk.Data) {
+		t.Errorf("audio data does not match")
+	}
+}
+
+func TestEncodeWastedBits(t *testing.T) {
+	file := testSignal(t, wav.Config{Channels: 2, SampleRate: 48000, BitDepth: 24, Format: wav.FormatPCM}, 1000)
+
+	// Clears the lowest byte of the samples, as for 16 bits padded to 24
+	for i := 0; i < len(file.DataChunk.Data); i += 3 {
+		file.DataChunk.Data[i] = 0
+	}
+
+	stream := encode(t, file)
+
+	decoded, err := flac.Decode(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	if !bytes.Equal(decoded.DataChunk.Data, file.DataChunk.Data) {
+		t.Errorf("audio data does not match")
+	}
+
+	if len(stream) >= len(file.DataChunk.Data)*2/3 {
+		t.Errorf("size: got %d bytes, want less than %d", len(stream), len(file.DataChunk.Data)*2/3)
+	}
+}
+
+func TestEncodeInfo(t *testing.T) {
+	file := testSignal(t, wav.Config{Channels: 2, SampleRate: 44100, BitDepth: 16, Format: wav.FormatPCM}, 100)
+
+	info := &wav.InfoChunk{}
+	info.Set(wav.InfoTitle, "tone")
+	info.Set(wav.InfoArtist, "someone")
+
+	if err := file.SetInfo(info); err != nil {
+		t.Fatalf("setting info: %s", err.Error())
+	}
+
+	stream := encode(t, file)
+
+	decoder, err := flac.NewDecoder(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("creating decoder: %s", err.Error())
+	}
+
+	if title, _ := decoder.VorbisComment.Get("TITLE"); title != "tone" {
+		t.Errorf("title: got %q, want %q", title, "tone")
+	}
+
+	decoded, err := flac.Decode(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	decodedInfo, err := decoded.Info()
+	if err != nil {
+		t.Fatalf("info: %s", err.Error())
+	}
+
+	if artist, _ := decodedInfo.Get(wav.InfoArtist); artist != "someone" {
+		t.Errorf("artist: got %q, want %q", artist, "someone")
+	}
+}
+
+func TestEncodeStream(t *testing.T) {
+	file := testSignal(t, wav.Config{Channels: 2, SampleRate: 44100, BitDepth: 16, Format: wav.FormatPCM}, 2*flac.BlockSize+7)
+
+	var input bytes.Buffer
+
+	if err := file.Encode(&input); err != nil {
+		t.Fatalf("encoding wav: %s", err.Error())
+	}
+
+	output, err := os.Create(filepath.Join(t.TempDir(), "stream.flac"))
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+	defer output.Close()
+
+	if err := flac.EncodeStream(output, &input); err != nil {
+		t.Fatalf("encoding: %s", err.Error())
+	}
+
+	if _, err := output.Seek(0, 0); err != nil {
+		t.Fatalf("seeking: %s", err.Error())
+	}
+
+	decoded, err := flac.Decode(output)
+	if err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	if !bytes.Equal(decoded.DataChunk.Data, file.DataChunk.Data) {
+		t.Errorf("audio data does not match")
+	}
+}
+
+func TestEncodeFormat(t *testing.T) {
+	file, err := wav.New(wav.Config{Channels: 1, SampleRate: 44100, BitDepth: 32, FloatingPoint: true, Format: wav.FormatIEEEFloat}, make([]byte, 16))
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	output, err := os.Create(filepath.Join(t.TempDir(), "float.flac"))
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+	defer output.Close()
+
+	if err := flac.Encode(output, file); err == nil {
+		t.Errorf("encoding floating point: got no error")
+	}
+}
@@ -0,0 +1,350 @@
+// Package flac decodes FLAC streams into WAV audio data and encodes WAV audio
+// data into FLAC streams without cgo. The decoded audio data is read with the
+// same SampleReader as WAV files, or converted into a WAV file with the
+// Vorbis comments as LIST INFO fields.
+//
+// https://www.rfc-editor.org/rfc/rfc9639
+package flac
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/samborkent/wav"
+)
+
+// Metadata block types
+const (
+	BlockStreamInfo    = 0
+	BlockPadding       = 1
+	BlockApplication   = 2
+	BlockSeekTable     = 3
+	BlockVorbisComment = 4
+	BlockCueSheet      = 5
+	BlockPicture       = 6
+)
+
+const StreamInfoSize = 34
+
+var (
+	ErrDecodeMarker        = errors.New("stream marker does not match 'fLaC'")
+	ErrDecodeStreamInfo    = errors.New("stream info block is missing or malformed")
+	ErrDecodeVorbisComment = errors.New("vorbis comment block is malformed")
+	ErrDecodeFrame         = errors.New("frame is malformed")
+	ErrCRC                 = errors.New("frame crc does not match")
+	ErrMD5                 = errors.New("audio data md5 does not match stream info")
+	ErrTotalSamples        = errors.New("number of frames does not match stream info")
+)
+
+// StreamInfo is the stream info metadata block.
+type StreamInfo struct {
+	MinBlockSize  uint16
+	MaxBlockSize  uint16
+	MinFrameSize  uint32 // Zero if unknown
+	MaxFrameSize  uint32 // Zero if unknown
+	SampleRate    uint32
+	Channels      int
+	BitsPerSample int
+	TotalSamples  uint64   // Frames per channel, zero if unknown
+	MD5           [16]byte // MD5 of the decoded samples, zero if unknown
+}
+
+// VorbisComment is the Vorbis comment metadata block.
+type VorbisComment struct {
+	Vendor   string
+	Comments []string // Fields of the form NAME=value
+}
+
+// Decoder decodes a FLAC stream. It reads the audio data in the WAV layout,
+// i.e. little endian samples in whole bytes, left-aligned and unsigned for
+// 8 bits.
+type Decoder struct {
+	StreamInfo
+	VorbisComment *VorbisComment // Optional
+
+	reader  *bitReader
+	md5     hash.Hash
+	frames  uint64
+	samples [][]int64 // Decoded samples of each channel of the current frame
+	buffer  []byte    // Audio data of the current frame not read yet
+	err     error
+}
+
+// NewDecoder returns a Decoder that has read the metadata blocks of the
+// stream. Metadata blocks other than stream info and Vorbis comment are
+// skipped.
+func NewDecoder(reader io.Reader) (*Decoder, error) {
+	buffered := bufio.NewReader(reader)
+
+	var marker [4]byte
+
+	if _, err := io.ReadFull(buffered, marker[:]); err != nil {
+		return nil, fmt.Errorf("reading stream marker: %w", err)
+	}
+
+	if marker != [4]byte{'f', 'L', 'a', 'C'} {
+		return nil, ErrDecodeMarker
+	}
+
+	d := &Decoder{
+		reader: &bitReader{reader: buffered},
+		md5:    md5.New(),
+	}
+
+	for first, last := true, false; !last; first = false {
+		var header [4]byte
+
+		if _, err := io.ReadFull(buffered, header[:]); err != nil {
+			return nil, fmt.Errorf("reading metadata block header: %w", err)
+		}
+
+		last = header[0]&0x80 != 0
+		blockType := header[0] & 0x7F
+		data := make([]byte, int(header[1])<<16|int(header[2])<<8|int(header[3]))
+
+		if _, err := io.ReadFull(buffered, data); err != nil {
+			return nil, fmt.Errorf("reading metadata block %d: %w", blockType, err)
+		}
+
+		if first != (blockType == BlockStreamInfo) {
+			return nil, ErrDecodeStreamInfo
+		}
+
+		switch blockType {
+		case BlockStreamInfo:
+			if err := d.StreamInfo.decode(data); err != nil {
+				return nil, err
+			}
+		case BlockVorbisComment:
+			d.VorbisComment = &VorbisComment{}
+
+			if err := d.VorbisComment.decode(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return d, nil
+}
+
+func (s *StreamInfo) decode(data []byte) error {
+	if len(data) != StreamInfoSize {
+		return ErrDecodeStreamInfo
+	}
+
+	// Sample rate, channels, bits per sample and total samples are packed
+	// into 20, 3, 5 and 36 bits
+	packed := binary.BigEndian.Uint64(data[10:18])
+
+	*s = StreamInfo{
+		MinBlockSize:  binary.BigEndian.Uint16(data[0:2]),
+		MaxBlockSize:  binary.BigEndian.Uint16(data[2:4]),
+		MinFrameSize:  uint32(data[4])<<16 | uint32(data[5])<<8 | uint32(data[6]),
+		MaxFrameSize:  uint32(data[7])<<16 | uint32(data[8])<<8 | uint32(data[9]),
+		SampleRate:    uint32(packed >> 44),
+		Channels:      int(packed>>41&0x07) + 1,
+		BitsPerSample: int(packed>>36&0x1F) + 1,
+		TotalSamples:  packed & (1<<36 - 1),
+		MD5:           [16]byte(data[18:34]),
+	}
+
+	if s.SampleRate == 0 || s.BitsPerSample < 4 || s.MaxBlockSize < 16 {
+		return ErrDecodeStreamInfo
+	}
+
+	return nil
+}
+
+func (c *VorbisComment) decode(data []byte) error {
+	// Lengths and counts are little endian
+	next := func() (string, bool) {
+		if len(data) < 4 {
+			return "", false
+		}
+
+		length := binary.LittleEndian.Uint32(data[0:4])
+		if uint64(length) > uint64(len(data)-4) {
+			return "", false
+		}
+
+		value := string(data[4 : 4+length])
+		data = data[4+length:]
+
+		return value, true
+	}
+
+	vendor, ok := next()
+	if !ok || len(data) < 4 {
+		return ErrDecodeVorbisComment
+	}
+
+	c.Vendor = vendor
+	count := binary.LittleEndian.Uint32(data[0:4])
+	data = data[4:]
+
+	for range count {
+		comment, ok := next()
+		if !ok {
+			return ErrDecodeVorbisComment
+		}
+
+		c.Comments = append(c.Comments, comment)
+	}
+
+	return nil
+}
+
+// Get returns the value of the first field with the name, which is case
+// insensitive.
+func (c *VorbisComment) Get(name string) (string, bool) {
+	for _, comment := range c.Comments {
+		key, value, ok := strings.Cut(comment, "=")
+		if ok && strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// Config returns the configuration of the decoded audio data, where bit
+// depths are rounded up to whole bytes. Streams of more than two channels
+// have the channel mask of the FLAC channel order, unless the Vorbis comment
+// holds another one.
+func (d *Decoder) Config() wav.Config {
+	cfg := wav.Config{
+		Channels:    d.Channels,
+		SampleRate:  int(d.SampleRate),
+		BitDepth:    (d.BitsPerSample + 7) / 8 * 8,
+		Format:      wav.FormatPCM,
+		ChannelMask: defaultChannelMask(d.Channels),
+	}
+
+	if d.VorbisComment != nil {
+		if mask, ok := d.VorbisComment.channelMask(); ok {
+			cfg.ChannelMask = mask
+		}
+	}
+
+	return cfg
+}
+
+// channelMasks holds the speaker positions of the FLAC channel order.
+var channelMasks = [9]uint32{
+	3: wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerFrontCenter,
+	4: wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerBackLeft | wav.SpeakerBackRight,
+	5: wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerFrontCenter | wav.SpeakerBackLeft | wav.SpeakerBackRight,
+	6: wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerFrontCenter | wav.SpeakerLowFrequency | wav.SpeakerBackLeft | wav.SpeakerBackRight,
+	7: wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerFrontCenter | wav.SpeakerLowFrequency | wav.SpeakerBackCenter | wav.SpeakerSideLeft | wav.SpeakerSideRight,
+	8: wav.SpeakerFrontLeft | wav.SpeakerFrontRight | wav.SpeakerFrontCenter | wav.SpeakerLowFrequency | wav.SpeakerBackLeft | wav.SpeakerBackRight | wav.SpeakerSideLeft | wav.SpeakerSideRight,
+}
+
+// Read reads decoded audio data. At the end of the stream, the number of
+// frames and the MD5 of the samples are checked against the stream info.
+func (d *Decoder) Read(p []byte) (int, error) {
+	for len(d.buffer) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+
+		if d.err = d.decodeFrame(); d.err == io.EOF {
+			d.err = d.verify()
+		}
+	}
+
+	n := copy(p, d.buffer)
+	d.buffer = d.buffer[n:]
+
+	return n, nil
+}
+
+// verify checks the decoded stream against the stream info, returning
+// io.EOF if it matches.
+func (d *Decoder) verify() error {
+	if d.TotalSamples != 0 && d.frames != d.TotalSamples {
+		return fmt.Errorf("%w: decoded %d frames of %d", ErrTotalSamples, d.frames, d.TotalSamples)
+	}
+
+	if d.MD5 != [16]byte{} && [16]byte(d.md5.Sum(nil)) != d.MD5 {
+		return ErrMD5
+	}
+
+	return io.EOF
+}
+
+// SampleReader returns a SampleReader over the decoded audio data.
+func (d *Decoder) SampleReader() (*wav.SampleReader, error) {
+	format, err := wav.NewFormatChunk(d.Config())
+	if err != nil {
+		return nil, err
+	}
+
+	return wav.NewSampleReader(d, format)
+}
+
+// Info returns the Vorbis comments with a LIST INFO equivalent.
+func (d *Decoder) Info() *wav.InfoChunk {
+	info := &wav.InfoChunk{}
+
+	if d.VorbisComment == nil {
+		return info
+	}
+
+	for _, field := range commentFields {
+		if value, ok := d.VorbisComment.Get(field.name); ok {
+			info.Set(field.info, value)
+		}
+	}
+
+	return info
+}
+
+// commentFields maps Vorbis comment field names to LIST INFO fields.
+var commentFields = []struct {
+	name string
+	info [4]byte
+}{
+	{"TITLE", wav.InfoTitle},
+	{"ARTIST", wav.InfoArtist},
+	{"ALBUM", wav.InfoProduct},
+	{"TRACKNUMBER", wav.InfoTrackNumber},
+	{"GENRE", wav.InfoGenre},
+	{"COMMENT", wav.InfoComment},
+	{"COPYRIGHT", wav.InfoCopyright},
+	{"DATE", wav.InfoCreationDate},
+	{"ENCODER", wav.InfoSoftware},
+}
+
+// Decode decodes a FLAC stream into a WAV file, verifying its MD5.
+func Decode(reader io.Reader) (*wav.WAVEFileFormat, error) {
+	decoder, err := NewDecoder(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var data bytes.Buffer
+
+	if _, err := data.ReadFrom(decoder); err != nil {
+		return nil, err
+	}
+
+	file, err := wav.New(decoder.Config(), data.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	if info := decoder.Info(); len(info.Entries) > 0 {
+		if err := file.SetInfo(info); err != nil {
+			return nil, err
+		}
+	}
+
+	return file, nil
+}
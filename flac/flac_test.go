@@ -0,0 +1,294 @@
+package flac_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/flac"
+)
+
+// bitWriter writes big endian bit fields for hand-made test streams.
+type bitWriter struct {
+	data []byte
+	bits int
+}
+
+func (w *bitWriter) write(value uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.bits%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+
+		w.data[len(w.data)-1] |= byte(value>>i&1) << (7 - w.bits%8)
+		w.bits++
+	}
+}
+
+func (w *bitWriter) writeSigned(value int64, n int) {
+	w.write(uint64(value)&(1<<n-1), n)
+}
+
+// writeRice writes a zigzag encoded value with the Rice parameter.
+func (w *bitWriter) writeRice(value int64, parameter int) {
+	zigzag := uint64(value<<1 ^ value>>63)
+
+	for range zigzag >> parameter {
+		w.write(0, 1)
+	}
+
+	w.write(1, 1)
+	w.write(zigzag, parameter)
+}
+
+func (w *bitWriter) align() {
+	w.bits = len(w.data) * 8
+}
+
+func crc8(data []byte) uint8 {
+	var crc uint8
+
+	for _, b := range data {
+		crc ^= b
+
+		for range 8 {
+			crc = crc<<1 ^ uint8(0x07*(crc>>7))
+		}
+	}
+
+	return crc
+}
+
+func crc16(data []byte) uint16 {
+	var crc uint16
+
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+
+		for range 8 {
+			crc = crc<<1 ^ 0x8005*(crc>>15)
+		}
+	}
+
+	return crc
+}
+
+// frame writes a frame of 16-bit stereo samples with a block size of 16.
+func frame(number int, assignment int, subframes func(w *bitWriter)) []byte {
+	w := &bitWriter{}
+
+	w.write(0xFFF8, 16)
+	w.write(6, 4) // 8-bit block size
+	w.write(0, 4) // Sample rate from stream info
+	w.write(uint64(assignment), 4)
+	w.write(4, 3) // 16 bits per sample
+	w.write(0, 1)
+	w.write(uint64(number), 8)
+	w.write(15, 8)
+	w.write(uint64(crc8(w.data)), 8)
+
+	subframes(w)
+
+	w.align()
+	w.write(uint64(crc16(w.data)), 16)
+
+	return w.data
+}
+
+func testStream(t *testing.T, left, right []int64, comments []string) []byte {
+	t.Helper()
+
+	var stream bytes.Buffer
+
+	stream.WriteString("fLaC")
+
+	info := make([]byte, 0, flac.StreamInfoSize)
+	info = binary.BigEndian.AppendUint16(info, 16)
+	info = binary.BigEndian.AppendUint16(info, 16)
+	info = append(info, 0, 0, 0, 0, 0, 0)
+
+	// 44.1 kHz, 2 channels, 16 bits, 32 frames
+	info = binary.BigEndian.AppendUint64(info, 44100<<44|1<<41|15<<36|32)
+
+	checksum := md5.New()
+	for i := range left {
+		checksum.Write(binary.LittleEndian.AppendUint16(nil, uint16(left[i])))
+		checksum.Write(binary.LittleEndian.AppendUint16(nil, uint16(right[i])))
+	}
+
+	info = checksum.Sum(info)
+
+	stream.Write([]byte{flac.BlockStreamInfo, 0, 0, flac.StreamInfoSize})
+	stream.Write(info)
+
+	comment := binary.LittleEndian.AppendUint32(nil, 4)
+	comment = append(comment, "test"...)
+	comment = binary.LittleEndian.AppendUint32(comment, uint32(len(comments)))
+
+	for _, field := range comments {
+		comment = binary.LittleEndian.AppendUint32(comment, uint32(len(field)))
+		comment = append(comment, field...)
+	}
+
+	stream.Write([]byte{0x80 | flac.BlockVorbisComment, 0, 0, byte(len(comment))})
+	stream.Write(comment)
+
+	// Left and side channels, of which the side is constant
+	stream.Write(frame(0, 8, func(w *bitWriter) {
+		w.write(1<<1, 8)
+		for _, sample := range left[:16] {
+			w.writeSigned(sample, 16)
+		}
+
+		w.write(0, 8)
+		w.writeSigned(left[0]-right[0], 17)
+	}))
+
+	// Independent channels with fixed and LPC prediction
+	stream.Write(frame(1, 1, func(w *bitWriter) {
+		samples := left[16:]
+
+		w.write(10<<1, 8) // Fixed order 2
+		w.writeSigned(samples[0], 16)
+		w.writeSigned(samples[1], 16)
+		w.write(0, 2)
+		w.write(1, 4) // Two partitions
+
+		for partition := range 2 {
+			w.write(4, 4)
+
+			for i := max(2, partition*8); i < (partition+1)*8; i++ {
+				w.writeRice(samples[i]-2*samples[i-1]+samples[i-2], 4)
+			}
+		}
+
+		samples = right[16:]
+
+		w.write(32<<1, 8) // LPC order 1
+		w.writeSigned(samples[0], 16)
+		w.write(2, 4)       // Coefficient precision of 3 bits
+		w.writeSigned(1, 5) // Shift
+		w.writeSigned(3, 3) // Coefficient
+		w.write(0, 2)       // Rice parameters of 4 bits
+		w.write(0, 4)       // One partition
+		w.write(15, 4)      // Escape
+		w.write(17, 5)      // Unencoded residual bits
+
+		for i := 1; i < 16; i++ {
+			w.writeSigned(samples[i]-3*samples[i-1]>>1, 17)
+		}
+	}))
+
+	return stream.Bytes()
+}
+
+func testSamples() ([]int64, []int64) {
+	left := make([]int64, 32)
+	right := make([]int64, 32)
+
+	for i := range left {
+		left[i] = int64(i*i*37%4000 - 2000)
+		right[i] = left[i] - 3
+
+		if i >= 16 {
+			right[i] = int64(-i * 1000)
+		}
+	}
+
+	return left, right
+}
+
+func TestDecode(t *testing.T) {
+	left, right := testSamples()
+	stream := testStream(t, left, right, []string{"TITLE=tone", "artist=someone"})
+
+	file, err := flac.Decode(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	if want := (wav.Config{Channels: 2, SampleRate: 44100, BitDepth: 16, Format: wav.FormatPCM}); file.Config() != want {
+		t.Errorf("config: got %+v, want %+v", file.Config(), want)
+	}
+
+	if file.Frames() != len(left) {
+		t.Fatalf("frames: got %d, want %d", file.Frames(), len(left))
+	}
+
+	for i := range left {
+		gotLeft := int64(int16(binary.LittleEndian.Uint16(file.DataChunk.Data[4*i:])))
+		gotRight := int64(int16(binary.LittleEndian.Uint16(file.DataChunk.Data[4*i+2:])))
+
+		if gotLeft != left[i] || gotRight != right[i] {
+			t.Errorf("frame %d: got %d %d, want %d %d", i, gotLeft, gotRight, left[i], right[i])
+		}
+	}
+
+	info, err := file.Info()
+	if err != nil {
+		t.Fatalf("info: %s", err.Error())
+	}
+
+	if title, _ := info.Get(wav.InfoTitle); title != "tone" {
+		t.Errorf("title: got %q, want %q", title, "tone")
+	}
+
+	if artist, _ := info.Get(wav.InfoArtist); artist != "someone" {
+		t.Errorf("artist: got %q, want %q", artist, "someone")
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	left, right := testSamples()
+	stream := testStream(t, left, right, nil)
+
+	// Changes a verbatim sample of the first frame
+	corrupt := bytes.Clone(stream)
+	corrupt[len(corrupt)-60] ^= 0x01
+
+	decoder, err := flac.NewDecoder(bytes.NewReader(corrupt))
+	if err != nil {
+		t.Fatalf("creating decoder: %s", err.Error())
+	}
+
+	if _, err := io.ReadAll(decoder); !errors.Is(err, flac.ErrCRC) {
+		t.Errorf("corrupt frame: got %v, want %v", err, flac.ErrCRC)
+	}
+
+	// Changes the MD5 of the stream info
+	corrupt = bytes.Clone(stream)
+	corrupt[8+flac.StreamInfoSize-1] ^= 0x01
+
+	if _, err := flac.Decode(bytes.NewReader(corrupt)); !errors.Is(err, flac.ErrMD5) {
+		t.Errorf("corrupt md5: got %v, want %v", err, flac.ErrMD5)
+	}
+}
+
+func TestSampleReader(t *testing.T) {
+	left, right := testSamples()
+
+	decoder, err := flac.NewDecoder(bytes.NewReader(testStream(t, left, right, nil)))
+	if err != nil {
+		t.Fatalf("creating decoder: %s", err.Error())
+	}
+
+	reader, err := decoder.SampleReader()
+	if err != nil {
+		t.Fatalf("creating sample reader: %s", err.Error())
+	}
+
+	samples := make([]float64, 2*len(left))
+
+	frames, err := reader.ReadFrames(samples)
+	if err != nil {
+		t.Fatalf("reading frames: %s", err.Error())
+	}
+
+	if frames != len(left) || samples[0] != float64(left[0])/32768 {
+		t.Errorf("got %d frames starting with %f, want %d starting with %f", frames, samples[0], len(left), float64(left[0])/32768)
+	}
+}
@@ -0,0 +1,519 @@
+package flac
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Channel assignments of stereo decorrelation
+const (
+	channelsLeftSide  = 8
+	channelsSideRight = 9
+	channelsMidSide   = 10
+)
+
+// Sample rates of the frame header codes 1 to 11
+var sampleRates = [12]uint32{0, 88200, 176400, 192000, 8000, 16000, 22050, 24000, 32000, 44100, 48000, 96000}
+
+// Bits per sample of the frame header codes
+var sampleSizes = [8]int{0, 8, 12, 0, 16, 20, 24, 32}
+
+// frameHeader holds the fields of a frame header used for decoding.
+type frameHeader struct {
+	blockSize     int
+	channels      int
+	assignment    int
+	bitsPerSample int
+}
+
+// decodeFrame decodes the next frame into the buffer, returning io.EOF at
+// the end of the stream.
+func (d *Decoder) decodeFrame() error {
+	r := d.reader
+	r.resetCRC()
+
+	if err := r.fill(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.EOF
+		}
+
+		return fmt.Errorf("reading frame: %w", err)
+	}
+
+	// The first byte is already in the cache, so the header continues from
+	// the sync code
+	header, err := d.decodeFrameHeader()
+	if err != nil {
+		return err
+	}
+
+	if len(d.samples) != header.channels {
+		d.samples = make([][]int64, header.channels)
+	}
+
+	for channel := range d.samples {
+		bitsPerSample := header.bitsPerSample
+
+		// Side channels have an extra bit
+		switch {
+		case header.assignment == channelsLeftSide && channel == 1,
+			header.assignment == channelsSideRight && channel == 0,
+			header.assignment == channelsMidSide && channel == 1:
+			bitsPerSample++
+		}
+
+		d.samples[channel] = append(d.samples[channel][:0], make([]int64, header.blockSize)...)
+
+		if err := r.decodeSubframe(d.samples[channel], bitsPerSample); err != nil {
+			return fmt.Errorf("reading frame %d: subframe %d: %w", d.frames, channel, err)
+		}
+	}
+
+	r.align()
+
+	crc := r.crc16
+
+	footer, err := r.readBits(16)
+	if err != nil {
+		return fmt.Errorf("reading frame %d: footer: %w", d.frames, err)
+	}
+
+	if uint16(footer) != crc {
+		return fmt.Errorf("%w: frame %d", ErrCRC, d.frames)
+	}
+
+	d.decorrelate(header.assignment)
+	d.encodeFrame(header)
+	d.frames += uint64(header.blockSize)
+
+	return nil
+}
+
+func (d *Decoder) decodeFrameHeader() (frameHeader, error) {
+	r := d.reader
+
+	// Sync code, reserved bit and blocking strategy
+	sync, err := r.readBits(16)
+	if err != nil {
+		return frameHeader{}, fmt.Errorf("reading frame header: %w", err)
+	}
+
+	if sync&0xFFFE != 0xFFF8 {
+		return frameHeader{}, fmt.Errorf("%w: sync code 0x%04X after %d frames", ErrDecodeFrame, sync, d.frames)
+	}
+
+	fields, err := r.readBits(16)
+	if err != nil {
+		return frameHeader{}, fmt.Errorf("reading frame header: %w", err)
+	}
+
+	blockSizeCode := int(fields >> 12)
+	sampleRateCode := int(fields >> 8 & 0x0F)
+	assignment := int(fields >> 4 & 0x0F)
+	sampleSizeCode := int(fields >> 1 & 0x07)
+
+	if err := r.skipCodedNumber(); err != nil {
+		return frameHeader{}, err
+	}
+
+	header := frameHeader{
+		channels:      assignment + 1,
+		assignment:    assignment,
+		bitsPerSample: sampleSizes[sampleSizeCode],
+	}
+
+	switch {
+	case assignment > channelsMidSide:
+		return frameHeader{}, fmt.Errorf("%w: channel assignment %d", ErrDecodeFrame, assignment)
+	case assignment >= channelsLeftSide:
+		header.channels = 2
+	}
+
+	if header.channels != d.Channels {
+		return frameHeader{}, fmt.Errorf("%w: %d channels instead of %d", ErrDecodeFrame, header.channels, d.Channels)
+	}
+
+	switch sampleSizeCode {
+	case 0:
+		header.bitsPerSample = d.BitsPerSample
+	case 3:
+		return frameHeader{}, fmt.Errorf("%w: reserved sample size", ErrDecodeFrame)
+	}
+
+	if header.bitsPerSample != d.BitsPerSample {
+		return frameHeader{}, fmt.Errorf("%w: %d bits per sample instead of %d", ErrDecodeFrame, header.bitsPerSample, d.BitsPerSample)
+	}
+
+	switch {
+	case blockSizeCode == 0:
+		return frameHeader{}, fmt.Errorf("%w: reserved block size", ErrDecodeFrame)
+	case blockSizeCode == 1:
+		header.blockSize = 192
+	case blockSizeCode <= 5:
+		header.blockSize = 576 << (blockSizeCode - 2)
+	case blockSizeCode <= 7:
+		size, err := r.readBits(8 << (blockSizeCode - 6))
+		if err != nil {
+			return frameHeader{}, fmt.Errorf("reading frame header: block size: %w", err)
+		}
+
+		header.blockSize = int(size) + 1
+	default:
+		header.blockSize = 256 << (blockSizeCode - 8)
+	}
+
+	// The sample rate is only needed from the stream info, but must be read
+	switch sampleRateCode {
+	case 12:
+		_, err = r.readBits(8)
+	case 13, 14:
+		_, err = r.readBits(16)
+	case 15:
+		err = fmt.Errorf("%w: invalid sample rate", ErrDecodeFrame)
+	}
+
+	if err != nil {
+		return frameHeader{}, fmt.Errorf("reading frame header: sample rate: %w", err)
+	}
+
+	crc := r.crc8
+
+	value, err := r.readBits(8)
+	if err != nil {
+		return frameHeader{}, fmt.Errorf("reading frame header: crc: %w", err)
+	}
+
+	if uint8(value) != crc {
+		return frameHeader{}, fmt.Errorf("%w: header of frame after %d frames", ErrCRC, d.frames)
+	}
+
+	return header, nil
+}
+
+// skipCodedNumber skips the frame or sample number, which is coded like
+// UTF-8 in up to 7 bytes.
+func (r *bitReader) skipCodedNumber() error {
+	first, err := r.readBits(8)
+	if err != nil {
+		return fmt.Errorf("reading frame header: number: %w", err)
+	}
+
+	// Leading one bits give the number of bytes
+	length := 0
+	for mask := uint64(0x80); first&mask != 0 && length < 8; mask >>= 1 {
+		length++
+	}
+
+	if length == 1 || length > 7 {
+		return fmt.Errorf("%w: invalid coded number", ErrDecodeFrame)
+	}
+
+	for i := 1; i < length; i++ {
+		next, err := r.readBits(8)
+		if err != nil {
+			return fmt.Errorf("reading frame header: number: %w", err)
+		}
+
+		if next&0xC0 != 0x80 {
+			return fmt.Errorf("%w: invalid coded number", ErrDecodeFrame)
+		}
+	}
+
+	return nil
+}
+
+// decodeSubframe decodes the samples of one channel of a frame.
+func (r *bitReader) decodeSubframe(samples []int64, bitsPerSample int) error {
+	header, err := r.readBits(8)
+	if err != nil {
+		return err
+	}
+
+	if header&0x80 != 0 {
+		return fmt.Errorf("%w: subframe padding bit", ErrDecodeFrame)
+	}
+
+	subframeType := int(header >> 1 & 0x3F)
+
+	// Wasted bits are zero in all samples, so they are not stored
+	wasted := 0
+	if header&1 != 0 {
+		count, err := r.readUnary()
+		if err != nil {
+			return err
+		}
+
+		wasted = int(count) + 1
+	}
+
+	if wasted >= bitsPerSample {
+		return fmt.Errorf("%w: %d wasted bits of %d", ErrDecodeFrame, wasted, bitsPerSample)
+	}
+
+	bits := uint(bitsPerSample - wasted)
+
+	switch {
+	case subframeType == 0:
+		// Constant
+		value, err := r.readSigned(bits)
+		if err != nil {
+			return err
+		}
+
+		for i := range samples {
+			samples[i] = value
+		}
+	case subframeType == 1:
+		// Verbatim
+		for i := range samples {
+			if samples[i], err = r.readSigned(bits); err != nil {
+				return err
+			}
+		}
+	case subframeType >= 8 && subframeType <= 12:
+		if err := r.decodeFixed(samples, bits, subframeType-8); err != nil {
+			return err
+		}
+	case subframeType >= 32:
+		if err := r.decodeLPC(samples, bits, subframeType-31); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: reserved subframe type %d", ErrDecodeFrame, subframeType)
+	}
+
+	if wasted > 0 {
+		for i := range samples {
+			samples[i] <<= wasted
+		}
+	}
+
+	return nil
+}
+
+// Coefficients of the fixed predictors of order 0 to 4
+var fixedCoefficients = [5][]int64{
+	{},
+	{1},
+	{2, -1},
+	{3, -3, 1},
+	{4, -6, 4, -1},
+}
+
+func (r *bitReader) decodeFixed(samples []int64, bits uint, order int) error {
+	if order > len(samples) {
+		return fmt.Errorf("%w: predictor order %d exceeds block size", ErrDecodeFrame, order)
+	}
+
+	for i := range order {
+		value, err := r.readSigned(bits)
+		if err != nil {
+			return err
+		}
+
+		samples[i] = value
+	}
+
+	if err := r.decodeResidual(samples, order); err != nil {
+		return err
+	}
+
+	predict(samples, fixedCoefficients[order], 0)
+
+	return nil
+}
+
+func (r *bitReader) decodeLPC(samples []int64, bits uint, order int) error {
+	if order > len(samples) {
+		return fmt.Errorf("%w: predictor order %d exceeds block size", ErrDecodeFrame, order)
+	}
+
+	for i := range order {
+		value, err := r.readSigned(bits)
+		if err != nil {
+			return err
+		}
+
+		samples[i] = value
+	}
+
+	precision, err := r.readBits(4)
+	if err != nil {
+		return err
+	} else if precision == 0x0F {
+		return fmt.Errorf("%w: invalid coefficient precision", ErrDecodeFrame)
+	}
+
+	shift, err := r.readSigned(5)
+	if err != nil {
+		return err
+	} else if shift < 0 {
+		return fmt.Errorf("%w: negative coefficient shift", ErrDecodeFrame)
+	}
+
+	coefficients := make([]int64, order)
+
+	for i := range coefficients {
+		if coefficients[i], err = r.readSigned(uint(precision) + 1); err != nil {
+			return err
+		}
+	}
+
+	if err := r.decodeResidual(samples, order); err != nil {
+		return err
+	}
+
+	predict(samples, coefficients, int(shift))
+
+	return nil
+}
+
+// predict adds the prediction to the residuals following the warm-up
+// samples, where coefficients[0] applies to the previous sample.
+func predict(samples []int64, coefficients []int64, shift int) {
+	for i := len(coefficients); i < len(samples); i++ {
+		var prediction int64
+
+		for j, coefficient := range coefficients {
+			prediction += coefficient * samples[i-j-1]
+		}
+
+		samples[i] += prediction >> shift
+	}
+}
+
+// decodeResidual decodes the Rice coded residuals following the warm-up
+// samples.
+func (r *bitReader) decodeResidual(samples []int64, order int) error {
+	method, err := r.readBits(2)
+	if err != nil {
+		return err
+	}
+
+	parameterBits := uint(4)
+
+	switch method {
+	case 0:
+	case 1:
+		parameterBits = 5
+	default:
+		return fmt.Errorf("%w: reserved residual coding method", ErrDecodeFrame)
+	}
+
+	escape := uint64(1)<<parameterBits - 1
+
+	partitionOrder, err := r.readBits(4)
+	if err != nil {
+		return err
+	}
+
+	partitions := 1 << partitionOrder
+	partitionSize := len(samples) >> partitionOrder
+
+	if partitionSize<<partitionOrder != len(samples) || partitionSize < order {
+		return fmt.Errorf("%w: partition order %d does not fit the block size", ErrDecodeFrame, partitionOrder)
+	}
+
+	i := order
+
+	for partition := range partitions {
+		end := (partition + 1) * partitionSize
+
+		parameter, err := r.readBits(parameterBits)
+		if err != nil {
+			return err
+		}
+
+		if parameter == escape {
+			// Unencoded residuals of a fixed size
+			bits, err := r.readBits(5)
+			if err != nil {
+				return err
+			}
+
+			for ; i < end; i++ {
+				if samples[i], err = r.readSigned(uint(bits)); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		for ; i < end; i++ {
+			quotient, err := r.readUnary()
+			if err != nil {
+				return err
+			}
+
+			remainder, err := r.readBits(uint(parameter))
+			if err != nil {
+				return err
+			}
+
+			// Zigzag encoded signed value
+			value := quotient<<parameter | remainder
+			samples[i] = int64(value>>1) ^ -int64(value&1)
+		}
+	}
+
+	return nil
+}
+
+// decorrelate restores the left and right channels from the stereo
+// decorrelation channels.
+func (d *Decoder) decorrelate(assignment int) {
+	if assignment < channelsLeftSide {
+		return
+	}
+
+	first, second := d.samples[0], d.samples[1]
+
+	for i := range first {
+		switch assignment {
+		case channelsLeftSide:
+			second[i] = first[i] - second[i]
+		case channelsSideRight:
+			first[i] += second[i]
+		case channelsMidSide:
+			mid := first[i]<<1 | second[i]&1
+			first[i] = (mid + second[i]) >> 1
+			second[i] = (mid - second[i]) >> 1
+		}
+	}
+}
+
+// encodeFrame encodes the decoded samples as audio data in the WAV layout
+// and adds them to the MD5, which is computed over signed little endian
+// samples in whole bytes.
+func (d *Decoder) encodeFrame(header frameHeader) {
+	bytesPerSample := (d.BitsPerSample + 7) / 8
+	shift := bytesPerSample*8 - d.BitsPerSample
+	size := header.blockSize * header.channels * bytesPerSample
+
+	data := make([]byte, 0, size)
+	checksum := make([]byte, 0, size)
+
+	var sample [8]byte
+
+	for i := range header.blockSize {
+		for channel := range header.channels {
+			value := d.samples[channel][i]
+
+			binary.LittleEndian.PutUint64(sample[:], uint64(value))
+			checksum = append(checksum, sample[:bytesPerSample]...)
+
+			binary.LittleEndian.PutUint64(sample[:], uint64(value<<shift))
+			if bytesPerSample == 1 {
+				sample[0] ^= 0x80
+			}
+
+			data = append(data, sample[:bytesPerSample]...)
+		}
+	}
+
+	d.md5.Write(checksum)
+	d.buffer = data
+}
@@ -0,0 +1,588 @@
+package flac
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Encoder parameters
+const (
+	maxFixedOrder     = 4
+	maxLPCOrder       = 12
+	lpcPrecision      = 14 // Bits of the quantized LPC coefficients
+	maxPartitionOrder = 8
+)
+
+// Subframe types
+const (
+	subframeConstant = iota
+	subframeVerbatim
+	subframeFixed
+	subframeLPC
+)
+
+// subframe is the encoding chosen for the samples of one channel.
+type subframe struct {
+	kind         int
+	bits         int // Bits per sample without wasted bits
+	wasted       int
+	samples      []int64 // Samples without wasted bits
+	order        int
+	coefficients []int64
+	shift        int
+	residual     residual
+	size         int // Encoded size in bits
+}
+
+// residual holds the Rice coding of the residuals following the warm-up
+// samples.
+type residual struct {
+	values         []int64
+	partitionOrder int
+	parameters     []int
+	parameterBits  int
+	size           int // Encoded size in bits, including the partition headers
+}
+
+// bitWriter writes big endian bit fields.
+type bitWriter struct {
+	data  []byte
+	cache uint64
+	bits  uint // Bits in the lower end of cache, always below 8 between writes
+}
+
+// writeBits writes the lower n bits of value, with n up to 56.
+func (w *bitWriter) writeBits(value uint64, n uint) {
+	if n == 0 {
+		return
+	}
+
+	w.cache = w.cache<<n | value&(1<<n-1)
+	w.bits += n
+
+	for w.bits >= 8 {
+		w.bits -= 8
+		w.data = append(w.data, byte(w.cache>>w.bits))
+	}
+}
+
+func (w *bitWriter) writeSigned(value int64, n uint) {
+	w.writeBits(uint64(value), n)
+}
+
+// writeUnary writes n zero bits followed by a one bit.
+func (w *bitWriter) writeUnary(n uint64) {
+	for ; n >= 32; n -= 32 {
+		w.writeBits(0, 32)
+	}
+
+	w.writeBits(1, uint(n)+1)
+}
+
+// align pads with zero bits to the next byte boundary.
+func (w *bitWriter) align() {
+	if w.bits > 0 {
+		w.writeBits(0, 8-w.bits)
+	}
+}
+
+// encodeFrame encodes the buffered samples of all channels as frame.
+func (e *Encoder) encodeFrame(blockSize int) []byte {
+	w := &bitWriter{data: make([]byte, 0, blockSize*e.info.Channels*e.bytesPerSample/2)}
+	bitsPerSample := e.info.BitsPerSample
+
+	// Stereo decorrelation, choosing the smallest pair of channels
+	assignment := e.info.Channels - 1
+	subframes := make([]*subframe, e.info.Channels)
+
+	for channel, samples := range e.samples {
+		subframes[channel] = encodeSubframe(samples, bitsPerSample)
+	}
+
+	if e.info.Channels == 2 {
+		left, right := e.samples[0], e.samples[1]
+		mid := make([]int64, blockSize)
+		side := make([]int64, blockSize)
+
+		for i := range blockSize {
+			mid[i] = (left[i] + right[i]) >> 1
+			side[i] = left[i] - right[i]
+		}
+
+		midFrame := encodeSubframe(mid, bitsPerSample)
+		sideFrame := encodeSubframe(side, bitsPerSample+1)
+
+		best := subframes[0].size + subframes[1].size
+
+		if size := subframes[0].size + sideFrame.size; size < best {
+			best, assignment = size, channelsLeftSide
+		}
+
+		if size := sideFrame.size + subframes[1].size; size < best {
+			best, assignment = size, channelsSideRight
+		}
+
+		if size := midFrame.size + sideFrame.size; size < best {
+			assignment = channelsMidSide
+		}
+
+		switch assignment {
+		case channelsLeftSide:
+			subframes[1] = sideFrame
+		case channelsSideRight:
+			subframes[0] = sideFrame
+		case channelsMidSide:
+			subframes[0], subframes[1] = midFrame, sideFrame
+		}
+	}
+
+	e.writeFrameHeader(w, blockSize, assignment)
+
+	for _, sub := range subframes {
+		sub.write(w)
+	}
+
+	w.align()
+
+	var crc uint16
+	for _, b := range w.data {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^b]
+	}
+
+	w.writeBits(uint64(crc), 16)
+
+	return w.data
+}
+
+func (e *Encoder) writeFrameHeader(w *bitWriter, blockSize, assignment int) {
+	// Sync code with fixed block size
+	w.writeBits(0xFFF8, 16)
+
+	blockSizeCode := uint64(7)
+
+	switch {
+	case blockSize == BlockSize:
+		blockSizeCode = 12
+	case blockSize <= 256:
+		blockSizeCode = 6
+	}
+
+	w.writeBits(blockSizeCode, 4)
+
+	sampleRateCode := uint64(0)
+	for code, rate := range sampleRates {
+		if code > 0 && rate == e.info.SampleRate {
+			sampleRateCode = uint64(code)
+		}
+	}
+
+	w.writeBits(sampleRateCode, 4)
+	w.writeBits(uint64(assignment), 4)
+
+	sampleSizeCode := uint64(0)
+	for code, size := range sampleSizes {
+		if code > 0 && size == e.info.BitsPerSample {
+			sampleSizeCode = uint64(code)
+		}
+	}
+
+	w.writeBits(sampleSizeCode, 3)
+	w.writeBits(0, 1)
+
+	writeCodedNumber(w, e.frameNumber)
+
+	switch blockSizeCode {
+	case 6:
+		w.writeBits(uint64(blockSize-1), 8)
+	case 7:
+		w.writeBits(uint64(blockSize-1), 16)
+	}
+
+	var crc uint8
+	for _, b := range w.data {
+		crc = crc8Table[crc^b]
+	}
+
+	w.writeBits(uint64(crc), 8)
+}
+
+// writeCodedNumber writes the frame number coded like UTF-8.
+func writeCodedNumber(w *bitWriter, number uint64) {
+	if number < 0x80 {
+		w.writeBits(number, 8)
+		return
+	}
+
+	// Continuation bytes hold 6 bits each, the first byte the remainder
+	length := 2
+	for number >= 1<<(5*length+1) {
+		length++
+	}
+
+	w.writeBits(0xFF<<(8-length)&0xFF|number>>(6*(length-1)), 8)
+
+	for i := length - 2; i >= 0; i-- {
+		w.writeBits(0x80|number>>(6*i)&0x3F, 8)
+	}
+}
+
+// encodeSubframe returns the smallest encoding of the samples.
+func encodeSubframe(samples []int64, bitsPerSample int) *subframe {
+	// Bits that are zero in all samples are not stored
+	var combined int64
+	for _, sample := range samples {
+		combined |= sample
+	}
+
+	wasted := 0
+	if combined != 0 {
+		wasted = min(bits.TrailingZeros64(uint64(combined)), bitsPerSample-1)
+	}
+
+	shifted := samples
+	if wasted > 0 {
+		shifted = make([]int64, len(samples))
+		for i, sample := range samples {
+			shifted[i] = sample >> wasted
+		}
+	}
+
+	sub := &subframe{bits: bitsPerSample - wasted, wasted: wasted, samples: shifted}
+	header := 8 + wasted
+
+	if isConstant(shifted) {
+		sub.kind = subframeConstant
+		sub.size = header + sub.bits
+
+		return sub
+	}
+
+	sub.kind = subframeVerbatim
+	sub.size = header + sub.bits*len(samples)
+
+	for order := range min(maxFixedOrder, len(samples)-1) + 1 {
+		values := make([]int64, len(samples)-order)
+		for i := range values {
+			values[i] = shifted[i+order]
+			predict := shifted[i : i+order+1]
+
+			for j, coefficient := range fixedCoefficients[order] {
+				values[i] -= coefficient * predict[order-j-1]
+			}
+		}
+
+		if candidate, ok := riceCode(values, len(samples), order); ok {
+			if size := header + order*sub.bits + candidate.size; size < sub.size {
+				*sub = subframe{kind: subframeFixed, bits: sub.bits, wasted: wasted, samples: shifted, order: order, residual: candidate, size: size}
+			}
+		}
+	}
+
+	for _, candidate := range lpcCandidates(shifted) {
+		if size := header + candidate.order*sub.bits + candidate.size; size < sub.size {
+			candidate.bits, candidate.wasted, candidate.samples, candidate.size = sub.bits, wasted, shifted, size
+			*sub = candidate
+		}
+	}
+
+	return sub
+}
+
+func isConstant(samples []int64) bool {
+	for _, sample := range samples[1:] {
+		if sample != samples[0] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lpcCandidates returns LPC encodings of several orders, with the size
+// excluding the subframe header and warm-up samples.
+func lpcCandidates(samples []int64) []subframe {
+	maxOrder := min(maxLPCOrder, len(samples)-1)
+	if maxOrder < 1 {
+		return nil
+	}
+
+	// Autocorrelation of the samples with a Tukey window
+	windowed := make([]float64, len(samples))
+	taper := len(samples) / 4
+
+	for i, sample := range samples {
+		weight := 1.0
+		if i < taper {
+			weight = 0.5 - 0.5*math.Cos(math.Pi*float64(i)/float64(taper))
+		} else if i >= len(samples)-taper {
+			weight = 0.5 - 0.5*math.Cos(math.Pi*float64(len(samples)-1-i)/float64(taper))
+		}
+
+		windowed[i] = float64(sample) * weight
+	}
+
+	autocorrelation := make([]float64, maxOrder+1)
+	for lag := range autocorrelation {
+		for i := lag; i < len(windowed); i++ {
+			autocorrelation[lag] += windowed[i] * windowed[i-lag]
+		}
+	}
+
+	if autocorrelation[0] == 0 {
+		return nil
+	}
+
+	// Levinson-Durbin recursion, keeping the predictor of each order
+	predictors := make([][]float64, 0, maxOrder)
+	predictor := make([]float64, 0, maxOrder)
+	errorPower := autocorrelation[0]
+
+	for order := 1; order <= maxOrder; order++ {
+		reflection := autocorrelation[order]
+		for j, coefficient := range predictor {
+			reflection -= coefficient * autocorrelation[order-j-1]
+		}
+
+		reflection /= errorPower
+
+		next := make([]float64, order)
+		for j := range predictor {
+			next[j] = predictor[j] - reflection*predictor[order-j-2]
+		}
+
+		next[order-1] = reflection
+		predictor = next
+		predictors = append(predictors, next)
+
+		errorPower *= 1 - reflection*reflection
+		if errorPower <= 0 {
+			break
+		}
+	}
+
+	var candidates []subframe
+
+	for _, order := range []int{1, 2, 4, 8, 12} {
+		if order > len(predictors) {
+			break
+		}
+
+		coefficients, shift, ok := quantize(predictors[order-1])
+		if !ok {
+			continue
+		}
+
+		values := make([]int64, len(samples)-order)
+
+		for i := range values {
+			var prediction int64
+			for j, coefficient := range coefficients {
+				prediction += coefficient * samples[i+order-j-1]
+			}
+
+			values[i] = samples[i+order] - prediction>>shift
+		}
+
+		coded, ok := riceCode(values, len(samples), order)
+		if !ok {
+			continue
+		}
+
+		candidates = append(candidates, subframe{
+			kind:         subframeLPC,
+			order:        order,
+			coefficients: coefficients,
+			shift:        shift,
+			residual:     coded,
+			size:         4 + 5 + order*lpcPrecision + coded.size,
+		})
+	}
+
+	return candidates
+}
+
+// quantize converts the predictor to integer coefficients of lpcPrecision
+// bits scaled by 2^shift.
+func quantize(predictor []float64) ([]int64, int, bool) {
+	var largest float64
+	for _, coefficient := range predictor {
+		largest = max(largest, math.Abs(coefficient))
+	}
+
+	if largest == 0 || math.IsNaN(largest) || math.IsInf(largest, 0) {
+		return nil, 0, false
+	}
+
+	_, exponent := math.Frexp(largest)
+
+	// Shifts are stored in 5 signed bits and must not be negative
+	shift := min(lpcPrecision-1-exponent, 15)
+	if shift < 0 {
+		return nil, 0, false
+	}
+
+	limit := float64(int64(1)<<(lpcPrecision-1) - 1)
+	coefficients := make([]int64, len(predictor))
+
+	// Rounding errors are carried over to the next coefficient
+	var carry float64
+
+	for i, coefficient := range predictor {
+		scaled := coefficient*float64(int64(1)<<shift) + carry
+		rounded := min(max(math.Round(scaled), -limit-1), limit)
+		carry = scaled - rounded
+		coefficients[i] = int64(rounded)
+	}
+
+	return coefficients, shift, true
+}
+
+// riceCode chooses the partition order and Rice parameters of the residuals.
+// Residuals beyond 32 bits cannot be coded.
+func riceCode(values []int64, blockSize, order int) (residual, bool) {
+	// Sums of the zigzag encoded residuals at the finest partition order
+	finest := 0
+	for finest < maxPartitionOrder && blockSize%(2<<finest) == 0 && blockSize>>(finest+1) > order {
+		finest++
+	}
+
+	sums := make([]uint64, 1<<finest)
+	partitionSize := blockSize >> finest
+
+	for i, value := range values {
+		if value > math.MaxInt32 || value < math.MinInt32 {
+			return residual{}, false
+		}
+
+		sums[(i+order)/partitionSize] += uint64(value<<1 ^ value>>63)
+	}
+
+	best := residual{values: values, size: math.MaxInt}
+
+	for partitionOrder := finest; partitionOrder >= 0; partitionOrder-- {
+		partitions := 1 << partitionOrder
+		size := blockSize >> partitionOrder
+		parameters := make([]int, partitions)
+		total := 2 + 4
+
+		for partition := range partitions {
+			count := size
+			if partition == 0 {
+				count -= order
+			}
+
+			parameter, bits := riceParameter(sums[partition], count)
+			parameters[partition] = parameter
+			total += bits
+		}
+
+		parameterBits := 4
+		for _, parameter := range parameters {
+			if parameter >= 15 {
+				parameterBits = 5
+			}
+		}
+
+		total += partitions * parameterBits
+
+		if total < best.size {
+			best = residual{values: values, partitionOrder: partitionOrder, parameters: parameters, parameterBits: parameterBits, size: total}
+		}
+
+		// Merge pairs of partitions for the next order
+		for i := range partitions / 2 {
+			sums[i] = sums[2*i] + sums[2*i+1]
+		}
+	}
+
+	return best, true
+}
+
+// riceParameter returns the Rice parameter minimizing the estimated size of
+// count values with the sum, and that size in bits.
+func riceParameter(sum uint64, count int) (int, int) {
+	bestParameter, bestSize := 0, math.MaxInt
+
+	for parameter := range 31 {
+		size := count*(parameter+1) + int(sum>>parameter)
+		if size < bestSize {
+			bestParameter, bestSize = parameter, size
+		}
+	}
+
+	return bestParameter, bestSize
+}
+
+func (s *subframe) write(w *bitWriter) {
+	types := [4]uint64{subframeConstant: 0, subframeVerbatim: 1, subframeFixed: 8, subframeLPC: 32}
+	subframeType := types[s.kind]
+
+	switch s.kind {
+	case subframeFixed:
+		subframeType += uint64(s.order)
+	case subframeLPC:
+		subframeType += uint64(s.order - 1)
+	}
+
+	// Zero padding bit followed by the type
+	w.writeBits(subframeType, 7)
+
+	if s.wasted > 0 {
+		w.writeBits(1, 1)
+		w.writeUnary(uint64(s.wasted - 1))
+	} else {
+		w.writeBits(0, 1)
+	}
+
+	bits := uint(s.bits)
+
+	switch s.kind {
+	case subframeConstant:
+		w.writeSigned(s.samples[0], bits)
+	case subframeVerbatim:
+		for _, sample := range s.samples {
+			w.writeSigned(sample, bits)
+		}
+	case subframeFixed, subframeLPC:
+		for _, sample := range s.samples[:s.order] {
+			w.writeSigned(sample, bits)
+		}
+
+		if s.kind == subframeLPC {
+			w.writeBits(lpcPrecision-1, 4)
+			w.writeSigned(int64(s.shift), 5)
+
+			for _, coefficient := range s.coefficients {
+				w.writeSigned(coefficient, lpcPrecision)
+			}
+		}
+
+		s.residual.write(w, len(s.samples), s.order)
+	}
+}
+
+func (r *residual) write(w *bitWriter, blockSize, order int) {
+	w.writeBits(uint64(r.parameterBits-4), 2)
+	w.writeBits(uint64(r.partitionOrder), 4)
+
+	size := blockSize >> r.partitionOrder
+	values := r.values
+
+	for partition, parameter := range r.parameters {
+		count := size
+		if partition == 0 {
+			count -= order
+		}
+
+		w.writeBits(uint64(parameter), uint(r.parameterBits))
+
+		for _, value := range values[:count] {
+			zigzag := uint64(value<<1 ^ value>>63)
+
+			w.writeUnary(zigzag >> parameter)
+			w.writeBits(zigzag, uint(parameter))
+		}
+
+		values = values[count:]
+	}
+}
@@ -0,0 +1,369 @@
+// Package generator produces test signals, such as sine sweeps, noise,
+// impulses and channel identification tones, as streams of samples that can
+// be written to WAV files in any supported sample format.
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/samborkent/wav"
+)
+
+var (
+	ErrChannels   = errors.New("number of channels must be positive")
+	ErrSampleRate = errors.New("sample rate must be positive")
+	ErrDuration   = errors.New("duration must be positive")
+	ErrAmplitude  = errors.New("amplitude must be between 0 and 1")
+	ErrFrequency  = errors.New("frequency must be positive and below the Nyquist frequency")
+	ErrMismatch   = errors.New("output configuration does not match signal")
+)
+
+const (
+	fadeDuration = 5 * time.Millisecond // Fades of identification tones
+	blockFrames  = 4096                 // Frames read from a signal at once
+)
+
+// Config configures a signal.
+type Config struct {
+	Channels   int
+	SampleRate int
+	Duration   time.Duration // Total duration, or per channel for identification tones
+
+	// Amplitude is the linear peak amplitude between 0 and 1.
+	Amplitude float64
+
+	// Frequency in Hz of tones, the start frequency of sweeps and the rate
+	// of repeated impulses.
+	Frequency float64
+
+	// EndFrequency in Hz of sweeps.
+	EndFrequency float64
+
+	// Seed of the random number generator of noise signals.
+	Seed uint64
+}
+
+// Signal is a stream of interleaved sample frames of a generated signal.
+type Signal struct {
+	channels   int
+	sampleRate int
+	frames     int
+	frame      int
+
+	// next fills the samples of one frame, which are zero on entry
+	next func(frame int, samples []float64)
+}
+
+// Channels returns the number of channels.
+func (s *Signal) Channels() int {
+	return s.channels
+}
+
+// SampleRate returns the sample rate in Hz.
+func (s *Signal) SampleRate() int {
+	return s.sampleRate
+}
+
+// Frames returns the total number of frames of the signal.
+func (s *Signal) Frames() int {
+	return s.frames
+}
+
+// ReadFrames fills samples with as many whole frames as fit, as with
+// wav.SampleReader, and returns io.EOF after the last frame.
+func (s *Signal) ReadFrames(samples []float64) (int, error) {
+	frames := min(len(samples)/s.channels, s.frames-s.frame)
+
+	if len(samples) < s.channels {
+		return 0, io.ErrShortBuffer
+	} else if frames == 0 {
+		return 0, io.EOF
+	}
+
+	clear(samples[:frames*s.channels])
+
+	for i := range frames {
+		s.next(s.frame+i, samples[i*s.channels:(i+1)*s.channels])
+	}
+
+	s.frame += frames
+
+	return frames, nil
+}
+
+// Sine returns a sine tone at the configured frequency in all channels.
+func Sine(cfg Config) (*Signal, error) {
+	if err := cfg.validate(true); err != nil {
+		return nil, err
+	}
+
+	step := 2 * math.Pi * cfg.Frequency / float64(cfg.SampleRate)
+
+	return cfg.signal(cfg.frames(), func(frame int, samples []float64) {
+		fill(samples, cfg.Amplitude*math.Sin(step*float64(frame)))
+	}), nil
+}
+
+// Sweep returns a sine sweep from the frequency to the end frequency in all
+// channels. Logarithmic sweeps spend equal time on each octave, linear
+// sweeps on each frequency band of equal width.
+func Sweep(cfg Config, logarithmic bool) (*Signal, error) {
+	if err := cfg.validate(true); err != nil {
+		return nil, err
+	}
+
+	if cfg.EndFrequency <= 0 || cfg.EndFrequency >= float64(cfg.SampleRate)/2 {
+		return nil, fmt.Errorf("%w: end frequency %g Hz", ErrFrequency, cfg.EndFrequency)
+	}
+
+	duration := cfg.Duration.Seconds()
+	rate := float64(cfg.SampleRate)
+	start, end := cfg.Frequency, cfg.EndFrequency
+
+	phase := func(t float64) float64 {
+		return 2 * math.Pi * (start*t + (end-start)*t*t/(2*duration))
+	}
+
+	if logarithmic && start != end {
+		ratio := math.Log(end / start)
+
+		phase = func(t float64) float64 {
+			return 2 * math.Pi * start * duration / ratio * (math.Exp(t/duration*ratio) - 1)
+		}
+	}
+
+	return cfg.signal(cfg.frames(), func(frame int, samples []float64) {
+		fill(samples, cfg.Amplitude*math.Sin(phase(float64(frame)/rate)))
+	}), nil
+}
+
+// WhiteNoise returns uniformly distributed white noise, uncorrelated
+// between channels.
+func WhiteNoise(cfg Config) (*Signal, error) {
+	if err := cfg.validate(false); err != nil {
+		return nil, err
+	}
+
+	random := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+
+	return cfg.signal(cfg.frames(), func(frame int, samples []float64) {
+		for channel := range samples {
+			samples[channel] = cfg.Amplitude * (2*random.Float64() - 1)
+		}
+	}), nil
+}
+
+// PinkNoise returns noise with equal power per octave, uncorrelated between
+// channels. White noise is filtered with Paul Kellet's economy filter,
+// accurate to within 0.5 dB above 40 Hz at 44.1 kHz.
+func PinkNoise(cfg Config) (*Signal, error) {
+	if err := cfg.validate(false); err != nil {
+		return nil, err
+	}
+
+	random := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+	state := make([][3]float64, cfg.Channels)
+
+	return cfg.signal(cfg.frames(), func(frame int, samples []float64) {
+		for channel := range samples {
+			white := 2*random.Float64() - 1
+			b := &state[channel]
+
+			b[0] = 0.99765*b[0] + white*0.0990460
+			b[1] = 0.96300*b[1] + white*0.2965164
+			b[2] = 0.57000*b[2] + white*1.0526913
+
+			// The filter gain peaks at about 4.5, bounding the output
+			pink := (b[0] + b[1] + b[2] + white*0.1848) / 4.5
+
+			samples[channel] = cfg.Amplitude * max(-1, min(1, pink))
+		}
+	}), nil
+}
+
+// Impulse returns a unit impulse in all channels at the start, repeated at
+// the configured frequency if it is positive.
+func Impulse(cfg Config) (*Signal, error) {
+	if err := cfg.validate(false); err != nil {
+		return nil, err
+	}
+
+	period := math.Inf(1)
+
+	if cfg.Frequency > 0 {
+		if cfg.Frequency >= float64(cfg.SampleRate) {
+			return nil, fmt.Errorf("%w: impulse rate %g Hz", ErrFrequency, cfg.Frequency)
+		}
+
+		period = float64(cfg.SampleRate) / cfg.Frequency
+	}
+
+	next := 0.0
+
+	return cfg.signal(cfg.frames(), func(frame int, samples []float64) {
+		if float64(frame) >= math.Round(next) {
+			fill(samples, cfg.Amplitude)
+			next += period
+		}
+	}), nil
+}
+
+// ChannelID returns identification tones: each channel in turn sounds a
+// tone at the configured frequency for the configured duration, followed by
+// silence for half the duration while the other channels are silent.
+func ChannelID(cfg Config) (*Signal, error) {
+	if err := cfg.validate(true); err != nil {
+		return nil, err
+	}
+
+	tone := cfg.frames()
+	slot := tone + tone/2
+	fade := max(1, int(fadeDuration.Seconds()*float64(cfg.SampleRate)))
+	step := 2 * math.Pi * cfg.Frequency / float64(cfg.SampleRate)
+
+	return cfg.signal(slot*cfg.Channels, func(frame int, samples []float64) {
+		channel, position := frame/slot, frame%slot
+		if position >= tone {
+			return
+		}
+
+		// Raised cosine fades avoid clicks
+		gain := 1.0
+		if edge := min(position, tone-1-position); edge < fade {
+			gain = 0.5 - 0.5*math.Cos(math.Pi*float64(edge)/float64(fade))
+		}
+
+		samples[channel] = gain * cfg.Amplitude * math.Sin(step*float64(position))
+	}), nil
+}
+
+func (c *Config) validate(tone bool) error {
+	switch {
+	case c.Channels <= 0:
+		return ErrChannels
+	case c.SampleRate <= 0:
+		return ErrSampleRate
+	case c.Duration <= 0:
+		return ErrDuration
+	case c.Amplitude < 0 || c.Amplitude > 1 || math.IsNaN(c.Amplitude):
+		return fmt.Errorf("%w: %g", ErrAmplitude, c.Amplitude)
+	case tone && (c.Frequency <= 0 || c.Frequency >= float64(c.SampleRate)/2):
+		return fmt.Errorf("%w: %g Hz", ErrFrequency, c.Frequency)
+	}
+
+	return nil
+}
+
+func (c *Config) frames() int {
+	return int(math.Round(c.Duration.Seconds() * float64(c.SampleRate)))
+}
+
+func (c *Config) signal(frames int, next func(frame int, samples []float64)) *Signal {
+	return &Signal{
+		channels:   c.Channels,
+		sampleRate: c.SampleRate,
+		frames:     frames,
+		next:       next,
+	}
+}
+
+func fill(samples []float64, value float64) {
+	for channel := range samples {
+		samples[channel] = value
+	}
+}
+
+// File returns a WAV file, created with wav.New, holding the remaining
+// frames of the signal in the sample format of cfg. The channels and sample
+// rate of cfg may be left zero and are then taken from the signal.
+func File(signal *Signal, cfg wav.Config) (*wav.WAVEFileFormat, error) {
+	cfg, err := outputConfig(signal, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := wav.New(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := new(bytes.Buffer)
+
+	writer, err := wav.NewSampleWriter(data, header.FormatChunk)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := copyFrames(writer, signal); err != nil {
+		return nil, err
+	}
+
+	return wav.New(cfg, data.Bytes())
+}
+
+// Write streams the remaining frames of the signal to writer as a WAV file
+// in the sample format of cfg, as with File, using a wav.Writer.
+func Write(writer io.WriteSeeker, signal *Signal, cfg wav.Config) error {
+	cfg, err := outputConfig(signal, cfg)
+	if err != nil {
+		return err
+	}
+
+	header, err := wav.New(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	output, err := wav.NewWriter(writer, header)
+	if err != nil {
+		return err
+	}
+
+	if err := copyFrames(output, signal); err != nil {
+		return err
+	}
+
+	return output.Close()
+}
+
+// outputConfig fills the channels and sample rate of cfg from the signal.
+func outputConfig(signal *Signal, cfg wav.Config) (wav.Config, error) {
+	if cfg.Channels == 0 {
+		cfg.Channels = signal.channels
+	} else if cfg.Channels != signal.channels {
+		return cfg, fmt.Errorf("%w: %d channels for a signal of %d channels", ErrMismatch, cfg.Channels, signal.channels)
+	}
+
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = signal.sampleRate
+	} else if cfg.SampleRate != signal.sampleRate {
+		return cfg, fmt.Errorf("%w: %d Hz for a signal of %d Hz", ErrMismatch, cfg.SampleRate, signal.sampleRate)
+	}
+
+	return cfg, nil
+}
+
+func copyFrames(writer interface {
+	WriteFrames(samples []float64) (int, error)
+}, signal *Signal) error {
+	samples := make([]float64, blockFrames*signal.Channels())
+
+	for {
+		frames, err := signal.ReadFrames(samples)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if _, err := writer.WriteFrames(samples[:frames*signal.Channels()]); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package generator_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/samborkent/wav"
+	"github.com/samborkent/wav/generator"
+)
+
+func readAll(t *testing.T, signal *generator.Signal) []float64 {
+	t.Helper()
+
+	var samples []float64
+
+	buffer := make([]float64, 1000*signal.Channels())
+
+	for {
+		frames, err := signal.ReadFrames(buffer)
+		if errors.Is(err, io.EOF) {
+			return samples
+		} else if err != nil {
+			t.Fatalf("reading frames: %s", err.Error())
+		}
+
+		samples = append(samples, buffer[:frames*signal.Channels()]...)
+	}
+}
+
+func rms(samples []float64) float64 {
+	var sum float64
+	for _, sample := range samples {
+		sum += sample * sample
+	}
+
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func TestSignals(t *testing.T) {
+	cfg := generator.Config{
+		Channels:     2,
+		SampleRate:   48000,
+		Duration:     time.Second,
+		Amplitude:    0.5,
+		Frequency:    100,
+		EndFrequency: 10000,
+		Seed:         1,
+	}
+
+	sweep := func(cfg generator.Config) (*generator.Signal, error) { return generator.Sweep(cfg, true) }
+
+	tests := []struct {
+		name     string
+		generate func(generator.Config) (*generator.Signal, error)
+		rms      float64
+	}{
+		{"sine", generator.Sine, 0.5 / math.Sqrt2},
+		{"sweep", sweep, 0.5 / math.Sqrt2},
+		{"white noise", generator.WhiteNoise, 0.5 / math.Sqrt(3)},
+		{"impulse", generator.Impulse, 0.5 / math.Sqrt(480)},
+	}
+
+	for _, test := range tests {
+		signal, err := test.generate(cfg)
+		if err != nil {
+			t.Fatalf("%s: generating: %s", test.name, err.Error())
+		}
+
+		samples := readAll(t, signal)
+
+		if len(samples) != 2*48000 {
+			t.Errorf("%s: samples: got %d, want %d", test.name, len(samples), 2*48000)
+		}
+
+		if got := rms(samples); math.Abs(got-test.rms) > 0.01 {
+			t.Errorf("%s: rms: got %f, want %f", test.name, got, test.rms)
+		}
+
+		for i, sample := range samples {
+			if math.Abs(sample) > cfg.Amplitude {
+				t.Errorf("%s: sample %d: got %f, exceeds amplitude", test.name, i, sample)
+				break
+			}
+		}
+	}
+
+	if _, err := generator.Sine(generator.Config{Channels: 1, SampleRate: 8000, Duration: time.Second, Amplitude: 1, Frequency: 4000}); !errors.Is(err, generator.ErrFrequency) {
+		t.Errorf("sine at the Nyquist frequency: got %v, want %v", err, generator.ErrFrequency)
+	}
+}
+
+func TestChannelID(t *testing.T) {
+	signal, err := generator.ChannelID(generator.Config{
+		Channels:   3,
+		SampleRate: 8000,
+		Duration:   100 * time.Millisecond,
+		Amplitude:  1,
+		Frequency:  1000,
+	})
+	if err != nil {
+		t.Fatalf("generating: %s", err.Error())
+	}
+
+	samples := readAll(t, signal)
+
+	if len(samples) != 3*3*1200 {
+		t.Fatalf("samples: got %d, want %d", len(samples), 3*3*1200)
+	}
+
+	// Only the identified channel sounds in each slot
+	for slot := range 3 {
+		for channel := range 3 {
+			var energy float64
+
+			for frame := slot * 1200; frame < (slot+1)*1200; frame++ {
+				energy += math.Abs(samples[frame*3+channel])
+			}
+
+			if (energy > 0) != (channel == slot) {
+				t.Errorf("slot %d, channel %d: got energy %f", slot, channel, energy)
+			}
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	cfg := generator.Config{Channels: 1, SampleRate: 8000, Duration: 10 * time.Millisecond, Amplitude: 1, Seed: 2}
+
+	signal, err := generator.PinkNoise(cfg)
+	if err != nil {
+		t.Fatalf("generating: %s", err.Error())
+	}
+
+	file, err := generator.File(signal, wav.Config{BitDepth: 16})
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	if file.Frames() != 80 {
+		t.Errorf("frames: got %d, want 80", file.Frames())
+	}
+
+	if _, err := generator.File(signal, wav.Config{Channels: 2, BitDepth: 16}); !errors.Is(err, generator.ErrMismatch) {
+		t.Errorf("mismatching channels: got %v, want %v", err, generator.ErrMismatch)
+	}
+
+	// The same seed generates the same noise
+	signal, _ = generator.PinkNoise(cfg)
+
+	streamed := &seekBuffer{}
+
+	if err := generator.Write(streamed, signal, wav.Config{BitDepth: 16}); err != nil {
+		t.Fatalf("writing: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := file.Encode(encoded); err != nil {
+		t.Fatalf("encoding: %s", err.Error())
+	}
+
+	if !bytes.Equal(streamed.data, encoded.Bytes()) {
+		t.Errorf("streamed file does not match file created with New")
+	}
+}
+
+// seekBuffer is an in-memory io.WriteSeeker.
+type seekBuffer struct {
+	data     []byte
+	position int
+}
+
+func (b *seekBuffer) Write(p []byte) (int, error) {
+	if end := b.position + len(p); end > len(b.data) {
+		b.data = append(b.data, make([]byte, end-len(b.data))...)
+	}
+
+	n := copy(b.data[b.position:], p)
+	b.position += n
+
+	return n, nil
+}
+
+func (b *seekBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		b.position = int(offset)
+	case io.SeekCurrent:
+		b.position += int(offset)
+	case io.SeekEnd:
+		b.position = len(b.data) + int(offset)
+	}
+
+	return int64(b.position), nil
+}
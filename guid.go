@@ -0,0 +1,175 @@
+package wav
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var ErrParseGUID = errors.New("guid must be of the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx")
+
+// GUID is a globally unique identifier in the byte order of the format
+// sub-chunk, where the first three groups are little endian.
+type GUID [16]byte
+
+// ParseGUID parses a GUID of the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx,
+// optionally enclosed in braces.
+func ParseGUID(s string) (GUID, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+
+	groups := strings.Split(s, "-")
+	if len(groups) != 5 || len(groups[0]) != 8 || len(groups[1]) != 4 || len(groups[2]) != 4 || len(groups[3]) != 4 || len(groups[4]) != 12 {
+		return GUID{}, fmt.Errorf("%w: %q", ErrParseGUID, s)
+	}
+
+	data, err := hex.DecodeString(strings.Join(groups, ""))
+	if err != nil {
+		return GUID{}, fmt.Errorf("%w: %q", ErrParseGUID, s)
+	}
+
+	var guid GUID
+
+	binary.LittleEndian.PutUint32(guid[0:4], binary.BigEndian.Uint32(data[0:4]))
+	binary.LittleEndian.PutUint16(guid[4:6], binary.BigEndian.Uint16(data[4:6]))
+	binary.LittleEndian.PutUint16(guid[6:8], binary.BigEndian.Uint16(data[6:8]))
+	copy(guid[8:], data[8:])
+
+	return guid, nil
+}
+
+// MustParseGUID is like ParseGUID but panics if the GUID cannot be parsed.
+func MustParseGUID(s string) GUID {
+	guid, err := ParseGUID(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return guid
+}
+
+// String formats the GUID as XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX.
+func (g GUID) String() string {
+	return fmt.Sprintf("%08X-%04X-%04X-%X-%X",
+		binary.LittleEndian.Uint32(g[0:4]),
+		binary.LittleEndian.Uint16(g[4:6]),
+		binary.LittleEndian.Uint16(g[6:8]),
+		g[8:10],
+		g[10:16],
+	)
+}
+
+// FormatGUID returns the sub-format GUID of the extensible format for the
+// format, i.e. xxxxxxxx-0000-0010-8000-00AA00389B71.
+func FormatGUID(format uint16) GUID {
+	return GUID{byte(format), byte(format >> 8), 0, 0, 0, 0, 0x10, 0, 0x80, 0, 0, 0xAA, 0, 0x38, 0x9B, 0x71}
+}
+
+// Well-known sub-format GUIDs of the extensible format
+var (
+	SubFormatPCM       = FormatGUID(FormatPCM)
+	SubFormatADPCM     = FormatGUID(FormatADPCM)
+	SubFormatIEEEFloat = FormatGUID(FormatIEEEFloat)
+	SubFormatALaw      = FormatGUID(FormatALaw)
+	SubFormatMuLaw     = FormatGUID(FormatMuLaw)
+	SubFormatDRM       = FormatGUID(FormatDRM)
+	SubFormatMPEG      = FormatGUID(FormatMPEG)
+
+	// IEC 61937 compressed audio passed through S/PDIF or HDMI
+	SubFormatDolbyDigital     = FormatGUID(FormatDolbyAC3SPDIF)
+	SubFormatDolbyDigitalPlus = MustParseGUID("0000000A-0CEA-0010-8000-00AA00389B71")
+	SubFormatDolbyMLP         = MustParseGUID("0000000C-0CEA-0010-8000-00AA00389B71")
+	SubFormatDTS              = FormatGUID(FormatDTS)
+
+	// 00000001-0721-11D3-8644-C8C1CA000000
+	SubFormatAmbisonicPCM = GUID{0x01, 0x00, 0x00, 0x00, 0x21, 0x07, 0xD3, 0x11, 0x86, 0x44, 0xC8, 0xC1, 0xCA, 0x00, 0x00, 0x00}
+	// 00000003-0721-11D3-8644-C8C1CA000000
+	SubFormatAmbisonicFloat = GUID{0x03, 0x00, 0x00, 0x00, 0x21, 0x07, 0xD3, 0x11, 0x86, 0x44, 0xC8, 0xC1, 0xCA, 0x00, 0x00, 0x00}
+)
+
+// SubFormat describes a sub-format GUID of the extensible format.
+type SubFormat struct {
+	GUID   GUID
+	Name   string
+	Format uint16 // Format of the samples, FormatUnknown if there is none
+}
+
+var (
+	subFormatsMutex sync.RWMutex
+	subFormats      = map[GUID]SubFormat{}
+)
+
+func init() {
+	for _, subFormat := range []SubFormat{
+		{SubFormatPCM, "PCM", FormatPCM},
+		{SubFormatADPCM, "ADPCM", FormatADPCM},
+		{SubFormatIEEEFloat, "IEEE float", FormatIEEEFloat},
+		{SubFormatALaw, "A-law", FormatALaw},
+		{SubFormatMuLaw, "mu-law", FormatMuLaw},
+		{SubFormatDRM, "DRM", FormatDRM},
+		{SubFormatMPEG, "MPEG", FormatMPEG},
+		{SubFormatDolbyDigital, "Dolby Digital", FormatDolbyAC3SPDIF},
+		{SubFormatDolbyDigitalPlus, "Dolby Digital Plus", FormatUnknown},
+		{SubFormatDolbyMLP, "Dolby MLP", FormatUnknown},
+		{SubFormatDTS, "DTS", FormatDTS},
+		{SubFormatAmbisonicPCM, "ambisonic B-format PCM", FormatPCM},
+		{SubFormatAmbisonicFloat, "ambisonic B-format IEEE float", FormatIEEEFloat},
+	} {
+		subFormats[subFormat.GUID] = subFormat
+	}
+}
+
+// RegisterSubFormat registers a sub-format, replacing an existing one with
+// the same GUID. Extensible format sub-chunks with unregistered sub-formats,
+// other than those derived from a format, are rejected when decoding.
+func RegisterSubFormat(subFormat SubFormat) {
+	subFormatsMutex.Lock()
+	defer subFormatsMutex.Unlock()
+
+	subFormats[subFormat.GUID] = subFormat
+}
+
+// LookupSubFormat returns the registered sub-format of the GUID. Sub-formats
+// derived from a format, as returned by FormatGUID, are always found.
+func LookupSubFormat(guid GUID) (SubFormat, bool) {
+	subFormatsMutex.RLock()
+	subFormat, ok := subFormats[guid]
+	subFormatsMutex.RUnlock()
+
+	if ok {
+		return subFormat, true
+	}
+
+	if format := binary.LittleEndian.Uint16(guid[:2]); guid == FormatGUID(format) {
+		return SubFormat{GUID: guid, Name: fmt.Sprintf("0x%04X", format), Format: format}, true
+	}
+
+	return SubFormat{}, false
+}
+
+// EffectiveFormat returns the format of the samples, which for the
+// extensible format is that of the sub-format, or FormatUnknown if the
+// sub-format is not known.
+func (c *FormatChunk) EffectiveFormat() uint16 {
+	format := binary.LittleEndian.Uint16(c.Format[:])
+	if format != FormatExtensible {
+		return format
+	}
+
+	subFormat, _ := LookupSubFormat(c.SubFormat)
+
+	return subFormat.Format
+}
+
+// EffectiveSubFormat returns the sub-format GUID, which for formats other
+// than the extensible format is derived from the format.
+func (c *FormatChunk) EffectiveSubFormat() GUID {
+	format := binary.LittleEndian.Uint16(c.Format[:])
+	if format != FormatExtensible {
+		return FormatGUID(format)
+	}
+
+	return c.SubFormat
+}
@@ -0,0 +1,99 @@
+package wav_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestGUID(t *testing.T) {
+	tests := []struct {
+		text string
+		guid wav.GUID
+	}{
+		{"00000001-0000-0010-8000-00AA00389B71", wav.SubFormatPCM},
+		{"{00000003-0000-0010-8000-00aa00389b71}", wav.SubFormatIEEEFloat},
+		{"00000001-0721-11D3-8644-C8C1CA000000", wav.SubFormatAmbisonicPCM},
+	}
+
+	for _, test := range tests {
+		guid, err := wav.ParseGUID(test.text)
+		if err != nil {
+			t.Fatalf("parsing %s: %s", test.text, err.Error())
+		}
+
+		if guid != test.guid {
+			t.Errorf("parsing %s: got % X, want % X", test.text, guid, test.guid)
+		}
+
+		if parsed, _ := wav.ParseGUID(guid.String()); parsed != guid {
+			t.Errorf("formatting %s: got %s", test.text, guid.String())
+		}
+	}
+
+	if got := wav.SubFormatPCM.String(); got != "00000001-0000-0010-8000-00AA00389B71" {
+		t.Errorf("string: got %s, want %s", got, "00000001-0000-0010-8000-00AA00389B71")
+	}
+
+	for _, text := range []string{"", "00000001-0000-0010-8000", "0000000G-0000-0010-8000-00AA00389B71", "000000010-000-0010-8000-00AA00389B71"} {
+		if _, err := wav.ParseGUID(text); !errors.Is(err, wav.ErrParseGUID) {
+			t.Errorf("parsing %q: got %v, want %v", text, err, wav.ErrParseGUID)
+		}
+	}
+}
+
+func TestSubFormat(t *testing.T) {
+	file, err := wav.New(wav.Config{Channels: 2, SampleRate: 48000, BitDepth: 16, ChannelMask: wav.SpeakerFrontLeft | wav.SpeakerFrontRight}, make([]byte, 8))
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	if format := file.FormatChunk.EffectiveFormat(); format != wav.FormatPCM {
+		t.Errorf("effective format: got 0x%04X, want 0x%04X", format, wav.FormatPCM)
+	}
+
+	guid := wav.MustParseGUID("12345678-9ABC-DEF0-1234-56789ABCDEF0")
+	file.FormatChunk.SubFormat = guid
+
+	var buffer bytes.Buffer
+
+	if err := file.Encode(&buffer); err != nil {
+		t.Fatalf("encoding: %s", err.Error())
+	}
+
+	if err := (&wav.WAVEFileFormat{}).Decode(bytes.NewReader(buffer.Bytes())); !errors.Is(err, wav.ErrDecodeFormatSubFormat) {
+		t.Errorf("unregistered sub-format: got %v, want %v", err, wav.ErrDecodeFormatSubFormat)
+	}
+
+	wav.RegisterSubFormat(wav.SubFormat{GUID: guid, Name: "test", Format: wav.FormatPCM})
+
+	decoded := &wav.WAVEFileFormat{}
+
+	if err := decoded.Decode(bytes.NewReader(buffer.Bytes())); err != nil {
+		t.Fatalf("decoding registered sub-format: %s", err.Error())
+	}
+
+	if format := decoded.FormatChunk.EffectiveFormat(); format != wav.FormatPCM {
+		t.Errorf("effective format: got 0x%04X, want 0x%04X", format, wav.FormatPCM)
+	}
+
+	if subFormat, ok := wav.LookupSubFormat(guid); !ok || subFormat.Name != "test" {
+		t.Errorf("lookup: got %+v, %t", subFormat, ok)
+	}
+
+	// Formats other than the extensible format have a derived sub-format
+	plain, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatMuLaw}, nil)
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+
+	if guid := plain.FormatChunk.EffectiveSubFormat(); guid != wav.SubFormatMuLaw {
+		t.Errorf("effective sub-format: got %s, want %s", guid, wav.SubFormatMuLaw)
+	}
+
+	if subFormat, ok := wav.LookupSubFormat(wav.FormatGUID(wav.FormatOpus)); !ok || subFormat.Format != wav.FormatOpus {
+		t.Errorf("derived lookup: got %+v, %t", subFormat, ok)
+	}
+}
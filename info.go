@@ -0,0 +1,144 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Common LIST INFO text IDs
+var (
+	InfoTitle        = [4]byte{'I', 'N', 'A', 'M'}
+	InfoArtist       = [4]byte{'I', 'A', 'R', 'T'}
+	InfoProduct      = [4]byte{'I', 'P', 'R', 'D'} // Album
+	InfoTrackNumber  = [4]byte{'I', 'T', 'R', 'K'}
+	InfoGenre        = [4]byte{'I', 'G', 'N', 'R'}
+	InfoComment      = [4]byte{'I', 'C', 'M', 'T'}
+	InfoCopyright    = [4]byte{'I', 'C', 'O', 'P'}
+	InfoCreationDate = [4]byte{'I', 'C', 'R', 'D'}
+	InfoEngineer     = [4]byte{'I', 'E', 'N', 'G'}
+	InfoKeywords     = [4]byte{'I', 'K', 'E', 'Y'}
+	InfoSoftware     = [4]byte{'I', 'S', 'F', 'T'}
+	InfoSource       = [4]byte{'I', 'S', 'R', 'C'}
+	InfoSubject      = [4]byte{'I', 'S', 'B', 'J'}
+)
+
+var ErrDecodeInfo = errors.New("LIST INFO sub-chunk is malformed")
+
+// InfoEntry is a text field of a LIST INFO sub-chunk.
+type InfoEntry struct {
+	ID    [4]byte
+	Value string
+}
+
+// InfoChunk holds the text fields of a LIST INFO sub-chunk in file order.
+type InfoChunk struct {
+	Entries []InfoEntry
+}
+
+// Decode decodes the LIST INFO sub-chunk data following the list type.
+func (c *InfoChunk) Decode(data []byte) error {
+	c.Entries = nil
+
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return ErrDecodeInfo
+		}
+
+		id := [4]byte(data[0:4])
+		size := binary.LittleEndian.Uint32(data[4:8])
+		data = data[8:]
+
+		if uint64(size) > uint64(len(data)) {
+			return fmt.Errorf("%w: '%s' field exceeds the sub-chunk", ErrDecodeInfo, id[:])
+		}
+
+		c.Entries = append(c.Entries, InfoEntry{
+			ID:    id,
+			Value: decodeString(data[:size]),
+		})
+
+		data = data[min(int(size)+int(size)%2, len(data)):]
+	}
+
+	return nil
+}
+
+// Encode encodes the LIST INFO sub-chunk data following the list type. Values
+// are terminated by a null character.
+func (c *InfoChunk) Encode() []byte {
+	var data []byte
+
+	for _, entry := range c.Entries {
+		size := len(entry.Value) + 1
+
+		data = append(data, entry.ID[:]...)
+		data = binary.LittleEndian.AppendUint32(data, uint32(size))
+		data = append(data, entry.Value...)
+		data = append(data, 0)
+
+		if size%2 != 0 {
+			data = append(data, 0)
+		}
+	}
+
+	return data
+}
+
+// Get returns the value of the first field with the given ID.
+func (c *InfoChunk) Get(id [4]byte) (string, bool) {
+	for _, entry := range c.Entries {
+		if entry.ID == id {
+			return entry.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set replaces the value of the first field with the given ID, or appends a
+// new field.
+func (c *InfoChunk) Set(id [4]byte, value string) {
+	for i := range c.Entries {
+		if c.Entries[i].ID == id {
+			c.Entries[i].Value = value
+			return
+		}
+	}
+
+	c.Entries = append(c.Entries, InfoEntry{ID: id, Value: value})
+}
+
+// Delete removes all fields with the given ID.
+func (c *InfoChunk) Delete(id [4]byte) {
+	entries := c.Entries[:0]
+
+	for _, entry := range c.Entries {
+		if entry.ID != id {
+			entries = append(entries, entry)
+		}
+	}
+
+	c.Entries = entries
+}
+
+// Info returns the decoded LIST INFO sub-chunk.
+func (f *WAVEFileFormat) Info() (*InfoChunk, error) {
+	data, err := f.ListChunk([4]byte{'I', 'N', 'F', 'O'})
+	if err != nil {
+		return nil, err
+	}
+
+	info := &InfoChunk{}
+
+	if err := info.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// SetInfo encodes the LIST INFO sub-chunk, replacing an existing one.
+func (f *WAVEFileFormat) SetInfo(info *InfoChunk) error {
+	return f.SetListChunk([4]byte{'I', 'N', 'F', 'O'}, info.Encode())
+}
@@ -0,0 +1,50 @@
+// Package cli provides helpers shared by the commands.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/samborkent/wav"
+)
+
+// SamePath reports whether both paths refer to the same file, either by
+// name or, if both exist, by identity.
+func SamePath(a, b string) (bool, error) {
+	a, err := filepath.Abs(a)
+	if err != nil {
+		return false, err
+	}
+
+	b, err = filepath.Abs(b)
+	if err != nil {
+		return false, err
+	}
+
+	if a == b {
+		return true, nil
+	}
+
+	statA, errA := os.Stat(a)
+	statB, errB := os.Stat(b)
+
+	return errA == nil && errB == nil && os.SameFile(statA, statB), nil
+}
+
+// Decode decodes the WAV file at path, including its audio data.
+func Decode(path string) (*wav.WAVEFileFormat, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	waveFile := &wav.WAVEFileFormat{}
+
+	if err := waveFile.Decode(file); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	return waveFile, nil
+}
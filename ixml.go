@@ -0,0 +1,262 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var ErrDecodeIXML = errors.New("iXML sub-chunk must contain a BWFXML document")
+
+// IXMLElement is an element of an iXML document. Elements with children have
+// no value.
+type IXMLElement struct {
+	Name     string
+	Value    string
+	Elements []IXMLElement
+}
+
+// IXMLChunk holds the iXML production metadata as a tree of elements below
+// the BWFXML root element. Attributes, comments and processing instructions
+// are not preserved.
+type IXMLChunk struct {
+	Elements []IXMLElement
+}
+
+// Decode decodes the iXML sub-chunk data.
+func (c *IXMLChunk) Decode(data []byte) error {
+	// Documents are often padded with null characters
+	decoder := xml.NewDecoder(bytes.NewReader(bytes.TrimRight(data, "\x00")))
+
+	var stack []*IXMLElement
+	root := &IXMLElement{}
+	found := false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("%w: %w", ErrDecodeIXML, err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 {
+				if found || token.Name.Local != "BWFXML" {
+					return ErrDecodeIXML
+				}
+
+				found = true
+				stack = append(stack, root)
+
+				continue
+			}
+
+			parent := stack[len(stack)-1]
+			parent.Elements = append(parent.Elements, IXMLElement{Name: token.Name.Local})
+			stack = append(stack, &parent.Elements[len(parent.Elements)-1])
+		case xml.EndElement:
+			element := stack[len(stack)-1]
+			element.Value = strings.TrimSpace(element.Value)
+
+			if len(element.Elements) > 0 {
+				element.Value = ""
+			}
+
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Value += string(token)
+			}
+		}
+	}
+
+	if !found || len(stack) != 0 {
+		return ErrDecodeIXML
+	}
+
+	c.Elements = root.Elements
+
+	return nil
+}
+
+// Encode encodes the iXML sub-chunk data as an indented document. Elements
+// without a name cannot be encoded.
+func (c *IXMLChunk) Encode() ([]byte, error) {
+	buffer := bytes.NewBufferString(xml.Header)
+
+	encoder := xml.NewEncoder(buffer)
+	encoder.Indent("", "\t")
+
+	root := IXMLElement{Name: "BWFXML", Elements: c.Elements}
+
+	if err := root.encode(encoder); err != nil {
+		return nil, fmt.Errorf("encoding iXML: %w", err)
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, fmt.Errorf("encoding iXML: %w", err)
+	}
+
+	buffer.WriteByte('\n')
+
+	return buffer.Bytes(), nil
+}
+
+func (e *IXMLElement) encode(encoder *xml.Encoder) error {
+	start := xml.StartElement{Name: xml.Name{Local: e.Name}}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if len(e.Elements) == 0 {
+		if err := encoder.EncodeToken(xml.CharData(e.Value)); err != nil {
+			return err
+		}
+	}
+
+	for i := range e.Elements {
+		if err := e.Elements[i].encode(encoder); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// Get returns the value of the element at path, which lists the names of the
+// nested elements separated by slashes, such as "SPEED/TIMECODE_RATE". The
+// first element with a matching name is used at each level.
+func (c *IXMLChunk) Get(path string) (string, bool) {
+	elements := c.Elements
+	names := strings.Split(path, "/")
+
+	for i, name := range names {
+		index := findIXMLElement(elements, name)
+		if index < 0 {
+			return "", false
+		}
+
+		if i == len(names)-1 {
+			return elements[index].Value, true
+		}
+
+		elements = elements[index].Elements
+	}
+
+	return "", false
+}
+
+// Set sets the value of the element at path, creating missing elements.
+// Elements with children have no value and cannot be set.
+func (c *IXMLChunk) Set(path string, value string) error {
+	elements := &c.Elements
+	names := strings.Split(path, "/")
+
+	for i, name := range names {
+		if !isIXMLName(name) {
+			return fmt.Errorf("invalid iXML element name %q", name)
+		}
+
+		index := findIXMLElement(*elements, name)
+		if index < 0 {
+			*elements = append(*elements, IXMLElement{Name: name})
+			index = len(*elements) - 1
+		}
+
+		element := &(*elements)[index]
+
+		if i == len(names)-1 {
+			if len(element.Elements) > 0 {
+				return fmt.Errorf("iXML element %q has child elements", path)
+			}
+
+			element.Value = value
+		}
+
+		elements = &element.Elements
+	}
+
+	return nil
+}
+
+// Delete removes the element at path including its children, and reports
+// whether it was found.
+func (c *IXMLChunk) Delete(path string) bool {
+	elements := &c.Elements
+	names := strings.Split(path, "/")
+
+	for i, name := range names {
+		index := findIXMLElement(*elements, name)
+		if index < 0 {
+			return false
+		}
+
+		if i == len(names)-1 {
+			*elements = append((*elements)[:index], (*elements)[index+1:]...)
+			return true
+		}
+
+		elements = &(*elements)[index].Elements
+	}
+
+	return false
+}
+
+func findIXMLElement(elements []IXMLElement, name string) int {
+	for i := range elements {
+		if elements[i].Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func isIXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r == '_':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// IXML returns the decoded iXML sub-chunk.
+func (f *WAVEFileFormat) IXML() (*IXMLChunk, error) {
+	data, err := f.SubChunk([4]byte{'i', 'X', 'M', 'L'})
+	if err != nil {
+		return nil, err
+	}
+
+	ixml := &IXMLChunk{}
+
+	if err := ixml.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return ixml, nil
+}
+
+// SetIXML encodes the iXML sub-chunk, replacing an existing one.
+func (f *WAVEFileFormat) SetIXML(ixml *IXMLChunk) error {
+	data, err := ixml.Encode()
+	if err != nil {
+		return err
+	}
+
+	return f.SetSubChunk([4]byte{'i', 'X', 'M', 'L'}, data)
+}
@@ -0,0 +1,479 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"slices"
+)
+
+// ITU-R BS.1770-4 and EBU Tech 3341/3342 measurement parameters
+const (
+	loudnessOffset          = -0.691 // LUFS
+	loudnessAbsoluteGate    = -70.0  // LUFS
+	loudnessRelativeGate    = -10.0  // LU
+	loudnessRangeGate       = -20.0  // LU
+	loudnessRangeLow        = 0.10   // Percentile
+	loudnessRangeHigh       = 0.95   // Percentile
+	loudnessStepsPerSecond  = 10     // 100 ms block overlap
+	loudnessMomentarySteps  = 4      // 400 ms
+	loudnessShortTermSteps  = 30     // 3 s
+	loudnessSurroundWeight  = 1.41   // +1.5 dB
+	truePeakTapsPerPhase    = 12
+	truePeakOversampledRate = 192000
+)
+
+// Loudness holds the loudness measurement of audio data.
+type Loudness struct {
+	Integrated   float64 // Gated integrated loudness in LUFS
+	Range        float64 // Loudness range in LU
+	TruePeak     float64 // Maximum true peak level in dBTP
+	MaxMomentary float64 // Highest momentary loudness in LUFS
+	MaxShortTerm float64 // Highest short-term loudness in LUFS
+}
+
+// MeasureLoudness decodes the header of the WAV file read from reader and
+// streams over the audio data to measure its loudness.
+func MeasureLoudness(reader io.Reader) (*Loudness, error) {
+	file, samples, err := DecodeSamples(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return measureLoudness(samples, file.FormatChunk)
+}
+
+// Loudness measures the loudness of the decoded audio data.
+func (f *WAVEFileFormat) Loudness() (*Loudness, error) {
+	samples, err := f.SampleReader()
+	if err != nil {
+		return nil, err
+	}
+
+	return measureLoudness(samples, f.FormatChunk)
+}
+
+func measureLoudness(samples *SampleReader, format FormatChunk) (*Loudness, error) {
+	meter, err := NewLoudnessMeter(format)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := make([]float64, analysisFrames*samples.Channels())
+
+	for {
+		frames, err := samples.ReadFrames(buffer)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		meter.WriteFrames(buffer[:frames*samples.Channels()])
+	}
+
+	loudness := meter.Loudness()
+
+	return &loudness, nil
+}
+
+// SetBextLoudness writes the loudness measurement into the bext sub-chunk,
+// creating it if absent and upgrading it to version 2.
+func (f *WAVEFileFormat) SetBextLoudness(loudness *Loudness) error {
+	bext, err := f.Bext()
+	if errors.Is(err, ErrSubChunkNotFound) {
+		bext = &BextChunk{}
+	} else if err != nil {
+		return err
+	}
+
+	bext.Version = max(bext.Version, BextVersion)
+	bext.LoudnessValue = bextLoudness(loudness.Integrated)
+	bext.LoudnessRange = bextLoudness(loudness.Range)
+	bext.MaxTruePeakLevel = bextLoudness(loudness.TruePeak)
+	bext.MaxMomentaryLoudness = bextLoudness(loudness.MaxMomentary)
+	bext.MaxShortTermLoudness = bextLoudness(loudness.MaxShortTerm)
+
+	return f.SetBext(bext)
+}
+
+// bextLoudness converts a level to hundredths, the unit of the bext loudness
+// fields, marking levels that cannot be represented as unknown.
+func bextLoudness(level float64) int16 {
+	value := math.Round(level * 100)
+	if math.IsNaN(value) || value < math.MinInt16 || value >= BextLoudnessUnknown {
+		return BextLoudnessUnknown
+	}
+
+	return int16(value)
+}
+
+// LoudnessMeter measures loudness of interleaved samples incrementally.
+type LoudnessMeter struct {
+	channels   int
+	weights    []float64
+	preFilters []biquad
+	rlbFilters []biquad
+	truePeaks  []oversampler
+
+	stepSize   int       // Frames per 100 ms step
+	stepFrames int       // Frames in the current step
+	stepEnergy float64   // Weighted energy of the current step
+	steps      []float64 // Weighted energy of each complete step
+	truePeak   float64
+}
+
+// NewLoudnessMeter returns a LoudnessMeter for samples of the given format.
+// Channels are weighted by their speaker position, which is taken from the
+// channel mask of the extensible format, or assumed to be in 5.1 order.
+func NewLoudnessMeter(format FormatChunk) (*LoudnessMeter, error) {
+	if _, err := format.validateSampleFormat(); err != nil {
+		return nil, err
+	}
+
+	channels := int(binary.LittleEndian.Uint16(format.NumChannels[:]))
+	sampleRate := float64(binary.LittleEndian.Uint32(format.SampleRate[:]))
+
+	if sampleRate == 0 {
+		return nil, ErrSampleFormatNotSupported
+	}
+
+	meter := &LoudnessMeter{
+		channels:   channels,
+		weights:    format.loudnessWeights(),
+		preFilters: make([]biquad, channels),
+		rlbFilters: make([]biquad, channels),
+		truePeaks:  make([]oversampler, channels),
+		stepSize:   max(1, int(math.Round(sampleRate/loudnessStepsPerSecond))),
+	}
+
+	// K-weighting filter coefficients for arbitrary sample rates, matching
+	// the coefficients given in BS.1770 at 48 kHz
+	preFilter := highShelf(sampleRate, 1681.974450955533, 3.999843853973347, 0.7071752369554196)
+	rlbFilter := highPass(sampleRate, 38.13547087602444, 0.5003270373238773)
+	coefficients := oversamplingFilter(truePeakFactor(sampleRate))
+
+	for channel := range channels {
+		meter.preFilters[channel] = preFilter
+		meter.rlbFilters[channel] = rlbFilter
+		meter.truePeaks[channel] = newOversampler(coefficients)
+	}
+
+	return meter, nil
+}
+
+// WriteFrames processes whole frames of interleaved samples.
+func (m *LoudnessMeter) WriteFrames(samples []float64) {
+	for frame := range len(samples) / m.channels {
+		for channel := range m.channels {
+			sample := samples[frame*m.channels+channel]
+
+			m.truePeak = max(m.truePeak, m.truePeaks[channel].peak(sample))
+
+			if m.weights[channel] == 0 {
+				continue
+			}
+
+			filtered := m.rlbFilters[channel].process(m.preFilters[channel].process(sample))
+			m.stepEnergy += m.weights[channel] * filtered * filtered
+		}
+
+		m.stepFrames++
+
+		if m.stepFrames == m.stepSize {
+			m.steps = append(m.steps, m.stepEnergy)
+			m.stepFrames = 0
+			m.stepEnergy = 0
+		}
+	}
+}
+
+// Loudness returns the measurement of all samples written so far. Levels
+// that cannot be measured, such as of silence, are negative infinity.
+func (m *LoudnessMeter) Loudness() Loudness {
+	momentary := m.blockLoudness(loudnessMomentarySteps)
+	shortTerm := m.blockLoudness(loudnessShortTermSteps)
+
+	loudness := Loudness{
+		Integrated:   gatedLoudness(momentary, loudnessRelativeGate),
+		Range:        loudnessRange(shortTerm),
+		TruePeak:     20 * math.Log10(m.truePeak),
+		MaxMomentary: math.Inf(-1),
+		MaxShortTerm: math.Inf(-1),
+	}
+
+	if len(momentary) > 0 {
+		loudness.MaxMomentary = slices.Max(momentary)
+	}
+
+	if len(shortTerm) > 0 {
+		loudness.MaxShortTerm = slices.Max(shortTerm)
+	}
+
+	return loudness
+}
+
+// blockLoudness returns the loudness of each block of the given number of
+// steps, with a hop size of one step.
+func (m *LoudnessMeter) blockLoudness(steps int) []float64 {
+	if len(m.steps) < steps {
+		return nil
+	}
+
+	loudness := make([]float64, 0, len(m.steps)-steps+1)
+
+	var energy float64
+
+	for i, step := range m.steps {
+		energy += step
+
+		if i >= steps {
+			energy -= m.steps[i-steps]
+		}
+
+		if i >= steps-1 {
+			loudness = append(loudness, energyToLoudness(max(0, energy)/float64(steps*m.stepSize)))
+		}
+	}
+
+	return loudness
+}
+
+// gatedLoudness returns the loudness of the mean energy of the blocks above
+// the absolute gate and the relative gate below the absolute gated loudness.
+func gatedLoudness(blocks []float64, relativeGate float64) float64 {
+	loudness := meanLoudness(blocks, loudnessAbsoluteGate)
+	if math.IsInf(loudness, -1) {
+		return loudness
+	}
+
+	return meanLoudness(blocks, loudness+relativeGate)
+}
+
+// meanLoudness returns the loudness of the mean energy of the blocks above
+// both the absolute gate and threshold.
+func meanLoudness(blocks []float64, threshold float64) float64 {
+	var energy float64
+	var count int
+
+	for _, block := range blocks {
+		if block > loudnessAbsoluteGate && block > threshold {
+			energy += loudnessToEnergy(block)
+			count++
+		}
+	}
+
+	if count == 0 {
+		return math.Inf(-1)
+	}
+
+	return energyToLoudness(energy / float64(count))
+}
+
+// loudnessRange returns the spread between the low and high percentile of
+// the gated short-term loudness, as defined by EBU Tech 3342.
+func loudnessRange(shortTerm []float64) float64 {
+	loudness := meanLoudness(shortTerm, loudnessAbsoluteGate)
+	if math.IsInf(loudness, -1) {
+		return 0
+	}
+
+	threshold := loudness + loudnessRangeGate
+	gated := make([]float64, 0, len(shortTerm))
+
+	for _, block := range shortTerm {
+		if block > loudnessAbsoluteGate && block > threshold {
+			gated = append(gated, block)
+		}
+	}
+
+	if len(gated) == 0 {
+		return 0
+	}
+
+	slices.Sort(gated)
+
+	percentile := func(p float64) float64 {
+		return gated[int(math.Round(p*float64(len(gated)-1)))]
+	}
+
+	return percentile(loudnessRangeHigh) - percentile(loudnessRangeLow)
+}
+
+func energyToLoudness(energy float64) float64 {
+	return loudnessOffset + 10*math.Log10(energy)
+}
+
+func loudnessToEnergy(loudness float64) float64 {
+	return math.Pow(10, (loudness-loudnessOffset)/10)
+}
+
+// loudnessWeights returns the BS.1770 weight of each channel: surround
+// channels are amplified and the low frequency channel is excluded.
+func (c *FormatChunk) loudnessWeights() []float64 {
+	channels := int(binary.LittleEndian.Uint16(c.NumChannels[:]))
+	weights := make([]float64, channels)
+
+	mask := binary.LittleEndian.Uint32(c.ChannelMask[:])
+	if binary.LittleEndian.Uint16(c.Format[:]) != FormatExtensible || mask == 0 {
+		switch channels {
+		case 5:
+			// L, R, C, Ls, Rs
+			mask = SpeakerFrontLeft | SpeakerFrontRight | SpeakerFrontCenter | SpeakerSideLeft | SpeakerSideRight
+		case 6:
+			// L, R, C, LFE, Ls, Rs
+			mask = SpeakerFrontLeft | SpeakerFrontRight | SpeakerFrontCenter | SpeakerLowFrequency | SpeakerSideLeft | SpeakerSideRight
+		default:
+			mask = 0
+		}
+	}
+
+	for channel := range weights {
+		// Speaker of the channel is the lowest remaining bit of the mask
+		speaker := mask & -mask
+		mask &^= speaker
+
+		switch speaker {
+		case SpeakerLowFrequency:
+			weights[channel] = 0
+		case SpeakerBackLeft, SpeakerBackRight, SpeakerSideLeft, SpeakerSideRight:
+			weights[channel] = loudnessSurroundWeight
+		default:
+			weights[channel] = 1
+		}
+	}
+
+	return weights
+}
+
+// biquad is a second order IIR filter in transposed direct form II.
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	z1, z2     float64
+}
+
+func (f *biquad) process(sample float64) float64 {
+	output := f.b0*sample + f.z1
+	f.z1 = f.b1*sample - f.a1*output + f.z2
+	f.z2 = f.b2*sample - f.a2*output
+
+	return output
+}
+
+// highShelf returns the K-weighting pre-filter modelling the acoustic effect
+// of the head.
+func highShelf(sampleRate, frequency, gain, q float64) biquad {
+	k := math.Tan(math.Pi * frequency / sampleRate)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+
+	return biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+}
+
+// highPass returns the revised low-frequency B-weighting (RLB) filter.
+func highPass(sampleRate, frequency, q float64) biquad {
+	k := math.Tan(math.Pi * frequency / sampleRate)
+	a0 := 1 + k/q + k*k
+
+	return biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+}
+
+// truePeakFactor returns the oversampling factor needed to reach a sample
+// rate of at least 192 kHz, as recommended by BS.1770 annex 2.
+func truePeakFactor(sampleRate float64) int {
+	return max(1, int(math.Ceil(truePeakOversampledRate/sampleRate)))
+}
+
+// oversamplingFilter returns the polyphase coefficients of a windowed sinc
+// interpolation filter, indexed by phase and tap.
+func oversamplingFilter(factor int) [][]float64 {
+	length := factor * truePeakTapsPerPhase
+	center := float64(length-1) / 2
+
+	coefficients := make([][]float64, factor)
+	for phase := range coefficients {
+		coefficients[phase] = make([]float64, truePeakTapsPerPhase)
+	}
+
+	for i := range length {
+		t := (float64(i) - center) / float64(factor)
+
+		sinc := 1.0
+		if t != 0 {
+			sinc = math.Sin(math.Pi*t) / (math.Pi * t)
+		}
+
+		// Blackman window
+		x := 2 * math.Pi * float64(i) / float64(length-1)
+		window := 0.42 - 0.5*math.Cos(x) + 0.08*math.Cos(2*x)
+
+		coefficients[i%factor][i/factor] = sinc * window
+	}
+
+	// Unity gain for each phase
+	for _, phase := range coefficients {
+		var sum float64
+		for _, coefficient := range phase {
+			sum += coefficient
+		}
+
+		for tap := range phase {
+			phase[tap] /= sum
+		}
+	}
+
+	return coefficients
+}
+
+// oversampler interpolates a single channel to detect inter-sample peaks.
+type oversampler struct {
+	coefficients [][]float64
+	history      []float64 // Most recent sample first
+}
+
+func newOversampler(coefficients [][]float64) oversampler {
+	return oversampler{
+		coefficients: coefficients,
+		history:      make([]float64, truePeakTapsPerPhase),
+	}
+}
+
+// peak returns the largest absolute value of the interpolated samples
+// between the previous and the current sample.
+func (o *oversampler) peak(sample float64) float64 {
+	copy(o.history[1:], o.history)
+	o.history[0] = sample
+
+	peak := math.Abs(sample)
+
+	if len(o.coefficients) == 1 {
+		return peak
+	}
+
+	for _, phase := range o.coefficients {
+		var interpolated float64
+
+		for tap, coefficient := range phase {
+			interpolated += coefficient * o.history[tap]
+		}
+
+		peak = max(peak, math.Abs(interpolated))
+	}
+
+	return peak
+}
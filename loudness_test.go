@@ -0,0 +1,104 @@
+package wav_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+// sine returns interleaved 32 bit float audio data of a sine wave in all channels.
+func sine(channels, sampleRate int, frequency, amplitude, seconds float64) []byte {
+	frames := int(seconds * float64(sampleRate))
+	data := make([]byte, 0, frames*channels*4)
+
+	for frame := range frames {
+		sample := float32(amplitude * math.Sin(2*math.Pi*frequency*float64(frame)/float64(sampleRate)))
+
+		for range channels {
+			data = binary.LittleEndian.AppendUint32(data, math.Float32bits(sample))
+		}
+	}
+
+	return data
+}
+
+func TestLoudness(t *testing.T) {
+	// EBU Tech 3341 test case 1: stereo 1 kHz sine at -23 dBFS
+	amplitude := math.Pow(10, -23.0/20)
+
+	waveFile, err := wav.New(wav.Config{Channels: 2, SampleRate: 48000, BitDepth: 32, FloatingPoint: true}, sine(2, 48000, 1000, amplitude, 20))
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	loudness, err := wav.MeasureLoudness(encoded)
+	if err != nil {
+		t.Fatalf("measuring loudness: %s", err.Error())
+	}
+
+	if math.Abs(loudness.Integrated+23) > 0.1 {
+		t.Errorf("integrated loudness: got %f LUFS, want -23 LUFS", loudness.Integrated)
+	}
+
+	if math.Abs(loudness.MaxMomentary+23) > 0.1 || math.Abs(loudness.MaxShortTerm+23) > 0.1 {
+		t.Errorf("maximum momentary and short-term loudness: got %f and %f LUFS, want -23 LUFS", loudness.MaxMomentary, loudness.MaxShortTerm)
+	}
+
+	if loudness.Range > 0.1 {
+		t.Errorf("loudness range: got %f LU, want 0 LU", loudness.Range)
+	}
+
+	if math.Abs(loudness.TruePeak+23) > 0.2 {
+		t.Errorf("true peak: got %f dBTP, want -23 dBTP", loudness.TruePeak)
+	}
+
+	if err := waveFile.SetBextLoudness(loudness); err != nil {
+		t.Fatalf("setting bext loudness: %s", err.Error())
+	}
+
+	encoded.Reset()
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	decoded := &wav.WAVEFileFormat{}
+
+	if err := decoded.Decode(encoded); err != nil {
+		t.Fatalf("decoding wav file: %s", err.Error())
+	}
+
+	bext, err := decoded.Bext()
+	if err != nil {
+		t.Fatalf("decoding bext sub-chunk: %s", err.Error())
+	}
+
+	if bext.Version != wav.BextVersion || bext.LoudnessValue != int16(math.Round(loudness.Integrated*100)) {
+		t.Errorf("bext: got version %d and loudness %d, want version 2 and loudness %f", bext.Version, bext.LoudnessValue, loudness.Integrated)
+	}
+}
+
+func TestLoudnessSilence(t *testing.T) {
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 44100, BitDepth: 16}, make([]byte, 2*44100))
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	loudness, err := waveFile.Loudness()
+	if err != nil {
+		t.Fatalf("measuring loudness: %s", err.Error())
+	}
+
+	if !math.IsInf(loudness.Integrated, -1) || !math.IsInf(loudness.TruePeak, -1) {
+		t.Errorf("got %+v, want negative infinite loudness and true peak", loudness)
+	}
+}
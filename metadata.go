@@ -0,0 +1,187 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var ErrMetadataSpace = errors.New("metadata does not fit before the audio data")
+
+// UpdateMetadata rewrites the chunks preceding the audio data of file with
+// the format and sub-chunks of header, which is typically decoded from the
+// same file by DecodeMetadata and then modified. The audio data stays in
+// place: the remaining space is filled with a JUNK sub-chunk and sub-chunks
+// following the audio data are removed. ErrMetadataSpace is returned if the
+// header does not fit, in which case the file must be rewritten with
+// RewriteMetadata.
+func UpdateMetadata(file interface {
+	io.ReadWriteSeeker
+	Truncate(size int64) error
+}, header *WAVEFileFormat) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking: %w", err)
+	}
+
+	data, err := findData(file)
+	if err != nil {
+		return err
+	}
+
+	if data.Size != uint32(header.DataSize()) {
+		return fmt.Errorf("header data size %d does not match %d bytes of audio data", header.DataSize(), data.Size)
+	}
+
+	encoded, err := header.encodeHeader(-1)
+	if err != nil {
+		return err
+	}
+
+	// Data sub-chunk header
+	end := data.Offset + 8
+
+	if space := end - int64(len(encoded)); space != 0 {
+		if space < 8 {
+			return ErrMetadataSpace
+		}
+
+		encoded, err = header.encodeHeader(int(space - 8))
+		if err != nil {
+			return err
+		}
+	}
+
+	size := end + int64(data.Size)
+
+	if err := file.Truncate(size + int64(data.Size%2)); err != nil {
+		return fmt.Errorf("truncating: %w", err)
+	}
+
+	if err := writeAt(file, 0, encoded); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	if data.Size%2 != 0 {
+		if err := writeAt(file, size, []byte{0}); err != nil {
+			return fmt.Errorf("writing data sub-chunk: padding byte: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RewriteMetadata writes a WAV file to writer with the format and sub-chunks
+// of header and the audio data of the WAV file read from reader. A JUNK
+// sub-chunk of reserve bytes is added before the audio data, so metadata
+// can later grow by as much with UpdateMetadata.
+func RewriteMetadata(writer io.Writer, reader io.ReadSeeker, header *WAVEFileFormat, reserve int) error {
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking: %w", err)
+	}
+
+	data, err := findData(reader)
+	if err != nil {
+		return err
+	}
+
+	if data.Size != uint32(header.DataSize()) {
+		return fmt.Errorf("header data size %d does not match %d bytes of audio data", header.DataSize(), data.Size)
+	}
+
+	encoded, err := header.encodeHeader(reserve + reserve%2)
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(encoded); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	if _, err := reader.Seek(data.Offset+8, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking: %w", err)
+	}
+
+	if _, err := io.CopyN(writer, reader, int64(data.Size)); err != nil {
+		return fmt.Errorf("copying data sub-chunk: %w", err)
+	}
+
+	if data.Size%2 != 0 {
+		if _, err := writer.Write([]byte{0}); err != nil {
+			return fmt.Errorf("writing data sub-chunk: padding byte: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// findData returns the location of the data sub-chunk.
+func findData(reader io.Reader) (ChunkInfo, error) {
+	chunks, err := ScanChunks(reader)
+	if err != nil {
+		return ChunkInfo{}, err
+	}
+
+	for _, chunk := range chunks {
+		if chunk.ID == [4]byte{'d', 'a', 't', 'a'} {
+			return chunk, nil
+		}
+	}
+
+	return ChunkInfo{}, ErrDecodeDataID
+}
+
+// encodeHeader encodes all chunks up to and including the data sub-chunk
+// header, with the RIFF chunk size covering the audio data. Padding sub-chunks
+// are left out, and a JUNK sub-chunk of junk bytes is added unless junk is
+// negative.
+func (f *WAVEFileFormat) encodeHeader(junk int) ([]byte, error) {
+	file := *f
+	file.DataChunk.Data = nil
+	file.SubChunks = nil
+
+	for _, subChunk := range f.SubChunks {
+		if !isPadding(subChunk.Chunk.ID) {
+			file.SubChunks = append(file.SubChunks, subChunk)
+		}
+	}
+
+	if junk >= 0 {
+		subChunk := SubChunk{
+			Chunk: Chunk{
+				ID: [4]byte{'J', 'U', 'N', 'K'},
+			},
+			Data: make([]byte, junk),
+		}
+
+		binary.LittleEndian.PutUint32(subChunk.Chunk.Size[:], uint32(junk))
+		file.SubChunks = append(file.SubChunks, subChunk)
+	}
+
+	buffer := new(bytes.Buffer)
+
+	// The audio data is written separately
+	if err := file.encode(buffer); err != nil {
+		return nil, err
+	}
+
+	encoded := buffer.Bytes()
+
+	dataSize := uint64(f.DataSize())
+	riffSize := uint64(len(encoded)) - 8 + dataSize + dataSize%2
+
+	if riffSize > math.MaxUint32 {
+		return nil, ErrDataTooLarge
+	}
+
+	binary.LittleEndian.PutUint32(encoded[4:8], uint32(riffSize))
+
+	return encoded, nil
+}
+
+// isPadding reports whether a sub-chunk only reserves space.
+func isPadding(id [4]byte) bool {
+	return id == [4]byte{'J', 'U', 'N', 'K'} || id == [4]byte{'j', 'u', 'n', 'k'} || id == [4]byte{'P', 'A', 'D', ' '}
+}
@@ -0,0 +1,221 @@
+package wav_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestInfo(t *testing.T) {
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, []byte{1, 2})
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	info := &wav.InfoChunk{}
+	info.Set(wav.InfoTitle, "odd")
+	info.Set(wav.InfoComment, "even")
+	info.Set(wav.InfoTitle, "title")
+
+	if err := waveFile.SetInfo(info); err != nil {
+		t.Fatalf("setting info: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	decoded := &wav.WAVEFileFormat{}
+
+	if err := decoded.Decode(bytes.NewReader(encoded.Bytes())); err != nil {
+		t.Fatalf("decoding wav file: %s", err.Error())
+	}
+
+	decodedInfo, err := decoded.Info()
+	if err != nil {
+		t.Fatalf("decoding info: %s", err.Error())
+	}
+
+	want := []wav.InfoEntry{{ID: wav.InfoTitle, Value: "title"}, {ID: wav.InfoComment, Value: "even"}}
+
+	if !slices.Equal(decodedInfo.Entries, want) {
+		t.Errorf("info entries: got %v, want %v", decodedInfo.Entries, want)
+	}
+
+	decodedInfo.Delete(wav.InfoTitle)
+
+	if _, ok := decodedInfo.Get(wav.InfoTitle); ok {
+		t.Errorf("deleted title: got a value, want none")
+	}
+}
+
+func TestIXML(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?><BWFXML><PROJECT>Film</PROJECT><SPEED><TIMECODE_RATE>25/1</TIMECODE_RATE></SPEED></BWFXML>` + "\x00\x00")
+
+	ixml := &wav.IXMLChunk{}
+
+	if err := ixml.Decode(data); err != nil {
+		t.Fatalf("decoding ixml: %s", err.Error())
+	}
+
+	if value, ok := ixml.Get("SPEED/TIMECODE_RATE"); !ok || value != "25/1" {
+		t.Errorf("timecode rate: got %q, want %q", value, "25/1")
+	}
+
+	if err := ixml.Set("SCENE", "1 & 2"); err != nil {
+		t.Fatalf("setting scene: %s", err.Error())
+	}
+
+	if err := ixml.Set("SPEED", "fast"); err == nil {
+		t.Errorf("setting element with children: got no error")
+	}
+
+	if !ixml.Delete("PROJECT") {
+		t.Errorf("deleting project: not found")
+	}
+
+	encoded, err := ixml.Encode()
+	if err != nil {
+		t.Fatalf("encoding ixml: %s", err.Error())
+	}
+
+	decoded := &wav.IXMLChunk{}
+
+	if err := decoded.Decode(encoded); err != nil {
+		t.Fatalf("decoding encoded ixml: %s", err.Error())
+	}
+
+	if value, ok := decoded.Get("SCENE"); !ok || value != "1 & 2" {
+		t.Errorf("scene: got %q, want %q", value, "1 & 2")
+	}
+
+	if _, ok := decoded.Get("PROJECT"); ok {
+		t.Errorf("deleted project: got a value, want none")
+	}
+
+	if err := decoded.Decode([]byte("<OTHER/>")); !errors.Is(err, wav.ErrDecodeIXML) {
+		t.Errorf("decoding other document: got %v, want %v", err, wav.ErrDecodeIXML)
+	}
+
+	invalid := &wav.IXMLChunk{Elements: []wav.IXMLElement{{Name: "", Value: "unnamed"}}}
+
+	if _, err := invalid.Encode(); err == nil {
+		t.Errorf("encoding element without name: got no error")
+	}
+
+	file, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, []byte{1, 2})
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	if err := file.SetIXML(invalid); err == nil {
+		t.Errorf("setting element without name: got no error")
+	} else if _, err := file.IXML(); !errors.Is(err, wav.ErrSubChunkNotFound) {
+		t.Errorf("iXML after failed set: got %v, want %v", err, wav.ErrSubChunkNotFound)
+	}
+}
+
+func TestUpdateMetadata(t *testing.T) {
+	data := []byte{1, 2, 3}
+
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, data)
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	path := filepath.Join(t.TempDir(), "metadata.wav")
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+	defer file.Close()
+
+	if err := waveFile.Encode(file); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	header := &wav.WAVEFileFormat{}
+
+	if err := decodeFileMetadata(file, header); err != nil {
+		t.Fatalf("decoding metadata: %s", err.Error())
+	}
+
+	if err := header.SetBext(&wav.BextChunk{Description: "grown"}); err != nil {
+		t.Fatalf("setting bext: %s", err.Error())
+	}
+
+	if err := wav.UpdateMetadata(file, header); !errors.Is(err, wav.ErrMetadataSpace) {
+		t.Fatalf("updating metadata without space: got %v, want %v", err, wav.ErrMetadataSpace)
+	}
+
+	rewritten := new(bytes.Buffer)
+
+	if err := wav.RewriteMetadata(rewritten, file, header, 100); err != nil {
+		t.Fatalf("rewriting metadata: %s", err.Error())
+	}
+
+	if err := file.Truncate(0); err != nil {
+		t.Fatalf("truncating: %s", err.Error())
+	}
+
+	if _, err := file.WriteAt(rewritten.Bytes(), 0); err != nil {
+		t.Fatalf("writing file: %s", err.Error())
+	}
+
+	// Shrinking and growing within the reserved space keeps the data in place
+	for _, description := range []string{"", "grown further"} {
+		if err := decodeFileMetadata(file, header); err != nil {
+			t.Fatalf("decoding metadata: %s", err.Error())
+		}
+
+		if err := header.SetInfo(&wav.InfoChunk{Entries: []wav.InfoEntry{{ID: wav.InfoTitle, Value: description}}}); err != nil {
+			t.Fatalf("setting info: %s", err.Error())
+		}
+
+		if err := wav.UpdateMetadata(file, header); err != nil {
+			t.Fatalf("updating metadata: %s", err.Error())
+		}
+
+		encoded, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading file: %s", err.Error())
+		}
+
+		if len(encoded) != rewritten.Len() {
+			t.Errorf("file size: got %d, want %d", len(encoded), rewritten.Len())
+		}
+
+		decoded := &wav.WAVEFileFormat{}
+
+		if err := decoded.Decode(bytes.NewReader(encoded)); err != nil {
+			t.Fatalf("decoding wav file: %s", err.Error())
+		}
+
+		if !bytes.Equal(decoded.Data(), data) {
+			t.Errorf("data: got %v, want %v", decoded.Data(), data)
+		}
+
+		if info, err := decoded.Info(); err != nil {
+			t.Errorf("decoding info: %s", err.Error())
+		} else if title, _ := info.Get(wav.InfoTitle); title != description {
+			t.Errorf("title: got %q, want %q", title, description)
+		}
+	}
+}
+
+func decodeFileMetadata(file *os.File, header *wav.WAVEFileFormat) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return header.DecodeMetadata(file)
+}
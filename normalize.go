@@ -0,0 +1,250 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// Limiter time constants in seconds
+const (
+	limiterLookahead = 0.005
+	limiterRelease   = 0.050
+)
+
+var ErrNormalizeSilence = errors.New("cannot normalize silence")
+
+// NormalizeTarget is the measure that is normalized.
+type NormalizeTarget int
+
+const (
+	NormalizeLoudness NormalizeTarget = iota // Integrated loudness in LUFS
+	NormalizePeak                            // Sample peak in dBFS
+	NormalizeTruePeak                        // True peak in dBTP
+)
+
+// NormalizeConfig configures the normalization of audio data.
+type NormalizeConfig struct {
+	Target NormalizeTarget
+	Level  float64 // Target level in the unit of the target
+
+	// Limit enables a look-ahead peak limiter that keeps the samples below
+	// Ceiling. Without it, samples exceeding full scale are clipped.
+	Limit   bool
+	Ceiling float64 // dBFS
+}
+
+// NormalizationGain returns the gain in dB that brings the audio data to the
+// configured target level.
+func (f *WAVEFileFormat) NormalizationGain(cfg NormalizeConfig) (float64, error) {
+	var level float64
+
+	switch cfg.Target {
+	case NormalizeLoudness, NormalizeTruePeak:
+		loudness, err := f.Loudness()
+		if err != nil {
+			return 0, err
+		}
+
+		if cfg.Target == NormalizeLoudness {
+			level = loudness.Integrated
+		} else {
+			level = loudness.TruePeak
+		}
+	case NormalizePeak:
+		analysis, err := f.Analyze()
+		if err != nil {
+			return 0, err
+		}
+
+		level = math.Inf(-1)
+
+		for _, channel := range analysis.Channels {
+			level = max(level, channel.PeakDBFS())
+		}
+	}
+
+	if math.IsInf(level, -1) {
+		return 0, ErrNormalizeSilence
+	}
+
+	return cfg.Level - level, nil
+}
+
+// Normalize returns a copy of the file, including its metadata sub-chunks,
+// with the gain applied that brings the audio data to the configured target
+// level, encoded in the original format.
+func (f *WAVEFileFormat) Normalize(cfg NormalizeConfig) (*WAVEFileFormat, error) {
+	gain, err := f.NormalizationGain(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.ApplyGain(gain, cfg.Limit, cfg.Ceiling)
+}
+
+// ApplyGain returns a copy of the file with the gain in dB applied. If limit
+// is set, a look-ahead peak limiter keeps the samples below ceiling in dBFS.
+func (f *WAVEFileFormat) ApplyGain(gain float64, limit bool, ceiling float64) (*WAVEFileFormat, error) {
+	linear := math.Pow(10, gain/20)
+
+	var limiterGains []float64
+
+	if limit {
+		var err error
+
+		limiterGains, err = f.limiterGains(linear, math.Pow(10, ceiling/20))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := f.mapFrames(func(frame int, samples []float64) {
+		frameGain := linear
+		if limiterGains != nil {
+			frameGain *= limiterGains[frame]
+		}
+
+		for i := range samples {
+			samples[i] *= frameGain
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return f.withData(data)
+}
+
+// limiterGains returns the gain reduction of each frame needed to keep the
+// amplified samples below ceiling. The gain is reduced ahead of each peak,
+// so the attack is smooth, and recovers exponentially after it.
+func (f *WAVEFileFormat) limiterGains(gain, ceiling float64) ([]float64, error) {
+	sampleRate := float64(binary.LittleEndian.Uint32(f.FormatChunk.SampleRate[:]))
+	lookahead := max(1, int(limiterLookahead*sampleRate))
+	release := math.Exp(-1 / (limiterRelease * sampleRate))
+
+	// Gain reduction required by the peak of each frame
+	required := make([]float64, 0, f.Frames())
+
+	if err := f.forEachFrame(nil, func(_ int, samples []float64) {
+		var peak float64
+		for _, sample := range samples {
+			peak = max(peak, math.Abs(sample*gain))
+		}
+
+		if peak > ceiling {
+			required = append(required, ceiling/peak)
+		} else {
+			required = append(required, 1)
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	// Hold the lowest gain of the look-ahead window, with release
+	held := make([]float64, len(required))
+	window := make([]int, 0, lookahead) // Indices of increasing gains
+
+	for i := len(required) - 1; i >= 0; i-- {
+		for len(window) > 0 && required[window[len(window)-1]] >= required[i] {
+			window = window[:len(window)-1]
+		}
+
+		window = append(window, i)
+
+		if window[0] >= i+lookahead {
+			window = window[1:]
+		}
+
+		held[i] = required[window[0]]
+	}
+
+	previous := 1.0
+
+	for i := range held {
+		held[i] = min(held[i], 1-(1-previous)*release)
+		previous = held[i]
+	}
+
+	if len(held) == 0 {
+		return held, nil
+	}
+
+	// Moving average over the look-ahead window, which never exceeds the
+	// required gain, as all held gains in the window are at most as high.
+	// Gains before the start of the audio data equal the first held gain.
+	gains := make([]float64, len(held))
+	sum := float64(lookahead) * held[0]
+
+	for i := range held {
+		sum += held[i]
+
+		if i >= lookahead {
+			sum -= held[i-lookahead]
+		} else {
+			sum -= held[0]
+		}
+
+		gains[i] = sum / float64(lookahead)
+	}
+
+	return gains, nil
+}
+
+// mapFrames decodes each frame of the audio data, calls fn with its index
+// and interleaved samples, and returns the modified samples encoded in the
+// original format.
+func (f *WAVEFileFormat) mapFrames(fn func(frame int, samples []float64)) ([]byte, error) {
+	output := bytes.NewBuffer(make([]byte, 0, len(f.DataChunk.Data)))
+
+	writer, err := NewSampleWriter(output, f.FormatChunk)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.forEachFrame(writer, fn); err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+// forEachFrame decodes each frame of the audio data and calls fn with its
+// index and interleaved samples. If writer is set, the samples are encoded
+// to it after fn returns.
+func (f *WAVEFileFormat) forEachFrame(writer *SampleWriter, fn func(frame int, samples []float64)) error {
+	reader, err := f.SampleReader()
+	if err != nil {
+		return err
+	}
+
+	channels := reader.Channels()
+	buffer := make([]float64, analysisFrames*channels)
+
+	var frame int
+
+	for {
+		frames, err := reader.ReadFrames(buffer)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		for i := range frames {
+			fn(frame, buffer[i*channels:(i+1)*channels])
+			frame++
+		}
+
+		if writer == nil {
+			continue
+		}
+
+		if _, err := writer.WriteFrames(buffer[:frames*channels]); err != nil {
+			return err
+		}
+	}
+}
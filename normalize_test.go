@@ -0,0 +1,94 @@
+package wav_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestNormalize(t *testing.T) {
+	waveFile, err := wav.New(wav.Config{Channels: 2, SampleRate: 48000, BitDepth: 32, FloatingPoint: true}, sine(2, 48000, 440, 0.03, 5))
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	if err := waveFile.SetBext(&wav.BextChunk{Description: "sine"}); err != nil {
+		t.Fatalf("setting bext sub-chunk: %s", err.Error())
+	}
+
+	testCases := []struct {
+		name   string
+		config wav.NormalizeConfig
+		check  func(t *testing.T, normalized *wav.WAVEFileFormat)
+	}{
+		{
+			name:   "peak",
+			config: wav.NormalizeConfig{Target: wav.NormalizePeak, Level: -1},
+			check: func(t *testing.T, normalized *wav.WAVEFileFormat) {
+				analysis, err := normalized.Analyze()
+				if err != nil {
+					t.Fatalf("analyzing: %s", err.Error())
+				}
+
+				if peak := analysis.Channels[0].PeakDBFS(); math.Abs(peak+1) > 0.01 {
+					t.Errorf("peak: got %f dBFS, want -1 dBFS", peak)
+				}
+			},
+		},
+		{
+			name:   "loudness",
+			config: wav.NormalizeConfig{Target: wav.NormalizeLoudness, Level: -23},
+			check: func(t *testing.T, normalized *wav.WAVEFileFormat) {
+				loudness, err := normalized.Loudness()
+				if err != nil {
+					t.Fatalf("measuring loudness: %s", err.Error())
+				}
+
+				if math.Abs(loudness.Integrated+23) > 0.01 {
+					t.Errorf("integrated loudness: got %f LUFS, want -23 LUFS", loudness.Integrated)
+				}
+			},
+		},
+		{
+			name:   "limited",
+			config: wav.NormalizeConfig{Target: wav.NormalizeLoudness, Level: 0, Limit: true, Ceiling: -1},
+			check: func(t *testing.T, normalized *wav.WAVEFileFormat) {
+				analysis, err := normalized.Analyze()
+				if err != nil {
+					t.Fatalf("analyzing: %s", err.Error())
+				}
+
+				if peak := analysis.Channels[0].PeakDBFS(); peak > -1+1e-6 {
+					t.Errorf("peak: got %f dBFS, want at most -1 dBFS", peak)
+				}
+
+				if clipped := analysis.Channels[0].ClippedSamples; clipped != 0 {
+					t.Errorf("clipped samples: got %d, want 0", clipped)
+				}
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			normalized, err := waveFile.Normalize(testCase.config)
+			if err != nil {
+				t.Fatalf("normalizing: %s", err.Error())
+			}
+
+			if normalized.Frames() != waveFile.Frames() || normalized.Size() != waveFile.Size() {
+				t.Errorf("got %d frames and size %d, want %d frames and size %d", normalized.Frames(), normalized.Size(), waveFile.Frames(), waveFile.Size())
+			}
+
+			bext, err := normalized.Bext()
+			if err != nil {
+				t.Fatalf("decoding bext sub-chunk: %s", err.Error())
+			} else if bext.Description != "sine" {
+				t.Errorf("bext description: got %q, want %q", bext.Description, "sine")
+			}
+
+			testCase.check(t, normalized)
+		})
+	}
+}
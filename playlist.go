@@ -0,0 +1,155 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Size of a segment in the plst sub-chunk
+const PlaylistSegmentSize = 12
+
+var (
+	ErrDecodePlaylist = errors.New("plst sub-chunk is malformed")
+	ErrPlaylistCue    = errors.New("playlist segment references an unknown cue point")
+	ErrPlaylistLength = errors.New("playlist segment has no length")
+)
+
+// PlaylistSegment plays a number of frames starting at a cue point.
+type PlaylistSegment struct {
+	CueID   uint32
+	Length  uint32 // Sample frames, zero to use the length of the cue region
+	Repeats uint32 // Number of times to play the segment
+}
+
+// PlaylistChunk holds the play order of the segments of a file.
+type PlaylistChunk struct {
+	Segments []PlaylistSegment
+}
+
+// Decode decodes the plst sub-chunk data.
+func (c *PlaylistChunk) Decode(data []byte) error {
+	if len(data) < 4 {
+		return ErrDecodePlaylist
+	}
+
+	count := binary.LittleEndian.Uint32(data[0:4])
+	data = data[4:]
+
+	if uint64(count)*PlaylistSegmentSize > uint64(len(data)) {
+		return fmt.Errorf("%w: %d segments exceed the sub-chunk", ErrDecodePlaylist, count)
+	}
+
+	c.Segments = make([]PlaylistSegment, count)
+
+	for i := range c.Segments {
+		segment := data[i*PlaylistSegmentSize : (i+1)*PlaylistSegmentSize]
+
+		c.Segments[i] = PlaylistSegment{
+			CueID:   binary.LittleEndian.Uint32(segment[0:4]),
+			Length:  binary.LittleEndian.Uint32(segment[4:8]),
+			Repeats: binary.LittleEndian.Uint32(segment[8:12]),
+		}
+	}
+
+	return nil
+}
+
+// Encode encodes the plst sub-chunk data.
+func (c *PlaylistChunk) Encode() []byte {
+	data := make([]byte, 4, 4+len(c.Segments)*PlaylistSegmentSize)
+	binary.LittleEndian.PutUint32(data, uint32(len(c.Segments)))
+
+	for _, segment := range c.Segments {
+		data = binary.LittleEndian.AppendUint32(data, segment.CueID)
+		data = binary.LittleEndian.AppendUint32(data, segment.Length)
+		data = binary.LittleEndian.AppendUint32(data, segment.Repeats)
+	}
+
+	return data
+}
+
+// Playlist returns the decoded plst sub-chunk.
+func (f *WAVEFileFormat) Playlist() (*PlaylistChunk, error) {
+	data, err := f.SubChunk([4]byte{'p', 'l', 's', 't'})
+	if err != nil {
+		return nil, err
+	}
+
+	playlist := &PlaylistChunk{}
+
+	if err := playlist.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return playlist, nil
+}
+
+// SetPlaylist encodes the plst sub-chunk, replacing an existing one.
+func (f *WAVEFileFormat) SetPlaylist(playlist *PlaylistChunk) error {
+	return f.SetSubChunk([4]byte{'p', 'l', 's', 't'}, playlist.Encode())
+}
+
+// Render returns a copy of the file, including its metadata sub-chunks, with
+// the audio data of the playlist segments joined in play order. Segments are
+// played at least once, and segments without a length span the region of
+// their cue point. Cue points within the segments are kept as with Concat,
+// and the plst sub-chunk is removed.
+func (f *WAVEFileFormat) Render() (*WAVEFileFormat, error) {
+	playlist, err := f.Playlist()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(playlist.Segments) == 0 {
+		return nil, fmt.Errorf("rendering playlist: %w", ErrNoFiles)
+	}
+
+	cue, adtl, err := f.cues()
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []*WAVEFileFormat
+
+	for i, segment := range playlist.Segments {
+		point, ok := cue.Point(segment.CueID)
+		if !ok {
+			return nil, fmt.Errorf("rendering segment %d: %w: %d", i, ErrPlaylistCue, segment.CueID)
+		}
+
+		length := segment.Length
+		if length == 0 {
+			if region, ok := adtl.Region(segment.CueID); ok {
+				length = region.SampleLength
+			}
+		}
+
+		if length == 0 {
+			return nil, fmt.Errorf("rendering segment %d: %w", i, ErrPlaylistLength)
+		}
+
+		start := int(point.SampleOffset)
+
+		file, err := f.Slice(start, start+int(length))
+		if err != nil {
+			return nil, fmt.Errorf("rendering segment %d: %w", i, err)
+		}
+
+		for range max(segment.Repeats, 1) {
+			segments = append(segments, file)
+		}
+	}
+
+	rendered, err := Concat(segments...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The segments no longer refer to the rendered audio data
+	if err := rendered.RemoveSubChunk([4]byte{'p', 'l', 's', 't'}); err != nil {
+		return nil, err
+	}
+
+	return rendered, nil
+}
@@ -0,0 +1,110 @@
+package wav_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestPlaylist(t *testing.T) {
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, data)
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	if err := waveFile.SetCue(&wav.CueChunk{Points: []wav.CuePoint{wav.NewCuePoint(1, 10), wav.NewCuePoint(2, 60)}}); err != nil {
+		t.Fatalf("setting cue: %s", err.Error())
+	}
+
+	if err := waveFile.SetAssociatedData(&wav.AssociatedDataChunk{
+		Regions: []wav.CueRegion{{CueID: 2, SampleLength: 5, Purpose: [4]byte{'r', 'g', 'n', ' '}}},
+	}); err != nil {
+		t.Fatalf("setting associated data: %s", err.Error())
+	}
+
+	// Cue point 2 for its region length twice, then 3 frames of cue point 1
+	layout := []byte{
+		0x02, 0x00, 0x00, 0x00, // Number of segments
+		0x02, 0x00, 0x00, 0x00, // Cue ID
+		0x00, 0x00, 0x00, 0x00, // Length
+		0x02, 0x00, 0x00, 0x00, // Repeats
+		0x01, 0x00, 0x00, 0x00, // Cue ID
+		0x03, 0x00, 0x00, 0x00, // Length
+		0x01, 0x00, 0x00, 0x00, // Repeats
+	}
+
+	if err := waveFile.SetSubChunk([4]byte{'p', 'l', 's', 't'}, layout); err != nil {
+		t.Fatalf("setting plst sub-chunk: %s", err.Error())
+	}
+
+	playlist, err := waveFile.Playlist()
+	if err != nil {
+		t.Fatalf("decoding playlist: %s", err.Error())
+	}
+
+	want := []wav.PlaylistSegment{{CueID: 2, Repeats: 2}, {CueID: 1, Length: 3, Repeats: 1}}
+
+	if !slices.Equal(playlist.Segments, want) {
+		t.Errorf("playlist segments: got %v, want %v", playlist.Segments, want)
+	}
+
+	if encoded := playlist.Encode(); !bytes.Equal(encoded, layout) {
+		t.Errorf("encoded playlist: got % X, want % X", encoded, layout)
+	}
+
+	rendered, err := waveFile.Render()
+	if err != nil {
+		t.Fatalf("rendering playlist: %s", err.Error())
+	}
+
+	wantData := []byte{60, 61, 62, 63, 64, 60, 61, 62, 63, 64, 10, 11, 12}
+
+	if !bytes.Equal(rendered.DataChunk.Data, wantData) {
+		t.Errorf("rendered data: got %v, want %v", rendered.DataChunk.Data, wantData)
+	}
+
+	if _, err := rendered.Playlist(); !errors.Is(err, wav.ErrSubChunkNotFound) {
+		t.Errorf("rendered playlist: got %v, want %v", err, wav.ErrSubChunkNotFound)
+	}
+
+	cue, err := rendered.Cue()
+	if err != nil {
+		t.Fatalf("decoding rendered cue: %s", err.Error())
+	}
+
+	if len(cue.Points) != 3 {
+		t.Fatalf("rendered cue points: got %d, want 3", len(cue.Points))
+	}
+
+	if frames := []uint32{cue.Points[0].SampleOffset, cue.Points[1].SampleOffset, cue.Points[2].SampleOffset}; !slices.Equal(frames, []uint32{0, 5, 10}) {
+		t.Errorf("rendered cue points: got %v, want frames 0, 5 and 10", cue.Points)
+	}
+
+	if err := waveFile.SetPlaylist(&wav.PlaylistChunk{Segments: []wav.PlaylistSegment{{CueID: 9, Length: 1}}}); err != nil {
+		t.Fatalf("setting playlist: %s", err.Error())
+	}
+
+	if _, err := waveFile.Render(); !errors.Is(err, wav.ErrPlaylistCue) {
+		t.Errorf("rendering unknown cue: got %v, want %v", err, wav.ErrPlaylistCue)
+	}
+
+	if err := waveFile.SetPlaylist(&wav.PlaylistChunk{Segments: []wav.PlaylistSegment{{CueID: 1}}}); err != nil {
+		t.Fatalf("setting playlist: %s", err.Error())
+	}
+
+	if _, err := waveFile.Render(); !errors.Is(err, wav.ErrPlaylistLength) {
+		t.Errorf("rendering segment without length: got %v, want %v", err, wav.ErrPlaylistLength)
+	}
+
+	if err := (&wav.PlaylistChunk{}).Decode([]byte{2, 0, 0, 0}); !errors.Is(err, wav.ErrDecodePlaylist) {
+		t.Errorf("decoding short playlist: got %v, want %v", err, wav.ErrDecodePlaylist)
+	}
+}
@@ -0,0 +1,83 @@
+package wav
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+)
+
+// RawLayout describes how samples of headerless audio data are stored, where
+// WAV stores little endian samples and unsigned 8-bit PCM.
+type RawLayout struct {
+	BigEndian bool
+	Signed8   bool // 8-bit PCM samples are signed
+}
+
+// DecodeRaw reads headerless audio data of the configuration until the end
+// of reader and wraps it into a file. Partial trailing frames are dropped.
+func DecodeRaw(reader io.Reader, cfg Config, layout RawLayout) (*WAVEFileFormat, error) {
+	format, err := NewFormatChunk(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	bytesPerSample, err := format.validateSampleFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, math.MaxUint32))
+	if err != nil {
+		return nil, fmt.Errorf("reading raw audio data: %w", err)
+	}
+
+	data = data[:len(data)/(cfg.Channels*bytesPerSample)*cfg.Channels*bytesPerSample]
+
+	convertRaw(data, format.EffectiveFormat(), bytesPerSample, layout)
+
+	return New(cfg, data)
+}
+
+// EncodeRaw writes the audio data without any header in the layout.
+func (f *WAVEFileFormat) EncodeRaw(writer io.Writer, layout RawLayout) error {
+	bytesPerSample, err := f.FormatChunk.validateSampleFormat()
+	if err != nil {
+		return err
+	}
+
+	data, _, err := f.frameData()
+	if err != nil {
+		return err
+	}
+
+	data = bytes.Clone(data)
+
+	convertRaw(data, f.FormatChunk.EffectiveFormat(), bytesPerSample, layout)
+
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("writing raw audio data: %w", err)
+	}
+
+	return nil
+}
+
+// convertRaw converts samples in place between the WAV layout and the raw
+// layout, which is its own inverse.
+func convertRaw(data []byte, format uint16, bytesPerSample int, layout RawLayout) {
+	if format == FormatPCM && bytesPerSample == 1 && layout.Signed8 {
+		for i := range data {
+			data[i] ^= 0x80
+		}
+	}
+
+	if !layout.BigEndian || bytesPerSample == 1 {
+		return
+	}
+
+	for sample := 0; sample+bytesPerSample <= len(data); sample += bytesPerSample {
+		for i, j := sample, sample+bytesPerSample-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package wav_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestRaw(t *testing.T) {
+	cfg := wav.Config{Channels: 2, SampleRate: 8000, BitDepth: 16}
+
+	// Big endian samples with a partial trailing frame
+	raw := []byte{0x12, 0x34, 0x56, 0x78, 0x9A, 0xBC}
+
+	file, err := wav.DecodeRaw(bytes.NewReader(raw), cfg, wav.RawLayout{BigEndian: true})
+	if err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	if want := []byte{0x34, 0x12, 0x78, 0x56}; !bytes.Equal(file.DataChunk.Data, want) {
+		t.Errorf("audio data: got %v, want %v", file.DataChunk.Data, want)
+	}
+
+	var encoded bytes.Buffer
+
+	if err := file.EncodeRaw(&encoded, wav.RawLayout{BigEndian: true}); err != nil {
+		t.Fatalf("encoding: %s", err.Error())
+	}
+
+	if !bytes.Equal(encoded.Bytes(), raw[:4]) {
+		t.Errorf("encoded: got %v, want %v", encoded.Bytes(), raw[:4])
+	}
+
+	// Signed 8-bit samples
+	file, err = wav.DecodeRaw(bytes.NewReader([]byte{0x00, 0x80, 0x7F}), wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, wav.RawLayout{Signed8: true})
+	if err != nil {
+		t.Fatalf("decoding: %s", err.Error())
+	}
+
+	if want := []byte{0x80, 0x00, 0xFF}; !bytes.Equal(file.DataChunk.Data, want) {
+		t.Errorf("8-bit audio data: got %v, want %v", file.DataChunk.Data, want)
+	}
+
+	// Audio data not loaded by DecodeMetadata
+	encodedFile := new(bytes.Buffer)
+
+	if err := file.Encode(encodedFile); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	header := &wav.WAVEFileFormat{}
+
+	if err := header.DecodeMetadata(bytes.NewReader(encodedFile.Bytes())); err != nil {
+		t.Fatalf("decoding metadata: %s", err.Error())
+	}
+
+	if err := header.EncodeRaw(io.Discard, wav.RawLayout{}); !errors.Is(err, wav.ErrDataNotLoaded) {
+		t.Errorf("encoding unloaded audio data: got %v, want %v", err, wav.ErrDataNotLoaded)
+	}
+}
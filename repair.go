@@ -0,0 +1,311 @@
+package wav
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Repair describes the corrections that make a damaged WAV file valid, such
+// as a recording interrupted before the chunk sizes were written.
+type Repair struct {
+	Chunks   []ChunkInfo // RIFF chunk and sub-chunks to keep, with corrected sizes
+	Size     int64       // File size after truncation, without padding byte
+	Padding  bool        // Whether a padding byte must be appended
+	Problems []string    // Description of each problem found
+
+	factOffset   int64 // Offset of the fact sample length, if it must be updated
+	sampleLength uint32
+}
+
+// DiagnoseRepair scans the chunks of a WAV file and determines how to repair
+// it. Audio data with an impossible size, or a size of zero that does not
+// belong to a consistent file, is assumed to extend to the end of the file,
+// in which case partial trailing frames are dropped. Sub-chunks cut off by
+// the end of the file and trailing bytes are removed, and a missing padding
+// byte is added.
+func DiagnoseRepair(reader io.ReadSeeker) (*Repair, error) {
+	size, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("seeking: %w", err)
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seeking: %w", err)
+	}
+
+	var header [12]byte
+
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, fmt.Errorf("reading riff chunk: %w", err)
+	}
+
+	if [4]byte(header[0:4]) != [4]byte{'R', 'I', 'F', 'F'} {
+		return nil, ErrDecodeRIFFID
+	}
+
+	if [4]byte(header[8:12]) != [4]byte{'W', 'A', 'V', 'E'} {
+		return nil, ErrDecodeRIFFFormat
+	}
+
+	repair := &Repair{
+		Chunks: []ChunkInfo{{
+			ID:   [4]byte{'R', 'I', 'F', 'F'},
+			Size: binary.LittleEndian.Uint32(header[4:8]),
+		}},
+		factOffset: -1,
+	}
+
+	offset := int64(len(header))
+	blockAlign := int64(0)
+	foundData := false
+	factOffset := int64(-1)
+
+	for offset+8 <= size {
+		if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("seeking: %w", err)
+		}
+
+		var chunk [8]byte
+
+		if _, err := io.ReadFull(reader, chunk[:]); err != nil {
+			return nil, fmt.Errorf("reading sub-chunk at offset %d: %w", offset, err)
+		}
+
+		info := ChunkInfo{
+			ID:     [4]byte(chunk[0:4]),
+			Offset: offset,
+			Size:   binary.LittleEndian.Uint32(chunk[4:8]),
+		}
+
+		available := size - offset - 8
+		toEnd := false
+
+		switch info.ID {
+		case [4]byte{'f', 'm', 't', ' '}:
+			if info.Size < FormatChunkSizePCM || int64(info.Size) > available {
+				return nil, ErrDecodeFormatSize
+			}
+
+			var format [FormatChunkSizePCM]byte
+
+			if _, err := io.ReadFull(reader, format[:]); err != nil {
+				return nil, fmt.Errorf("reading format sub-chunk: %w", err)
+			}
+
+			blockAlign = int64(binary.LittleEndian.Uint16(format[12:14]))
+			if blockAlign == 0 {
+				return nil, fmt.Errorf("%w: block align of zero", ErrSampleFormatNotSupported)
+			}
+		case [4]byte{'f', 'a', 'c', 't'}:
+			if info.Size == FactChunkSize && available >= FactChunkSize {
+				factOffset = offset + 8
+			}
+		case [4]byte{'d', 'a', 't', 'a'}:
+			if blockAlign == 0 {
+				return nil, ErrDecodeFormatID
+			}
+
+			foundData = true
+
+			broken := int64(info.Size) > available
+
+			// Recorders leave a size of zero if interrupted, which is only
+			// distinguishable from empty audio data if the riff chunk size
+			// was not written either or no sub-chunk follows
+			if info.Size == 0 && available > 0 {
+				follows, err := chunkHeaderAt(reader, offset+8, size)
+				if err != nil {
+					return nil, err
+				}
+
+				riffSize := int64(repair.Chunks[0].Size)
+				broken = riffSize == 0 || riffSize != size-8 || !follows
+			}
+
+			if broken {
+				if available > math.MaxUint32 {
+					return nil, ErrDataTooLarge
+				}
+
+				// Audio data extends to the end of the file
+				repair.problem("data sub-chunk size %d does not match the %d bytes until the end of the file", info.Size, available)
+
+				toEnd = true
+				info.Size = uint32(available)
+
+				if partial := available % blockAlign; partial != 0 {
+					repair.problem("data sub-chunk ends with a partial frame of %d bytes", partial)
+					info.Size -= uint32(partial)
+				}
+			}
+
+			repair.sampleLength = info.Size / uint32(blockAlign)
+		}
+
+		if int64(info.Size) > available {
+			repair.problem("'%s' sub-chunk of %d bytes is cut off after %d bytes", info.ID[:], info.Size, available)
+
+			size = offset
+			break
+		}
+
+		repair.Chunks = append(repair.Chunks, info)
+		offset += 8 + int64(info.Size)
+
+		if info.Size%2 != 0 {
+			if !toEnd && offset < size {
+				offset++
+			} else {
+				if !toEnd {
+					repair.problem("'%s' sub-chunk is missing its padding byte", info.ID[:])
+				}
+
+				repair.Padding = true
+			}
+		}
+
+		// Sub-chunks following audio data that extends to the end of the
+		// file cannot be located
+		if toEnd {
+			size = offset
+			break
+		}
+	}
+
+	if !foundData {
+		return nil, ErrDecodeDataID
+	}
+
+	if offset < size {
+		repair.problem("%d bytes following the last sub-chunk", size-offset)
+	}
+
+	repair.Size = offset
+
+	riffSize := repair.Size - 8
+	if repair.Padding {
+		riffSize++
+	}
+
+	if riffSize > math.MaxUint32 {
+		return nil, ErrDataTooLarge
+	}
+
+	if repair.Chunks[0].Size != uint32(riffSize) {
+		repair.problem("riff chunk size %d does not match the file size", repair.Chunks[0].Size)
+		repair.Chunks[0].Size = uint32(riffSize)
+	}
+
+	if factOffset >= 0 {
+		if _, err := reader.Seek(factOffset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("seeking: %w", err)
+		}
+
+		var sampleLength [4]byte
+
+		if _, err := io.ReadFull(reader, sampleLength[:]); err != nil {
+			return nil, fmt.Errorf("reading fact sub-chunk: %w", err)
+		}
+
+		if length := binary.LittleEndian.Uint32(sampleLength[:]); length != repair.sampleLength {
+			repair.problem("fact sub-chunk sample length %d does not match %d frames", length, repair.sampleLength)
+			repair.factOffset = factOffset
+		}
+	}
+
+	return repair, nil
+}
+
+// Valid reports whether the file needs no repair.
+func (r *Repair) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+// Apply repairs the diagnosed file in place by truncating it, rewriting the
+// chunk sizes and appending the padding byte.
+func (r *Repair) Apply(file interface {
+	io.WriteSeeker
+	Truncate(size int64) error
+}) error {
+	if r.Valid() {
+		return nil
+	}
+
+	if err := file.Truncate(r.Size); err != nil {
+		return fmt.Errorf("truncating: %w", err)
+	}
+
+	var value [4]byte
+
+	for _, chunk := range r.Chunks {
+		binary.LittleEndian.PutUint32(value[:], chunk.Size)
+
+		if err := writeAt(file, chunk.Offset+4, value[:]); err != nil {
+			return fmt.Errorf("writing '%s' chunk: size: %w", chunk.ID[:], err)
+		}
+	}
+
+	if r.factOffset >= 0 {
+		binary.LittleEndian.PutUint32(value[:], r.sampleLength)
+
+		if err := writeAt(file, r.factOffset, value[:]); err != nil {
+			return fmt.Errorf("writing fact sub-chunk: sample length: %w", err)
+		}
+	}
+
+	if r.Padding {
+		if err := writeAt(file, r.Size, []byte{0}); err != nil {
+			return fmt.Errorf("writing padding byte: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// chunkHeaderAt reports whether a plausible sub-chunk header, with a
+// printable ID and a size within the file, starts at offset.
+func chunkHeaderAt(reader io.ReadSeeker, offset, size int64) (bool, error) {
+	if offset+8 > size {
+		return false, nil
+	}
+
+	if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+		return false, fmt.Errorf("seeking: %w", err)
+	}
+
+	var header [8]byte
+
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return false, fmt.Errorf("reading sub-chunk at offset %d: %w", offset, err)
+	}
+
+	for _, b := range header[0:4] {
+		if b < 0x20 || b > 0x7E {
+			return false, nil
+		}
+	}
+
+	return int64(binary.LittleEndian.Uint32(header[4:8])) <= size-offset-8, nil
+}
+
+func (r *Repair) problem(format string, args ...any) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// writeAt writes data at offset from the start of writer.
+func writeAt(writer io.WriteSeeker, offset int64, data []byte) error {
+	if _, err := writer.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	n, err := writer.Write(data)
+	if err != nil {
+		return err
+	} else if n != len(data) {
+		return io.ErrShortWrite
+	}
+
+	return nil
+}
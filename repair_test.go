@@ -0,0 +1,144 @@
+package wav_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestRepair(t *testing.T) {
+	data := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 32, FloatingPoint: true}, data)
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	valid := encoded.Bytes()
+
+	if repair, err := wav.DiagnoseRepair(bytes.NewReader(valid)); err != nil {
+		t.Fatalf("diagnosing valid file: %s", err.Error())
+	} else if !repair.Valid() {
+		t.Errorf("valid file: got problems %v, want none", repair.Problems)
+	}
+
+	// Sizes left at zero by a crashed recorder, with a partial frame
+	damaged := append(bytes.Clone(valid), 13, 14)
+	binary.LittleEndian.PutUint32(damaged[4:8], 0)
+	binary.LittleEndian.PutUint32(damaged[len(valid)-len(data)-4:], 0)
+	binary.LittleEndian.PutUint32(damaged[len(valid)-len(data)-12:], 0)
+
+	if err := (&wav.WAVEFileFormat{}).Decode(bytes.NewReader(damaged)); err == nil {
+		t.Fatalf("decoding damaged file: got no error")
+	}
+
+	path := filepath.Join(t.TempDir(), "damaged.wav")
+
+	if err := os.WriteFile(path, damaged, 0o644); err != nil {
+		t.Fatalf("writing file: %s", err.Error())
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("opening file: %s", err.Error())
+	}
+	defer file.Close()
+
+	repair, err := wav.DiagnoseRepair(file)
+	if err != nil {
+		t.Fatalf("diagnosing damaged file: %s", err.Error())
+	}
+
+	if len(repair.Problems) != 4 {
+		t.Errorf("problems: got %v, want 4", repair.Problems)
+	}
+
+	if err := repair.Apply(file); err != nil {
+		t.Fatalf("repairing file: %s", err.Error())
+	}
+
+	repaired, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading file: %s", err.Error())
+	}
+
+	if !bytes.Equal(repaired, valid) {
+		t.Errorf("repaired file: got %v, want %v", repaired, valid)
+	}
+}
+
+func TestRepairPadding(t *testing.T) {
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8}, []byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	valid := encoded.Bytes()
+
+	// Missing padding byte
+	repair, err := wav.DiagnoseRepair(bytes.NewReader(valid[:len(valid)-1]))
+	if err != nil {
+		t.Fatalf("diagnosing damaged file: %s", err.Error())
+	}
+
+	if !repair.Padding || repair.Size != int64(len(valid)-1) {
+		t.Errorf("repair: got padding %t and size %d, want padding and size %d", repair.Padding, repair.Size, len(valid)-1)
+	}
+
+	if repair.Chunks[0].Size != uint32(waveFile.Size()) {
+		t.Errorf("riff chunk size: got %d, want %d", repair.Chunks[0].Size, waveFile.Size())
+	}
+}
+
+func TestRepairEmptyData(t *testing.T) {
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 16}, nil)
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	if repair, err := wav.DiagnoseRepair(bytes.NewReader(encoded.Bytes())); err != nil {
+		t.Fatalf("diagnosing empty file: %s", err.Error())
+	} else if !repair.Valid() {
+		t.Errorf("empty file: got problems %v, want none", repair.Problems)
+	}
+
+	// Empty audio data followed by a LIST sub-chunk
+	list := []byte{'L', 'I', 'S', 'T', 18, 0, 0, 0, 'I', 'N', 'F', 'O', 'I', 'N', 'A', 'M', 6, 0, 0, 0, 't', 'i', 't', 'l', 'e', 0}
+	valid := append(bytes.Clone(encoded.Bytes()), list...)
+	binary.LittleEndian.PutUint32(valid[4:8], uint32(len(valid)-8))
+
+	repair, err := wav.DiagnoseRepair(bytes.NewReader(valid))
+	if err != nil {
+		t.Fatalf("diagnosing file: %s", err.Error())
+	}
+
+	if !repair.Valid() {
+		t.Errorf("file with trailing LIST sub-chunk: got problems %v, want none", repair.Problems)
+	}
+
+	if len(repair.Chunks) != 4 || repair.Chunks[3].ID != [4]byte{'L', 'I', 'S', 'T'} {
+		t.Errorf("chunks: got %v, want riff, fmt, data and LIST", repair.Chunks)
+	}
+}
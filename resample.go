@@ -0,0 +1,158 @@
+package wav
+
+import (
+	"math"
+)
+
+// Resampler parameters
+const (
+	resamplerZeroCrossings = 16  // Zero crossings of the sinc on each side
+	resamplerResolution    = 512 // Kernel table entries per zero crossing
+)
+
+// Resampler converts the sample rate of interleaved samples with a windowed
+// sinc interpolation filter. Samples are streamed through it in blocks of
+// any size; the output is delayed until enough input is available.
+type Resampler struct {
+	channels   int
+	inputRate  int64
+	outputRate int64
+
+	scale     float64   // Kernel time scale, below one when downsampling
+	halfWidth int64     // Kernel half width in input frames
+	kernel    []float64 // Kernel from zero to the half width
+
+	buffer       []float64 // Interleaved input frames from offset on
+	offset       int64     // Index of the first buffered input frame
+	inputFrames  int64
+	outputFrames int64
+}
+
+// NewResampler returns a Resampler converting from inputRate to outputRate.
+func NewResampler(channels, inputRate, outputRate int) *Resampler {
+	// Cutoff at the lower of both Nyquist frequencies
+	scale := min(1, float64(outputRate)/float64(inputRate))
+
+	resampler := &Resampler{
+		channels:   channels,
+		inputRate:  int64(inputRate),
+		outputRate: int64(outputRate),
+		scale:      scale,
+		halfWidth:  int64(math.Ceil(resamplerZeroCrossings / scale)),
+		kernel:     make([]float64, resamplerZeroCrossings*resamplerResolution+2),
+	}
+
+	for i := range resampler.kernel {
+		// Time in zero crossings of the sinc
+		t := float64(i) / resamplerResolution
+
+		if t >= resamplerZeroCrossings {
+			continue
+		}
+
+		sinc := 1.0
+		if t != 0 {
+			sinc = math.Sin(math.Pi*t) / (math.Pi * t)
+		}
+
+		// Blackman window
+		x := math.Pi * (1 + t/resamplerZeroCrossings)
+		window := 0.42 - 0.5*math.Cos(x) + 0.08*math.Cos(2*x)
+
+		resampler.kernel[i] = scale * sinc * window
+	}
+
+	return resampler
+}
+
+// Resample appends the resampled frames that can be computed after adding
+// the interleaved input frames to dst.
+func (r *Resampler) Resample(dst, input []float64) []float64 {
+	frames := len(input) / r.channels
+
+	if r.inputRate == r.outputRate {
+		r.inputFrames += int64(frames)
+		r.outputFrames += int64(frames)
+
+		return append(dst, input[:frames*r.channels]...)
+	}
+
+	r.buffer = append(r.buffer, input[:frames*r.channels]...)
+	r.inputFrames += int64(frames)
+
+	return r.produce(dst, r.inputFrames, math.MaxInt64)
+}
+
+// Flush appends the remaining resampled frames to dst, assuming silence
+// after the input, so the output spans the same duration as the input.
+func (r *Resampler) Flush(dst []float64) []float64 {
+	total := (r.inputFrames*r.outputRate + r.inputRate - 1) / r.inputRate
+
+	if r.inputRate == r.outputRate {
+		return dst
+	}
+
+	r.buffer = append(r.buffer, make([]float64, (r.halfWidth+1)*int64(r.channels))...)
+
+	return r.produce(dst, r.inputFrames+r.halfWidth+1, total)
+}
+
+// produce appends output frames as long as their kernel does not reach
+// beyond the available input frames, up to limit output frames.
+func (r *Resampler) produce(dst []float64, available, limit int64) []float64 {
+	channels := int64(r.channels)
+
+	for r.outputFrames < limit {
+		// Position of the output frame in input frames
+		numerator := r.outputFrames * r.inputRate
+		center := numerator / r.outputRate
+		fraction := float64(numerator%r.outputRate) / float64(r.outputRate)
+
+		if center+r.halfWidth >= available {
+			break
+		}
+
+		frame := len(dst)
+		dst = append(dst, make([]float64, channels)...)
+
+		for input := max(r.offset, center-r.halfWidth+1); input <= center+r.halfWidth; input++ {
+			weight := r.weight(float64(center-input) + fraction)
+			if weight == 0 {
+				continue
+			}
+
+			samples := r.buffer[(input-r.offset)*channels : (input-r.offset+1)*channels]
+
+			for channel, sample := range samples {
+				dst[frame+channel] += weight * sample
+			}
+		}
+
+		r.outputFrames++
+	}
+
+	// Drop input frames no longer needed by the next output frame
+	next := r.outputFrames * r.inputRate / r.outputRate
+	if drop := next - r.halfWidth + 1 - r.offset; drop > 0 {
+		drop = min(drop, int64(len(r.buffer))/channels)
+		r.buffer = r.buffer[:copy(r.buffer, r.buffer[drop*channels:])]
+		r.offset += drop
+	}
+
+	return dst
+}
+
+// weight returns the kernel value at a distance in input frames, linearly
+// interpolated from the kernel table.
+func (r *Resampler) weight(distance float64) float64 {
+	position := math.Abs(distance) * r.scale * resamplerResolution
+
+	index := int(position)
+	if index >= len(r.kernel)-1 {
+		return 0
+	}
+
+	fraction := position - float64(index)
+
+	return r.kernel[index]*(1-fraction) + r.kernel[index+1]*fraction
+}
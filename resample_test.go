@@ -0,0 +1,55 @@
+package wav_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestResampler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		inputRate  int
+		outputRate int
+		frequency  float64
+		amplitude  float64 // Expected output amplitude
+	}{
+		{name: "upsample", inputRate: 44100, outputRate: 48000, frequency: 1000, amplitude: 1},
+		{name: "downsample pass band", inputRate: 48000, outputRate: 16000, frequency: 6000, amplitude: 1},
+		{name: "downsample stop band", inputRate: 48000, outputRate: 16000, frequency: 10000, amplitude: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			input := make([]float64, testCase.inputRate)
+			for i := range input {
+				input[i] = math.Sin(2 * math.Pi * testCase.frequency * float64(i) / float64(testCase.inputRate))
+			}
+
+			resampler := wav.NewResampler(1, testCase.inputRate, testCase.outputRate)
+
+			var output []float64
+
+			// Blocks of varying size
+			for start, size := 0, 1; start < len(input); start, size = start+size, size*2+1 {
+				output = resampler.Resample(output, input[start:min(len(input), start+size)])
+			}
+
+			output = resampler.Flush(output)
+
+			if len(output) != testCase.outputRate {
+				t.Fatalf("frames: got %d, want %d", len(output), testCase.outputRate)
+			}
+
+			// Skip the edges, where the filter sees silence
+			for i := 100; i < len(output)-100; i++ {
+				want := testCase.amplitude * math.Sin(2*math.Pi*testCase.frequency*float64(i)/float64(testCase.outputRate))
+
+				if math.Abs(output[i]-want) > 0.01 {
+					t.Fatalf("frame %d: got %f, want %f", i, output[i], want)
+				}
+			}
+		})
+	}
+}
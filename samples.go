@@ -0,0 +1,380 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var ErrSampleFormatNotSupported = errors.New("sample format is not supported")
+
+// channelMask returns the speaker positions of the extensible format, or zero
+// for other formats.
+func (c *FormatChunk) channelMask() uint32 {
+	if binary.LittleEndian.Uint16(c.Format[:]) != FormatExtensible {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint32(c.ChannelMask[:])
+}
+
+// validateSampleFormat checks whether samples of the format can be decoded
+// and encoded, returning the number of bytes per sample.
+func (c *FormatChunk) validateSampleFormat() (int, error) {
+	channels := int(binary.LittleEndian.Uint16(c.NumChannels[:]))
+	bitsPerSample := int(binary.LittleEndian.Uint16(c.BitsPerSample[:]))
+
+	if channels == 0 {
+		return 0, fmt.Errorf("%w: zero channels", ErrSampleFormatNotSupported)
+	}
+
+	switch c.EffectiveFormat() {
+	case FormatPCM:
+		switch bitsPerSample {
+		case 8, 16, 24, 32:
+		default:
+			return 0, fmt.Errorf("%w: %d bit pcm", ErrSampleFormatNotSupported, bitsPerSample)
+		}
+	case FormatIEEEFloat:
+		switch bitsPerSample {
+		case 32, 64:
+		default:
+			return 0, fmt.Errorf("%w: %d bit floating point", ErrSampleFormatNotSupported, bitsPerSample)
+		}
+	case FormatALaw, FormatMuLaw:
+		if bitsPerSample != 8 {
+			return 0, fmt.Errorf("%w: %d bit companded", ErrSampleFormatNotSupported, bitsPerSample)
+		}
+	default:
+		return 0, fmt.Errorf("%w: format 0x%04X", ErrSampleFormatNotSupported, c.EffectiveFormat())
+	}
+
+	if blockAlign := int(binary.LittleEndian.Uint16(c.BlockAlign[:])); blockAlign != channels*bitsPerSample/8 {
+		return 0, fmt.Errorf("%w: block align %d does not match %d channels of %d bits", ErrSampleFormatNotSupported, blockAlign, channels, bitsPerSample)
+	}
+
+	return bitsPerSample / 8, nil
+}
+
+// fullScale returns the largest positive sample value the format can represent.
+func (c *FormatChunk) fullScale() float64 {
+	switch c.EffectiveFormat() {
+	case FormatIEEEFloat:
+		return 1
+	case FormatALaw:
+		return decodeALaw(0xAA)
+	case FormatMuLaw:
+		return decodeMuLaw(0x80)
+	default:
+		bitsPerSample := binary.LittleEndian.Uint16(c.BitsPerSample[:])
+		return 1 - 1/float64(uint64(1)<<(bitsPerSample-1))
+	}
+}
+
+// Config returns the configuration describing the audio data.
+func (f *WAVEFileFormat) Config() Config {
+	return Config{
+		Channels:      int(binary.LittleEndian.Uint16(f.FormatChunk.NumChannels[:])),
+		SampleRate:    int(binary.LittleEndian.Uint32(f.FormatChunk.SampleRate[:])),
+		BitDepth:      int(binary.LittleEndian.Uint16(f.FormatChunk.BitsPerSample[:])),
+		FloatingPoint: f.FormatChunk.EffectiveFormat() == FormatIEEEFloat,
+		Format:        f.FormatChunk.EffectiveFormat(),
+		ChannelMask:   f.FormatChunk.channelMask(),
+		Ambisonic:     f.FormatChunk.ambisonic(),
+	}
+}
+
+// Frames returns the number of sample frames, i.e. samples per channel.
+func (f *WAVEFileFormat) Frames() int {
+	blockAlign := int(binary.LittleEndian.Uint16(f.FormatChunk.BlockAlign[:]))
+	if blockAlign == 0 {
+		return 0
+	}
+
+	return f.DataSize() / blockAlign
+}
+
+// SampleReader decodes interleaved samples of the audio data into floating
+// point values, where full scale corresponds to the range [-1, 1).
+type SampleReader struct {
+	reader         io.Reader
+	format         uint16
+	channels       int
+	bytesPerSample int
+	buffer         []byte
+}
+
+// NewSampleReader returns a SampleReader that decodes the audio data read
+// from reader according to format. The reader should be limited to the data
+// sub-chunk, e.g. using io.LimitReader after DecodeHeader.
+func NewSampleReader(reader io.Reader, format FormatChunk) (*SampleReader, error) {
+	bytesPerSample, err := format.validateSampleFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SampleReader{
+		reader:         reader,
+		format:         format.EffectiveFormat(),
+		channels:       int(binary.LittleEndian.Uint16(format.NumChannels[:])),
+		bytesPerSample: bytesPerSample,
+	}, nil
+}
+
+// SampleReader returns a SampleReader over the decoded audio data.
+func (f *WAVEFileFormat) SampleReader() (*SampleReader, error) {
+	return NewSampleReader(bytes.NewReader(f.DataChunk.Data), f.FormatChunk)
+}
+
+// Channels returns the number of interleaved channels per frame.
+func (r *SampleReader) Channels() int {
+	return r.channels
+}
+
+// ReadFrames decodes as many whole frames as fit in samples, which holds the
+// samples of each frame interleaved, and returns the number of frames read.
+// A trailing partial frame is discarded. At the end of the audio data it
+// returns 0 and io.EOF.
+func (r *SampleReader) ReadFrames(samples []float64) (int, error) {
+	frames := len(samples) / r.channels
+	if frames == 0 {
+		return 0, io.ErrShortBuffer
+	}
+
+	size := frames * r.channels * r.bytesPerSample
+	if cap(r.buffer) < size {
+		r.buffer = make([]byte, size)
+	}
+
+	n, err := io.ReadFull(r.reader, r.buffer[:size])
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	frames = n / (r.channels * r.bytesPerSample)
+	if frames == 0 {
+		return 0, io.EOF
+	}
+
+	for i := range frames * r.channels {
+		samples[i] = decodeSample(r.format, r.buffer[i*r.bytesPerSample:(i+1)*r.bytesPerSample])
+	}
+
+	return frames, err
+}
+
+// SampleWriter encodes interleaved floating point samples into audio data.
+// Samples outside of the range [-1, 1] are clipped.
+type SampleWriter struct {
+	writer         io.Writer
+	format         uint16
+	channels       int
+	bytesPerSample int
+	buffer         []byte
+}
+
+// NewSampleWriter returns a SampleWriter that encodes samples to writer
+// according to format.
+func NewSampleWriter(writer io.Writer, format FormatChunk) (*SampleWriter, error) {
+	bytesPerSample, err := format.validateSampleFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SampleWriter{
+		writer:         writer,
+		format:         format.EffectiveFormat(),
+		channels:       int(binary.LittleEndian.Uint16(format.NumChannels[:])),
+		bytesPerSample: bytesPerSample,
+	}, nil
+}
+
+// Channels returns the number of interleaved channels per frame.
+func (w *SampleWriter) Channels() int {
+	return w.channels
+}
+
+// WriteFrames encodes the whole frames in samples, which holds the samples of
+// each frame interleaved, and returns the number of frames written.
+func (w *SampleWriter) WriteFrames(samples []float64) (int, error) {
+	frames := len(samples) / w.channels
+
+	size := frames * w.channels * w.bytesPerSample
+	if cap(w.buffer) < size {
+		w.buffer = make([]byte, size)
+	}
+
+	for i := range frames * w.channels {
+		encodeSample(w.format, w.buffer[i*w.bytesPerSample:(i+1)*w.bytesPerSample], samples[i])
+	}
+
+	n, err := w.writer.Write(w.buffer[:size])
+	if err != nil {
+		return n / (w.channels * w.bytesPerSample), err
+	} else if n != size {
+		return n / (w.channels * w.bytesPerSample), io.ErrShortWrite
+	}
+
+	return frames, nil
+}
+
+func decodeSample(format uint16, data []byte) float64 {
+	switch format {
+	case FormatIEEEFloat:
+		if len(data) == 8 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(data))
+		}
+
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+	case FormatALaw:
+		return decodeALaw(data[0])
+	case FormatMuLaw:
+		return decodeMuLaw(data[0])
+	}
+
+	switch len(data) {
+	case 1:
+		// 8 bit samples are unsigned
+		return float64(int(data[0])-128) / (1 << 7)
+	case 2:
+		return float64(int16(binary.LittleEndian.Uint16(data))) / (1 << 15)
+	case 3:
+		return float64(int32(uint32(data[0])<<8|uint32(data[1])<<16|uint32(data[2])<<24)>>8) / (1 << 23)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(data))) / (1 << 31)
+	}
+}
+
+func encodeSample(format uint16, data []byte, sample float64) {
+	sample = max(-1, min(1, sample))
+
+	switch format {
+	case FormatIEEEFloat:
+		if len(data) == 8 {
+			binary.LittleEndian.PutUint64(data, math.Float64bits(sample))
+		} else {
+			binary.LittleEndian.PutUint32(data, math.Float32bits(float32(sample)))
+		}
+
+		return
+	case FormatALaw:
+		data[0] = encodeALaw(int16(quantize(sample, 16)))
+		return
+	case FormatMuLaw:
+		data[0] = encodeMuLaw(int16(quantize(sample, 16)))
+		return
+	}
+
+	switch len(data) {
+	case 1:
+		data[0] = byte(quantize(sample, 8) + 128)
+	case 2:
+		binary.LittleEndian.PutUint16(data, uint16(int16(quantize(sample, 16))))
+	case 3:
+		value := uint32(int32(quantize(sample, 24)))
+		data[0] = byte(value)
+		data[1] = byte(value >> 8)
+		data[2] = byte(value >> 16)
+	default:
+		binary.LittleEndian.PutUint32(data, uint32(int32(quantize(sample, 32))))
+	}
+}
+
+// quantize rounds a sample in the range [-1, 1] to a signed integer of the
+// given bit depth, saturating at the positive limit.
+func quantize(sample float64, bits int) int64 {
+	scale := float64(int64(1) << (bits - 1))
+	return int64(max(-scale, min(scale-1, math.Round(sample*scale))))
+}
+
+// ITU-T G.711 A-law expansion.
+func decodeALaw(value byte) float64 {
+	value ^= 0x55
+
+	exponent := int(value>>4) & 0x07
+	mantissa := int(value & 0x0F)
+
+	magnitude := mantissa<<4 + 8
+	if exponent > 0 {
+		magnitude = (magnitude + 0x100) << (exponent - 1)
+	}
+
+	if value&0x80 == 0 {
+		magnitude = -magnitude
+	}
+
+	return float64(magnitude) / (1 << 15)
+}
+
+// ITU-T G.711 A-law compression.
+func encodeALaw(sample int16) byte {
+	sign := byte(0x80)
+	magnitude := int(sample)
+
+	if magnitude < 0 {
+		sign = 0
+		magnitude = -magnitude - 1
+	}
+
+	magnitude = min(magnitude, 0x7FFF) >> 3
+
+	var value byte
+	if magnitude < 0x20 {
+		value = byte(magnitude >> 1)
+	} else {
+		exponent := 1
+		for magnitude >= 0x40 {
+			magnitude >>= 1
+			exponent++
+		}
+
+		value = byte(exponent<<4) | byte(magnitude>>1&0x0F)
+	}
+
+	return (value | sign) ^ 0x55
+}
+
+const muLawBias = 0x84
+
+// ITU-T G.711 mu-law expansion.
+func decodeMuLaw(value byte) float64 {
+	value = ^value
+
+	exponent := int(value>>4) & 0x07
+	mantissa := int(value & 0x0F)
+
+	magnitude := (mantissa<<3+muLawBias)<<exponent - muLawBias
+
+	if value&0x80 != 0 {
+		magnitude = -magnitude
+	}
+
+	return float64(magnitude) / (1 << 15)
+}
+
+// ITU-T G.711 mu-law compression.
+func encodeMuLaw(sample int16) byte {
+	var sign byte
+	magnitude := int(sample)
+
+	if magnitude < 0 {
+		sign = 0x80
+		magnitude = -magnitude
+	}
+
+	magnitude = min(magnitude, 0x7FFF-muLawBias) + muLawBias
+
+	exponent := 7
+	for mask := 0x4000; magnitude&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+
+	mantissa := byte(magnitude>>(exponent+3)) & 0x0F
+
+	return ^(sign | byte(exponent<<4) | mantissa)
+}
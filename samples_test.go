@@ -0,0 +1,71 @@
+package wav_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestSampleRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name      string
+		format    uint16
+		bitDepth  int
+		tolerance float64
+	}{
+		{name: "pcm 8 bit", format: wav.FormatPCM, bitDepth: 8, tolerance: 1.0 / (1 << 7)},
+		{name: "pcm 16 bit", format: wav.FormatPCM, bitDepth: 16, tolerance: 1.0 / (1 << 15)},
+		{name: "pcm 24 bit", format: wav.FormatPCM, bitDepth: 24, tolerance: 1.0 / (1 << 23)},
+		{name: "pcm 32 bit", format: wav.FormatPCM, bitDepth: 32, tolerance: 1.0 / (1 << 31)},
+		{name: "float 32 bit", format: wav.FormatIEEEFloat, bitDepth: 32, tolerance: 1e-7},
+		{name: "float 64 bit", format: wav.FormatIEEEFloat, bitDepth: 64},
+		{name: "a-law", format: wav.FormatALaw, bitDepth: 8, tolerance: 1.0 / (1 << 5)},
+		{name: "mu-law", format: wav.FormatMuLaw, bitDepth: 8, tolerance: 1.0 / (1 << 5)},
+	}
+
+	samples := []float64{0, 0.5, -0.5, 0.001, -0.001, 0.9, -1, 0.25}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			format := wav.FormatChunk{
+				Format:        [2]byte{byte(testCase.format), byte(testCase.format >> 8)},
+				NumChannels:   [2]byte{2, 0},
+				BlockAlign:    [2]byte{byte(2 * testCase.bitDepth / 8), 0},
+				BitsPerSample: [2]byte{byte(testCase.bitDepth), 0},
+			}
+
+			buffer := new(bytes.Buffer)
+
+			writer, err := wav.NewSampleWriter(buffer, format)
+			if err != nil {
+				t.Fatalf("creating sample writer: %s", err.Error())
+			}
+
+			if _, err := writer.WriteFrames(samples); err != nil {
+				t.Fatalf("writing frames: %s", err.Error())
+			}
+
+			reader, err := wav.NewSampleReader(buffer, format)
+			if err != nil {
+				t.Fatalf("creating sample reader: %s", err.Error())
+			}
+
+			decoded := make([]float64, len(samples))
+
+			frames, err := reader.ReadFrames(decoded)
+			if err != nil {
+				t.Fatalf("reading frames: %s", err.Error())
+			} else if frames != len(samples)/2 {
+				t.Fatalf("frames: got %d, want %d", frames, len(samples)/2)
+			}
+
+			for i := range samples {
+				if math.Abs(decoded[i]-samples[i]) > testCase.tolerance*math.Max(1, math.Abs(samples[i])*8) {
+					t.Errorf("sample %d: got %f, want %f", i, decoded[i], samples[i])
+				}
+			}
+		})
+	}
+}
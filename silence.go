@@ -0,0 +1,109 @@
+package wav
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// FrameRange is a range of sample frames, from Start up to but not including End.
+type FrameRange struct {
+	Start int
+	End   int
+}
+
+// Frames returns the number of frames in the range.
+func (r FrameRange) Frames() int {
+	return r.End - r.Start
+}
+
+// SilenceConfig configures silence detection.
+type SilenceConfig struct {
+	// Threshold is the level in dBFS below which a frame is silent in all
+	// channels.
+	Threshold float64
+
+	// MinDuration is the shortest duration of a reported silent region.
+	MinDuration time.Duration
+
+	// Hold is the duration of silence kept next to non-silent audio, so
+	// that decays and breaths are not cut off.
+	Hold time.Duration
+}
+
+// DetectSilence returns the silent regions of the audio data in order.
+func (f *WAVEFileFormat) DetectSilence(cfg SilenceConfig) ([]FrameRange, error) {
+	sampleRate := float64(binary.LittleEndian.Uint32(f.FormatChunk.SampleRate[:]))
+	threshold := math.Pow(10, cfg.Threshold/20)
+	minFrames := max(1, int(cfg.MinDuration.Seconds()*sampleRate))
+	holdFrames := int(cfg.Hold.Seconds() * sampleRate)
+
+	var regions []FrameRange
+
+	start := -1
+	frames := 0
+
+	addRegion := func(region FrameRange) {
+		// Hold silence next to non-silent audio
+		if region.Start > 0 {
+			region.Start += holdFrames
+		}
+
+		if region.End < frames {
+			region.End -= holdFrames
+		}
+
+		if region.Frames() >= minFrames {
+			regions = append(regions, region)
+		}
+	}
+
+	if err := f.forEachFrame(nil, func(frame int, samples []float64) {
+		silent := true
+
+		for _, sample := range samples {
+			if math.Abs(sample) >= threshold {
+				silent = false
+				break
+			}
+		}
+
+		frames = frame + 1
+
+		if silent && start < 0 {
+			start = frame
+		} else if !silent && start >= 0 {
+			addRegion(FrameRange{Start: start, End: frame})
+			start = -1
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	if start >= 0 {
+		addRegion(FrameRange{Start: start, End: frames})
+	}
+
+	return regions, nil
+}
+
+// Trim returns a copy of the file, including its metadata sub-chunks, with
+// the leading and trailing silence removed, as with Slice.
+func (f *WAVEFileFormat) Trim(cfg SilenceConfig) (*WAVEFileFormat, error) {
+	regions, err := f.DetectSilence(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	audio := FrameRange{Start: 0, End: f.Frames()}
+
+	if len(regions) > 0 && regions[0].Start == 0 {
+		audio.Start = regions[0].End
+	}
+
+	if len(regions) > 0 && regions[len(regions)-1].End == audio.End {
+		audio.End = max(audio.Start, regions[len(regions)-1].Start)
+	}
+
+	return f.Slice(audio.Start, audio.End)
+}
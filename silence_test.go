@@ -0,0 +1,62 @@
+package wav_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/samborkent/wav"
+)
+
+func TestSilence(t *testing.T) {
+	const sampleRate = 8000
+
+	// 0.5 s silence, 1 s tone, 0.25 s silence, 0.5 s tone, 0.5 s silence
+	data := slices.Concat(
+		make([]byte, 4*sampleRate/2),
+		sine(1, sampleRate, 440, 0.5, 1),
+		make([]byte, 4*sampleRate/4),
+		sine(1, sampleRate, 440, 0.5, 0.5),
+		make([]byte, 4*sampleRate/2),
+	)
+
+	waveFile, err := wav.New(wav.Config{Channels: 1, SampleRate: sampleRate, BitDepth: 32, FloatingPoint: true}, data)
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	config := wav.SilenceConfig{
+		Threshold:   -60,
+		MinDuration: 200 * time.Millisecond,
+		Hold:        10 * time.Millisecond,
+	}
+
+	regions, err := waveFile.DetectSilence(config)
+	if err != nil {
+		t.Fatalf("detecting silence: %s", err.Error())
+	}
+
+	// Sine starts at zero crossings, which count as silence too
+	want := []wav.FrameRange{
+		{Start: 0, End: 4001 - 80},
+		{Start: 12000 + 80, End: 14001 - 80},
+		{Start: 18000 + 80, End: 22000},
+	}
+
+	if !slices.Equal(regions, want) {
+		t.Errorf("silent regions: got %v, want %v", regions, want)
+	}
+
+	trimmed, err := waveFile.Trim(config)
+	if err != nil {
+		t.Fatalf("trimming: %s", err.Error())
+	}
+
+	if got := trimmed.Frames(); got != want[2].Start-want[0].End {
+		t.Errorf("trimmed frames: got %d, want %d", got, want[2].Start-want[0].End)
+	}
+
+	if trimmed.DataSize() != len(trimmed.Data()) || trimmed.Size() != 4+(8+wav.FormatChunkSizeNonPCM)+(8+wav.FactChunkSize)+(8+trimmed.DataSize()) {
+		t.Errorf("trimmed sizes not recalculated: data size %d, riff size %d", trimmed.DataSize(), trimmed.Size())
+	}
+}
@@ -0,0 +1,343 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// Window is a window function applied to each STFT frame.
+type Window int
+
+const (
+	WindowHann Window = iota
+	WindowHamming
+	WindowBlackman
+	WindowRectangular
+)
+
+var ErrSTFTConfig = errors.New("FFT size must be a power of two and hop size positive")
+
+// STFTConfig configures a short-time Fourier transform.
+type STFTConfig struct {
+	FFTSize int // Frames per transform, a power of two
+	Hop     int // Frames between transforms, defaults to a quarter of FFTSize
+	Window  Window
+}
+
+// Spectrogram holds the magnitude spectra of consecutive STFT frames.
+type Spectrogram struct {
+	SampleRate int
+	FFTSize    int
+	Hop        int
+
+	// Spectra holds the magnitude of each frequency bin from zero up to the
+	// Nyquist frequency in dBFS, where a full scale sine is 0 dBFS.
+	Spectra [][]float64
+}
+
+// BinFrequency returns the center frequency of a bin in Hz.
+func (s *Spectrogram) BinFrequency(bin int) float64 {
+	return float64(bin) * float64(s.SampleRate) / float64(s.FFTSize)
+}
+
+// ComputeSpectrogram decodes the header of the WAV file read from reader and
+// streams over the audio data to compute the spectrogram of the average of
+// all channels.
+func ComputeSpectrogram(reader io.Reader, cfg STFTConfig) (*Spectrogram, error) {
+	file, samples, err := DecodeSamples(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return computeSpectrogram(samples, file.FormatChunk, cfg)
+}
+
+// Spectrogram computes the spectrogram of the average of all channels of the
+// decoded audio data.
+func (f *WAVEFileFormat) Spectrogram(cfg STFTConfig) (*Spectrogram, error) {
+	samples, err := f.SampleReader()
+	if err != nil {
+		return nil, err
+	}
+
+	return computeSpectrogram(samples, f.FormatChunk, cfg)
+}
+
+func computeSpectrogram(samples *SampleReader, format FormatChunk, cfg STFTConfig) (*Spectrogram, error) {
+	stft, err := NewSTFT(format, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := make([]float64, analysisFrames*samples.Channels())
+
+	for {
+		frames, err := samples.ReadFrames(buffer)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		stft.WriteFrames(buffer[:frames*samples.Channels()])
+	}
+
+	return stft.Spectrogram(), nil
+}
+
+// STFT computes a short-time Fourier transform of the average of all
+// channels of interleaved samples incrementally.
+type STFT struct {
+	channels    int
+	window      []float64
+	spectrogram Spectrogram
+
+	buffer  []float64 // Mono samples not yet transformed
+	scratch []complex128
+}
+
+// NewSTFT returns an STFT for samples of the given format.
+func NewSTFT(format FormatChunk, cfg STFTConfig) (*STFT, error) {
+	if _, err := format.validateSampleFormat(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Hop == 0 {
+		cfg.Hop = cfg.FFTSize / 4
+	}
+
+	if cfg.FFTSize < 2 || bits.OnesCount(uint(cfg.FFTSize)) != 1 || cfg.Hop <= 0 {
+		return nil, fmt.Errorf("%w: FFT size %d, hop size %d", ErrSTFTConfig, cfg.FFTSize, cfg.Hop)
+	}
+
+	window := make([]float64, cfg.FFTSize)
+	sum := 0.0
+
+	for i := range window {
+		x := 2 * math.Pi * float64(i) / float64(cfg.FFTSize)
+
+		switch cfg.Window {
+		case WindowHann:
+			window[i] = 0.5 - 0.5*math.Cos(x)
+		case WindowHamming:
+			window[i] = 0.54 - 0.46*math.Cos(x)
+		case WindowBlackman:
+			window[i] = 0.42 - 0.5*math.Cos(x) + 0.08*math.Cos(2*x)
+		default:
+			window[i] = 1
+		}
+
+		sum += window[i]
+	}
+
+	// A full scale sine peaks at half the window sum in its bin
+	for i := range window {
+		window[i] *= 2 / sum
+	}
+
+	return &STFT{
+		channels: int(binary.LittleEndian.Uint16(format.NumChannels[:])),
+		window:   window,
+		spectrogram: Spectrogram{
+			SampleRate: int(binary.LittleEndian.Uint32(format.SampleRate[:])),
+			FFTSize:    cfg.FFTSize,
+			Hop:        cfg.Hop,
+		},
+		scratch: make([]complex128, cfg.FFTSize),
+	}, nil
+}
+
+// WriteFrames processes whole frames of interleaved samples.
+func (s *STFT) WriteFrames(samples []float64) {
+	for frame := range len(samples) / s.channels {
+		var sum float64
+
+		for _, sample := range samples[frame*s.channels : (frame+1)*s.channels] {
+			sum += sample
+		}
+
+		s.buffer = append(s.buffer, sum/float64(s.channels))
+	}
+
+	size, hop := s.spectrogram.FFTSize, s.spectrogram.Hop
+	consumed := 0
+
+	for ; consumed+size <= len(s.buffer); consumed += hop {
+		s.transform(s.buffer[consumed : consumed+size])
+	}
+
+	s.buffer = s.buffer[:copy(s.buffer, s.buffer[min(consumed, len(s.buffer)):])]
+}
+
+// Spectrogram returns the spectra of all complete frames written so far.
+func (s *STFT) Spectrogram() *Spectrogram {
+	spectrogram := s.spectrogram
+	spectrogram.Spectra = append([][]float64(nil), s.spectrogram.Spectra...)
+
+	return &spectrogram
+}
+
+func (s *STFT) transform(samples []float64) {
+	for i, sample := range samples {
+		s.scratch[i] = complex(sample*s.window[i], 0)
+	}
+
+	fft(s.scratch)
+
+	spectrum := make([]float64, len(samples)/2+1)
+
+	for bin := range spectrum {
+		magnitude := cmplx.Abs(s.scratch[bin])
+
+		// Bins at zero and the Nyquist frequency have no mirror image
+		if bin == 0 || bin == len(samples)/2 {
+			magnitude /= 2
+		}
+
+		spectrum[bin] = 20 * math.Log10(magnitude)
+	}
+
+	s.spectrogram.Spectra = append(s.spectrogram.Spectra, spectrum)
+}
+
+// fft computes the discrete Fourier transform in place with the iterative
+// radix-2 Cooley-Tukey algorithm. The length must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	shift := bits.UintSize - bits.Len(uint(n-1))
+
+	for i := range x {
+		if j := int(bits.Reverse(uint(i)) >> shift); i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size *= 2 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+
+		for start := 0; start < n; start += size {
+			twiddle := complex(1, 0)
+
+			for i := range size / 2 {
+				even, odd := x[start+i], twiddle*x[start+i+size/2]
+				x[start+i] = even + odd
+				x[start+i+size/2] = even - odd
+				twiddle *= step
+			}
+		}
+	}
+}
+
+// SpectrogramImageConfig configures the rendering of a spectrogram.
+type SpectrogramImageConfig struct {
+	Height       int     // Pixels, defaults to the number of bins
+	LogFrequency bool    // Logarithmic instead of linear frequency axis
+	MinFrequency float64 // Lowest frequency of a logarithmic axis, defaults to 20 Hz
+	Floor        float64 // Level in dBFS drawn black, defaults to -120 dBFS
+}
+
+// Image renders the spectrogram with time from left to right, one pixel per
+// STFT frame, and frequency from bottom to top. Levels from the floor up to
+// 0 dBFS are mapped onto a black, purple, orange and white color scale.
+func (s *Spectrogram) Image(cfg SpectrogramImageConfig) *image.RGBA {
+	bins := s.FFTSize/2 + 1
+	nyquist := float64(s.SampleRate) / 2
+
+	if cfg.Height <= 0 {
+		cfg.Height = bins
+	}
+
+	if cfg.MinFrequency <= 0 {
+		cfg.MinFrequency = min(20, nyquist/2)
+	}
+
+	if cfg.Floor >= 0 {
+		cfg.Floor = -120
+	}
+
+	// Frequency at the bottom edge of each row, from the bottom row up
+	frequency := func(edge int) float64 {
+		position := float64(edge) / float64(cfg.Height)
+
+		if cfg.LogFrequency {
+			return cfg.MinFrequency * math.Pow(nyquist/cfg.MinFrequency, position)
+		}
+
+		return position * nyquist
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, len(s.Spectra), cfg.Height))
+	binWidth := nyquist / float64(bins-1)
+
+	for row := range cfg.Height {
+		low, high := frequency(row)/binWidth, frequency(row+1)/binWidth
+		y := cfg.Height - 1 - row
+
+		for x, spectrum := range s.Spectra {
+			level := math.Inf(-1)
+
+			// Highest bin within the row, or interpolated at its center
+			for bin := int(math.Ceil(low)); bin <= int(high) && bin < bins; bin++ {
+				level = max(level, spectrum[bin])
+			}
+
+			if math.IsInf(level, -1) {
+				center := min((low+high)/2, float64(bins-1))
+				bin := min(int(center), bins-2)
+				fraction := center - float64(bin)
+				level = spectrum[bin]*(1-fraction) + spectrum[bin+1]*fraction
+			}
+
+			img.SetRGBA(x, y, spectrogramColor((level-cfg.Floor)/-cfg.Floor))
+		}
+	}
+
+	return img
+}
+
+// EncodePNG renders the spectrogram as with Image and writes it as PNG.
+func (s *Spectrogram) EncodePNG(writer io.Writer, cfg SpectrogramImageConfig) error {
+	if err := png.Encode(writer, s.Image(cfg)); err != nil {
+		return fmt.Errorf("writing spectrogram: %w", err)
+	}
+
+	return nil
+}
+
+// spectrogramColors is the color scale from low to high levels.
+var spectrogramColors = []color.RGBA{
+	{0, 0, 0, 255},
+	{40, 10, 90, 255},
+	{140, 30, 120, 255},
+	{230, 90, 50, 255},
+	{250, 200, 60, 255},
+	{255, 255, 255, 255},
+}
+
+// spectrogramColor returns the color of a level between zero and one.
+func spectrogramColor(level float64) color.RGBA {
+	if math.IsNaN(level) || level <= 0 {
+		return spectrogramColors[0]
+	} else if level >= 1 {
+		return spectrogramColors[len(spectrogramColors)-1]
+	}
+
+	position := level * float64(len(spectrogramColors)-1)
+	index := int(position)
+	fraction := position - float64(index)
+	a, b := spectrogramColors[index], spectrogramColors[index+1]
+
+	blend := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a)*(1-fraction) + float64(b)*fraction))
+	}
+
+	return color.RGBA{blend(a.R, b.R), blend(a.G, b.G), blend(a.B, b.B), 255}
+}
@@ -0,0 +1,82 @@
+package wav_test
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestSpectrogram(t *testing.T) {
+	// 1 kHz sine at -6 dBFS, centered on bin 32
+	waveFile, err := wav.New(wav.Config{Channels: 2, SampleRate: 16000, BitDepth: 32, FloatingPoint: true}, sine(2, 16000, 1000, 0.5, 1))
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	spectrogram, err := waveFile.Spectrogram(wav.STFTConfig{FFTSize: 512, Hop: 256})
+	if err != nil {
+		t.Fatalf("computing spectrogram: %s", err.Error())
+	}
+
+	if len(spectrogram.Spectra) != (16000-512)/256+1 {
+		t.Errorf("spectra: got %d, want %d", len(spectrogram.Spectra), (16000-512)/256+1)
+	}
+
+	spectrum := spectrogram.Spectra[10]
+
+	if len(spectrum) != 257 || spectrogram.BinFrequency(32) != 1000 {
+		t.Fatalf("bins: got %d, want 257", len(spectrum))
+	}
+
+	if math.Abs(spectrum[32]+6.02) > 0.05 {
+		t.Errorf("level at 1 kHz: got %f dBFS, want -6.02 dBFS", spectrum[32])
+	}
+
+	if spectrum[100] > -100 {
+		t.Errorf("level at %f Hz: got %f dBFS, want below -100 dBFS", spectrogram.BinFrequency(100), spectrum[100])
+	}
+
+	for _, logFrequency := range []bool{false, true} {
+		img := spectrogram.Image(wav.SpectrogramImageConfig{Height: 100, LogFrequency: logFrequency})
+
+		if bounds := img.Bounds(); bounds.Dx() != len(spectrogram.Spectra) || bounds.Dy() != 100 {
+			t.Fatalf("image size: got %v", bounds)
+		}
+
+		// Brightest row of a column is at 1 kHz
+		brightest, row := 0, 0
+
+		for y := range 100 {
+			if c := img.RGBAAt(10, y); int(c.R)+int(c.G)+int(c.B) > brightest {
+				brightest, row = int(c.R)+int(c.G)+int(c.B), y
+			}
+		}
+
+		want := 100 - 1 - 1000*100/8000
+		if logFrequency {
+			want = 100 - 1 - int(math.Log(1000.0/20)/math.Log(8000.0/20)*100)
+		}
+
+		if row < want-1 || row > want+1 {
+			t.Errorf("brightest row with log frequency %t: got %d, want %d", logFrequency, row, want)
+		}
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := spectrogram.EncodePNG(encoded, wav.SpectrogramImageConfig{}); err != nil {
+		t.Fatalf("encoding png: %s", err.Error())
+	}
+
+	if _, err := png.Decode(encoded); err != nil {
+		t.Errorf("decoding png: %s", err.Error())
+	}
+
+	if _, err := waveFile.Spectrogram(wav.STFTConfig{FFTSize: 500}); !errors.Is(err, wav.ErrSTFTConfig) {
+		t.Errorf("fft size of 500: got %v, want %v", err, wav.ErrSTFTConfig)
+	}
+}
@@ -0,0 +1,161 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var ErrWriterClosed = errors.New("writer is closed")
+
+// DecodeSamples decodes the header of the WAV file read from reader and
+// returns it together with a SampleReader that streams the audio data.
+func DecodeSamples(reader io.Reader) (*WAVEFileFormat, *SampleReader, error) {
+	file := &WAVEFileFormat{}
+
+	if err := file.DecodeHeader(reader); err != nil {
+		return nil, nil, err
+	}
+
+	samples, err := NewSampleReader(io.LimitReader(reader, int64(file.DataSize())), file.FormatChunk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, samples, nil
+}
+
+// Writer streams samples into a WAV file of unknown length. The header is
+// written up front and its sizes are updated when the Writer is closed.
+type Writer struct {
+	writer  io.WriteSeeker
+	samples *SampleWriter
+
+	start      int64 // Position of the RIFF chunk
+	header     int64 // Size of all chunks preceding the audio data
+	factOffset int64 // Offset of the fact sample length, if any
+	blockAlign int64
+	frames     int64
+	closed     bool
+}
+
+// NewWriter writes the header to writer, including the sub-chunks, and
+// returns a Writer for audio data in the format of header. Audio data of the
+// header itself is not written.
+func NewWriter(writer io.WriteSeeker, header *WAVEFileFormat) (*Writer, error) {
+	samples, err := NewSampleWriter(writer, header.FormatChunk)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := writer.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("seeking: %w", err)
+	}
+
+	file := *header
+	file.DataChunk = DataChunk{
+		Chunk: Chunk{
+			ID: [4]byte{'d', 'a', 't', 'a'},
+		},
+	}
+
+	if err := file.updateSize(); err != nil {
+		return nil, err
+	}
+
+	if err := file.Encode(writer); err != nil {
+		return nil, err
+	}
+
+	w := &Writer{
+		writer:     writer,
+		samples:    samples,
+		start:      start,
+		header:     int64(file.Size()) + 8,
+		factOffset: -1,
+		blockAlign: int64(binary.LittleEndian.Uint16(header.FormatChunk.BlockAlign[:])),
+	}
+
+	if file.FactChunk.Chunk.ID == [4]byte{'f', 'a', 'c', 't'} {
+		formatSize := int64(binary.LittleEndian.Uint32(file.FormatChunk.Chunk.Size[:]))
+		w.factOffset = 12 + 8 + formatSize + formatSize%2 + 8
+	}
+
+	return w, nil
+}
+
+// WriteFrames encodes whole frames of interleaved samples, as with
+// SampleWriter.
+func (w *Writer) WriteFrames(samples []float64) (int, error) {
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+
+	frames := int64(len(samples) / w.samples.Channels())
+
+	if w.header+(w.frames+frames)*w.blockAlign-8 > math.MaxUint32-1 {
+		return 0, ErrDataTooLarge
+	}
+
+	n, err := w.samples.WriteFrames(samples)
+	w.frames += int64(n)
+
+	return n, err
+}
+
+// Frames returns the number of frames written.
+func (w *Writer) Frames() int {
+	return int(w.frames)
+}
+
+// Close writes the padding byte and updates the sizes in the header. The
+// underlying writer is not closed.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+
+	dataSize := w.frames * w.blockAlign
+
+	if dataSize%2 != 0 {
+		if _, err := w.writer.Write([]byte{0}); err != nil {
+			return fmt.Errorf("writing data sub-chunk: padding byte: %w", err)
+		}
+	}
+
+	end := w.start + w.header + dataSize + dataSize%2
+
+	if err := w.patch(4, uint32(end-w.start-8)); err != nil {
+		return fmt.Errorf("writing riff chunk: size: %w", err)
+	}
+
+	if err := w.patch(w.header-4, uint32(dataSize)); err != nil {
+		return fmt.Errorf("writing data sub-chunk: size: %w", err)
+	}
+
+	if w.factOffset >= 0 {
+		if err := w.patch(w.factOffset, uint32(w.frames)); err != nil {
+			return fmt.Errorf("writing fact sub-chunk: sample length: %w", err)
+		}
+	}
+
+	if _, err := w.writer.Seek(end, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking: %w", err)
+	}
+
+	return nil
+}
+
+// patch overwrites the little endian value at offset from the start of the
+// RIFF chunk.
+func (w *Writer) patch(offset int64, value uint32) error {
+	var data [4]byte
+	binary.LittleEndian.PutUint32(data[:], value)
+
+	return writeAt(w.writer, w.start+offset, data[:])
+}
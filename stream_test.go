@@ -0,0 +1,91 @@
+package wav_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestWriter(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "stream.wav"))
+	if err != nil {
+		t.Fatalf("creating file: %s", err.Error())
+	}
+	defer file.Close()
+
+	header, err := wav.New(wav.Config{Channels: 1, SampleRate: 8000, BitDepth: 8, Format: wav.FormatMuLaw}, nil)
+	if err != nil {
+		t.Fatalf("creating header: %s", err.Error())
+	}
+
+	if err := header.SetBext(&wav.BextChunk{Description: "streamed"}); err != nil {
+		t.Fatalf("setting bext sub-chunk: %s", err.Error())
+	}
+
+	writer, err := wav.NewWriter(file, header)
+	if err != nil {
+		t.Fatalf("creating writer: %s", err.Error())
+	}
+
+	samples := []float64{0, 0.25, 0.5, -0.5, -0.25}
+
+	// Odd number of frames in several writes
+	for range 3 {
+		if _, err := writer.WriteFrames(samples); err != nil {
+			t.Fatalf("writing frames: %s", err.Error())
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("closing writer: %s", err.Error())
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("seeking: %s", err.Error())
+	}
+
+	decoded, reader, err := wav.DecodeSamples(file)
+	if err != nil {
+		t.Fatalf("decoding samples: %s", err.Error())
+	}
+
+	if decoded.Frames() != 3*len(samples) || binary.LittleEndian.Uint32(decoded.FactChunk.SampleLength[:]) != uint32(3*len(samples)) {
+		t.Errorf("frames: got %d, want %d", decoded.Frames(), 3*len(samples))
+	}
+
+	if bext, err := decoded.Bext(); err != nil || bext.Description != "streamed" {
+		t.Errorf("bext: got %+v and error %v, want description %q", bext, err, "streamed")
+	}
+
+	buffer := make([]float64, 64)
+
+	frames, err := reader.ReadFrames(buffer)
+	if err != nil {
+		t.Fatalf("reading frames: %s", err.Error())
+	}
+
+	for i := range frames {
+		if math.Abs(buffer[i]-samples[i%len(samples)]) > 0.02 {
+			t.Errorf("sample %d: got %f, want %f", i, buffer[i], samples[i%len(samples)])
+		}
+	}
+
+	if _, err := reader.ReadFrames(buffer); !errors.Is(err, io.EOF) {
+		t.Errorf("reading beyond audio data: got %v, want %v", err, io.EOF)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		t.Fatalf("stat: %s", err.Error())
+	}
+
+	if stat.Size() != int64(decoded.Size())+8 {
+		t.Errorf("file size: got %d, want riff size %d plus 8", stat.Size(), decoded.Size())
+	}
+}
@@ -17,17 +17,23 @@ const (
 )
 
 const (
-	FormatUnknown    = 0x0000
-	FormatPCM        = 0x0001
-	FormatIEEEFloat  = 0x0003
-	FormatALaw       = 0x0006
-	FormatMuLaw      = 0x0007
-	FormatMP3        = 0x0055
-	FormatAAC        = 0x00FF
-	FormatOpus       = 0x704F
-	FormatMPEG4      = 0xA106
-	FormatFLAC       = 0xF1AC
-	FormatExtensible = 0xFFFE
+	FormatUnknown       = 0x0000
+	FormatPCM           = 0x0001
+	FormatADPCM         = 0x0002
+	FormatIEEEFloat     = 0x0003
+	FormatALaw          = 0x0006
+	FormatMuLaw         = 0x0007
+	FormatDTS           = 0x0008
+	FormatDRM           = 0x0009
+	FormatMPEG          = 0x0050
+	FormatMP3           = 0x0055
+	FormatDolbyAC3SPDIF = 0x0092
+	FormatAAC           = 0x00FF
+	FormatHEAAC         = 0x1610
+	FormatOpus          = 0x704F
+	FormatMPEG4         = 0xA106
+	FormatFLAC          = 0xF1AC
+	FormatExtensible    = 0xFFFE
 )
 
 const (
@@ -35,6 +41,28 @@ const (
 	ExtensionSizeExtensible = 22
 )
 
+// Speaker positions of the extensible format channel mask
+const (
+	SpeakerFrontLeft          = 0x00001
+	SpeakerFrontRight         = 0x00002
+	SpeakerFrontCenter        = 0x00004
+	SpeakerLowFrequency       = 0x00008
+	SpeakerBackLeft           = 0x00010
+	SpeakerBackRight          = 0x00020
+	SpeakerFrontLeftOfCenter  = 0x00040
+	SpeakerFrontRightOfCenter = 0x00080
+	SpeakerBackCenter         = 0x00100
+	SpeakerSideLeft           = 0x00200
+	SpeakerSideRight          = 0x00400
+	SpeakerTopCenter          = 0x00800
+	SpeakerTopFrontLeft       = 0x01000
+	SpeakerTopFrontCenter     = 0x02000
+	SpeakerTopFrontRight      = 0x04000
+	SpeakerTopBackLeft        = 0x08000
+	SpeakerTopBackCenter      = 0x10000
+	SpeakerTopBackRight       = 0x20000
+)
+
 var (
 	ErrDataTooLarge      = errors.New("data exceeds wav length limit of 4 GiB")
 	ErrBitDepthTooHigh   = errors.New("bit depth exceeds wav limit of 2^16")
@@ -54,7 +82,7 @@ var (
 	ErrDecodeFormatBitsPerSample      = errors.New("format sub-chunk bits per sample must be divisible by 8")
 	ErrDecodeFormatExtensionSize      = errors.New("format sub-chunk extension size invalid for this format type")
 	ErrDecodeFormatValidBitsPerSample = errors.New("format sub-chunk valid bits per sample cannot exceed bits per sample")
-	ErrDecodeFormatSubFormat          = errors.New("format sub-chunk sub-format first two bytes do not match format")
+	ErrDecodeFormatSubFormat          = errors.New("format sub-chunk sub-format is not a known guid")
 	ErrDecodeFactID                   = errors.New("fact sub-chunk id does not match 'fact'")
 	ErrDecodeFactSize                 = errors.New("fact sub-chunk size must be 4 bytes")
 	ErrDecodeDataID                   = errors.New("data sub-chunk id does not match 'data'")
@@ -65,6 +93,7 @@ type WAVEFileFormat struct {
 	FormatChunk
 	FactChunk // Optional
 	DataChunk
+	SubChunks []SubChunk // Optional, e.g. metadata
 }
 
 type Chunk struct {
@@ -79,16 +108,20 @@ type RIFFChunk struct {
 
 type FormatChunk struct {
 	Chunk
-	Format             [2]byte  // Little endian
-	NumChannels        [2]byte  // Little endian
-	SampleRate         [4]byte  // Little endian
-	ByteRate           [4]byte  // Little endian
-	BlockAlign         [2]byte  // Little endian
-	BitsPerSample      [2]byte  // Little endian
-	ExtensionSize      [2]byte  // Little endian, optional
-	ValidBitsPerSample [2]byte  // Little endian, optional
-	ChannelMask        [4]byte  // Little endian, optional
-	SubFormat          [16]byte // Big endian, optional
+	Format             [2]byte // Little endian
+	NumChannels        [2]byte // Little endian
+	SampleRate         [4]byte // Little endian
+	ByteRate           [4]byte // Little endian
+	BlockAlign         [2]byte // Little endian
+	BitsPerSample      [2]byte // Little endian
+	ExtensionSize      [2]byte // Little endian, optional
+	ValidBitsPerSample [2]byte // Little endian, optional
+	ChannelMask        [4]byte // Little endian, optional
+	SubFormat          GUID    // Optional
+
+	// Codec-specific extension of non-PCM formats, optional. Stored as a
+	// string to keep the format sub-chunk comparable.
+	extension string
 }
 
 type FactChunk struct {
@@ -107,116 +140,178 @@ type Config struct {
 	SampleRate    int
 	BitDepth      int
 	FloatingPoint bool
+	Format        uint16 // Optional, e.g. FormatALaw, overrides FloatingPoint
+	ChannelMask   uint32 // Optional speaker positions, selects the extensible format
+	Ambisonic     bool   // Optional, selects the extensible format with ambisonic B-format
 }
 
-// TODO: implement extension
+// New returns a WAV file holding the audio data in the format of cfg.
 func New(cfg Config, data []byte) (*WAVEFileFormat, error) {
+	formatChunk, err := NewFormatChunk(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data)+36 > math.MaxUint32 {
+		return nil, ErrDataTooLarge
+	}
+
+	var dataChunkSize [4]byte
+
+	binary.LittleEndian.PutUint32(dataChunkSize[:], uint32(len(data)))
+
+	file := &WAVEFileFormat{
+		RIFFChunk: RIFFChunk{
+			Chunk: Chunk{
+				ID: [4]byte{'R', 'I', 'F', 'F'},
+			},
+			Identifier: [4]byte{'W', 'A', 'V', 'E'},
+		},
+		FormatChunk: formatChunk,
+		DataChunk: DataChunk{
+			Chunk: Chunk{
+				ID:   [4]byte{'d', 'a', 't', 'a'},
+				Size: dataChunkSize,
+			},
+			Data: data,
+		},
+	}
+
+	if formatChunk.EffectiveFormat() != FormatPCM {
+		var sampleLength [4]byte
+
+		// Number of samples per channel
+		binary.LittleEndian.PutUint32(sampleLength[:], uint32(file.Frames()))
+
+		file.FactChunk = FactChunk{
+			Chunk: Chunk{
+				ID:   [4]byte{'f', 'a', 'c', 't'},
+				Size: [4]byte{FactChunkSize, 0, 0, 0},
+			},
+			SampleLength: sampleLength,
+		}
+	}
+
+	if err := file.updateSize(); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// NewFormatChunk returns the format sub-chunk describing audio data of the
+// configuration. The format is PCM or IEEE float, unless Format is set, and
+// is wrapped in the extensible format if ChannelMask or Ambisonic is set.
+func NewFormatChunk(cfg Config) (FormatChunk, error) {
 	if cfg.Channels > math.MaxUint16 {
-		return nil, ErrTooManyChannels
+		return FormatChunk{}, ErrTooManyChannels
 	}
 
 	if cfg.SampleRate > math.MaxUint32 {
-		return nil, ErrSampleRateTooHigh
+		return FormatChunk{}, ErrSampleRateTooHigh
 	}
 
 	if cfg.BitDepth%8 != 0 {
-		return nil, ErrInvalidBitDepth
+		return FormatChunk{}, ErrInvalidBitDepth
+	}
+
+	if cfg.BitDepth > math.MaxUint16 {
+		return FormatChunk{}, ErrBitDepthTooHigh
 	}
 
 	bytesPerSample := uint16(cfg.BitDepth) / 8
 
-	if len(data)+36 > math.MaxUint32 {
-		return nil, ErrDataTooLarge
+	format := cfg.Format
+	if format == FormatUnknown {
+		if cfg.FloatingPoint {
+			format = FormatIEEEFloat
+		} else {
+			format = FormatPCM
+		}
 	}
 
-	var chunkSize [4]byte
+	var formatTag [2]byte
 	var numChannels [2]byte
 	var sampleRate [4]byte
 	var byteRate [4]byte
 	var blockAlign [2]byte
 	var bitsPerSample [2]byte
-	var dataChunkSize [4]byte
 
-	binary.LittleEndian.PutUint32(chunkSize[:], uint32(4+(8+FormatChunkSizePCM)+(8+len(data))))
+	binary.LittleEndian.PutUint16(formatTag[:], format)
 	binary.LittleEndian.PutUint16(numChannels[:], uint16(cfg.Channels))
 	binary.LittleEndian.PutUint32(sampleRate[:], uint32(cfg.SampleRate))
 	binary.LittleEndian.PutUint32(byteRate[:], uint32(uint16(cfg.Channels)*bytesPerSample)*uint32(cfg.SampleRate))
 	binary.LittleEndian.PutUint16(blockAlign[:], uint16(cfg.Channels)*bytesPerSample)
 	binary.LittleEndian.PutUint16(bitsPerSample[:], uint16(cfg.BitDepth))
-	binary.LittleEndian.PutUint32(dataChunkSize[:], uint32(len(data)))
 
-	if cfg.FloatingPoint {
-		var sampleLength [4]byte
+	if cfg.ChannelMask != 0 || cfg.Ambisonic {
+		var channelMask [4]byte
 
-		// TODO: check if this should be sampled per channel, or total number of samples
-		binary.LittleEndian.PutUint32(sampleLength[:], uint32(len(data))/(uint32(cfg.Channels)*uint32(cfg.BitDepth)))
+		binary.LittleEndian.PutUint32(channelMask[:], cfg.ChannelMask)
 
-		return &WAVEFileFormat{
-			RIFFChunk: RIFFChunk{
-				Chunk: Chunk{
-					ID:   [4]byte{'R', 'I', 'F', 'F'},
-					Size: chunkSize,
-				},
-				Identifier: [4]byte{'W', 'A', 'V', 'E'},
-			},
-			FormatChunk: FormatChunk{
-				Chunk: Chunk{
-					ID:   [4]byte{'f', 'm', 't', ' '},
-					Size: [4]byte{FormatChunkSizeNonPCM, 0, 0, 0},
-				},
-				Format:        [2]byte{FormatIEEEFloat, 0},
-				NumChannels:   numChannels,
-				SampleRate:    sampleRate,
-				ByteRate:      byteRate,
-				BlockAlign:    blockAlign,
-				BitsPerSample: bitsPerSample,
-				ExtensionSize: [2]byte{ExtensionSizeZero, 0},
-			},
-			FactChunk: FactChunk{
-				Chunk: Chunk{
-					ID:   [4]byte{'f', 'a', 'c', 't'},
-					Size: [4]byte{FactChunkSize, 0, 0, 0},
-				},
-				SampleLength: sampleLength,
-			},
-			DataChunk: DataChunk{
-				Chunk: Chunk{
-					ID:   [4]byte{'d', 'a', 't', 'a'},
-					Size: dataChunkSize,
-				},
-				Data: data,
+		subFormat := FormatGUID(format)
+
+		if cfg.Ambisonic {
+			if _, err := AmbisonicOrder(cfg.Channels); err != nil {
+				return FormatChunk{}, err
+			}
+
+			switch format {
+			case FormatPCM:
+				subFormat = SubFormatAmbisonicPCM
+			case FormatIEEEFloat:
+				subFormat = SubFormatAmbisonicFloat
+			default:
+				return FormatChunk{}, fmt.Errorf("%w: ambisonic format 0x%04X", ErrSampleFormatNotSupported, format)
+			}
+		}
+
+		return FormatChunk{
+			Chunk: Chunk{
+				ID:   [4]byte{'f', 'm', 't', ' '},
+				Size: [4]byte{FormatChunkSizeExtensible, 0, 0, 0},
 			},
+			Format:             [2]byte{0xFE, 0xFF},
+			NumChannels:        numChannels,
+			SampleRate:         sampleRate,
+			ByteRate:           byteRate,
+			BlockAlign:         blockAlign,
+			BitsPerSample:      bitsPerSample,
+			ExtensionSize:      [2]byte{ExtensionSizeExtensible, 0},
+			ValidBitsPerSample: bitsPerSample,
+			ChannelMask:        channelMask,
+			SubFormat:          subFormat,
 		}, nil
-	} else {
-		return &WAVEFileFormat{
-			RIFFChunk: RIFFChunk{
-				Chunk: Chunk{
-					ID:   [4]byte{'R', 'I', 'F', 'F'},
-					Size: chunkSize,
-				},
-				Identifier: [4]byte{'W', 'A', 'V', 'E'},
-			},
-			FormatChunk: FormatChunk{
-				Chunk: Chunk{
-					ID:   [4]byte{'f', 'm', 't', ' '},
-					Size: [4]byte{FormatChunkSizePCM, 0, 0, 0},
-				},
-				Format:        [2]byte{FormatPCM, 0},
-				NumChannels:   numChannels,
-				SampleRate:    sampleRate,
-				ByteRate:      byteRate,
-				BlockAlign:    blockAlign,
-				BitsPerSample: bitsPerSample,
-			},
-			DataChunk: DataChunk{
-				Chunk: Chunk{
-					ID:   [4]byte{'d', 'a', 't', 'a'},
-					Size: dataChunkSize,
-				},
-				Data: data,
+	}
+
+	if format == FormatPCM {
+		return FormatChunk{
+			Chunk: Chunk{
+				ID:   [4]byte{'f', 'm', 't', ' '},
+				Size: [4]byte{FormatChunkSizePCM, 0, 0, 0},
 			},
+			Format:        formatTag,
+			NumChannels:   numChannels,
+			SampleRate:    sampleRate,
+			ByteRate:      byteRate,
+			BlockAlign:    blockAlign,
+			BitsPerSample: bitsPerSample,
 		}, nil
 	}
+
+	return FormatChunk{
+		Chunk: Chunk{
+			ID:   [4]byte{'f', 'm', 't', ' '},
+			Size: [4]byte{FormatChunkSizeNonPCM, 0, 0, 0},
+		},
+		Format:        formatTag,
+		NumChannels:   numChannels,
+		SampleRate:    sampleRate,
+		ByteRate:      byteRate,
+		BlockAlign:    blockAlign,
+		BitsPerSample: bitsPerSample,
+		ExtensionSize: [2]byte{ExtensionSizeZero, 0},
+	}, nil
 }
 
 func (f *WAVEFileFormat) Data() []byte {
@@ -227,25 +322,30 @@ func (f *WAVEFileFormat) DataSize() int {
 	return int(binary.LittleEndian.Uint32(f.DataChunk.Chunk.Size[:]))
 }
 
-func (f *WAVEFileFormat) Decode(reader io.Reader) error {
+func (f *WAVEFileFormat) decodeHeader(reader io.Reader) (uint32, error) {
+	// Optional chunks of a previous decode
+	f.FactChunk = FactChunk{}
+	f.FormatChunk.extension = ""
+	f.SubChunks = nil
+
 	// RIFF chuck ID
 	n, err := reader.Read(f.RIFFChunk.Chunk.ID[:])
 	if err != nil {
-		return fmt.Errorf("reading riff chunk: id: %w", err)
+		return 0, fmt.Errorf("reading riff chunk: id: %w", err)
 	} else if n != len(f.RIFFChunk.Chunk.ID) {
-		return fmt.Errorf("reading riff chunk: id: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading riff chunk: id: %w", io.ErrShortBuffer)
 	}
 
 	if f.RIFFChunk.Chunk.ID != [4]byte{'R', 'I', 'F', 'F'} {
-		return ErrDecodeRIFFID
+		return 0, ErrDecodeRIFFID
 	}
 
 	// RIFF chuck size
 	n, err = reader.Read(f.RIFFChunk.Chunk.Size[:])
 	if err != nil {
-		return fmt.Errorf("reading riff chunk: size: %w", err)
+		return 0, fmt.Errorf("reading riff chunk: size: %w", err)
 	} else if n != len(f.RIFFChunk.Chunk.Size) {
-		return fmt.Errorf("reading riff chunk: size: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading riff chunk: size: %w", io.ErrShortBuffer)
 	}
 
 	chuckSize := binary.LittleEndian.Uint32(f.RIFFChunk.Chunk.Size[:])
@@ -253,33 +353,33 @@ func (f *WAVEFileFormat) Decode(reader io.Reader) error {
 	// RIFF format
 	n, err = reader.Read(f.RIFFChunk.Identifier[:])
 	if err != nil {
-		return fmt.Errorf("reading riff chunk: identifier: %w", err)
+		return 0, fmt.Errorf("reading riff chunk: identifier: %w", err)
 	} else if n != len(f.RIFFChunk.Identifier) {
-		return fmt.Errorf("reading riff chunk: identifier: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading riff chunk: identifier: %w", io.ErrShortBuffer)
 	}
 
 	if f.RIFFChunk.Identifier != [4]byte{'W', 'A', 'V', 'E'} {
-		return ErrDecodeRIFFFormat
+		return 0, ErrDecodeRIFFFormat
 	}
 
 	// Format sub-chunk ID
 	n, err = reader.Read(f.FormatChunk.Chunk.ID[:])
 	if err != nil {
-		return fmt.Errorf("reading format sub-chunk: id: %w", err)
+		return 0, fmt.Errorf("reading format sub-chunk: id: %w", err)
 	} else if n != len(f.FormatChunk.Chunk.ID) {
-		return fmt.Errorf("reading format sub-chunk: id: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading format sub-chunk: id: %w", io.ErrShortBuffer)
 	}
 
 	if f.FormatChunk.Chunk.ID != [4]byte{'f', 'm', 't', ' '} {
-		return ErrDecodeFormatID
+		return 0, ErrDecodeFormatID
 	}
 
 	// Format sub-chunk size
 	n, err = reader.Read(f.FormatChunk.Chunk.Size[:])
 	if err != nil {
-		return fmt.Errorf("reading format sub-chunk: size: %w", err)
+		return 0, fmt.Errorf("reading format sub-chunk: size: %w", err)
 	} else if n != len(f.FormatChunk.Chunk.Size) {
-		return fmt.Errorf("reading format sub-chunk: size: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading format sub-chunk: size: %w", io.ErrShortBuffer)
 	}
 
 	formatSize := binary.LittleEndian.Uint32(f.FormatChunk.Chunk.Size[:])
@@ -287,242 +387,308 @@ func (f *WAVEFileFormat) Decode(reader io.Reader) error {
 	// Format sub-chunk audio format
 	n, err = reader.Read(f.FormatChunk.Format[:])
 	if err != nil {
-		return fmt.Errorf("reading format sub-chunk: audio format: %w", err)
+		return 0, fmt.Errorf("reading format sub-chunk: audio format: %w", err)
 	} else if n != len(f.FormatChunk.Format) {
-		return fmt.Errorf("reading format sub-chunk: audio format: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading format sub-chunk: audio format: %w", io.ErrShortBuffer)
 	}
 
 	// Format sub-chunk number of channels
 	n, err = reader.Read(f.FormatChunk.NumChannels[:])
 	if err != nil {
-		return fmt.Errorf("reading format sub-chunk: number of channels: %w", err)
+		return 0, fmt.Errorf("reading format sub-chunk: number of channels: %w", err)
 	} else if n != len(f.FormatChunk.NumChannels) {
-		return fmt.Errorf("reading format sub-chunk: number of channels: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading format sub-chunk: number of channels: %w", io.ErrShortBuffer)
 	}
 
 	// Format sub-chunk sample rate
 	n, err = reader.Read(f.FormatChunk.SampleRate[:])
 	if err != nil {
-		return fmt.Errorf("reading format sub-chunk: sample rate: %w", err)
+		return 0, fmt.Errorf("reading format sub-chunk: sample rate: %w", err)
 	} else if n != len(f.FormatChunk.SampleRate) {
-		return fmt.Errorf("reading format sub-chunk: sample rate: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading format sub-chunk: sample rate: %w", io.ErrShortBuffer)
 	}
 
 	// Format sub-chunk byte rate
 	n, err = reader.Read(f.FormatChunk.ByteRate[:])
 	if err != nil {
-		return fmt.Errorf("reading format sub-chunk: byte rate: %w", err)
+		return 0, fmt.Errorf("reading format sub-chunk: byte rate: %w", err)
 	} else if n != len(f.FormatChunk.ByteRate) {
-		return fmt.Errorf("reading format sub-chunk: byte rate: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading format sub-chunk: byte rate: %w", io.ErrShortBuffer)
 	}
 
 	// Format sub-chunk block align
 	n, err = reader.Read(f.FormatChunk.BlockAlign[:])
 	if err != nil {
-		return fmt.Errorf("reading format sub-chunk: block align: %w", err)
+		return 0, fmt.Errorf("reading format sub-chunk: block align: %w", err)
 	} else if n != len(f.FormatChunk.BlockAlign) {
-		return fmt.Errorf("reading format sub-chunk: block align: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading format sub-chunk: block align: %w", io.ErrShortBuffer)
 	}
 
 	// Format sub-chunk bits per sample
 	n, err = reader.Read(f.FormatChunk.BitsPerSample[:])
 	if err != nil {
-		return fmt.Errorf("reading format sub-chunk: bits per sample: %w", err)
+		return 0, fmt.Errorf("reading format sub-chunk: bits per sample: %w", err)
 	} else if n != len(f.FormatChunk.BitsPerSample) {
-		return fmt.Errorf("reading format sub-chunk: bits per sample: %w", io.ErrShortBuffer)
+		return 0, fmt.Errorf("reading format sub-chunk: bits per sample: %w", io.ErrShortBuffer)
 	}
 
 	if binary.LittleEndian.Uint16(f.FormatChunk.BitsPerSample[:])%8 != 0 {
-		return ErrDecodeFormatBitsPerSample
+		return 0, ErrDecodeFormatBitsPerSample
 	}
 
 	switch binary.LittleEndian.Uint16(f.FormatChunk.Format[:]) {
 	case FormatUnknown:
-		return ErrDecodeFormat
+		return 0, ErrDecodeFormat
 	case FormatPCM:
 		// PCM
 		if formatSize != FormatChunkSizePCM {
-			return ErrDecodeFormatSize
+			return 0, ErrDecodeFormatSize
 		}
 	case FormatExtensible:
 		// Extensible
 		if formatSize != FormatChunkSizeExtensible {
-			return ErrDecodeFormatSize
+			return 0, ErrDecodeFormatSize
 		}
 
 		// Format sub-chunk extension size
 		n, err = reader.Read(f.FormatChunk.ExtensionSize[:])
 		if err != nil {
-			return fmt.Errorf("reading format sub-chunk: extension size: %w", err)
+			return 0, fmt.Errorf("reading format sub-chunk: extension size: %w", err)
 		} else if n != len(f.FormatChunk.ExtensionSize) {
-			return fmt.Errorf("reading format sub-chunk: extension size: %w", io.ErrShortBuffer)
+			return 0, fmt.Errorf("reading format sub-chunk: extension size: %w", io.ErrShortBuffer)
 		}
 
 		if binary.LittleEndian.Uint16(f.FormatChunk.ExtensionSize[:]) != ExtensionSizeExtensible {
-			return ErrDecodeFormatExtensionSize
+			return 0, ErrDecodeFormatExtensionSize
 		}
 
 		// Format sub-chunk valid bits per sample
 		n, err = reader.Read(f.FormatChunk.ValidBitsPerSample[:])
 		if err != nil {
-			return fmt.Errorf("reading format sub-chunk: valid bits per sample: %w", err)
+			return 0, fmt.Errorf("reading format sub-chunk: valid bits per sample: %w", err)
 		} else if n != len(f.FormatChunk.ValidBitsPerSample) {
-			return fmt.Errorf("reading format sub-chunk: valid bits per sample: %w", io.ErrShortBuffer)
+			return 0, fmt.Errorf("reading format sub-chunk: valid bits per sample: %w", io.ErrShortBuffer)
 		}
 
 		if binary.LittleEndian.Uint16(f.FormatChunk.ValidBitsPerSample[:]) > binary.LittleEndian.Uint16(f.FormatChunk.BitsPerSample[:]) {
-			return ErrDecodeFormatValidBitsPerSample
+			return 0, ErrDecodeFormatValidBitsPerSample
 		}
 
 		// Format sub-chunk channel mask
 		n, err = reader.Read(f.FormatChunk.ChannelMask[:])
 		if err != nil {
-			return fmt.Errorf("reading format sub-chunk: channel mask: %w", err)
+			return 0, fmt.Errorf("reading format sub-chunk: channel mask: %w", err)
 		} else if n != len(f.FormatChunk.ChannelMask) {
-			return fmt.Errorf("reading format sub-chunk: channel mask: %w", io.ErrShortBuffer)
+			return 0, fmt.Errorf("reading format sub-chunk: channel mask: %w", io.ErrShortBuffer)
 		}
 
 		// Format sub-chunk sub-format
 		n, err = reader.Read(f.FormatChunk.SubFormat[:])
 		if err != nil {
-			return fmt.Errorf("reading format sub-chunk: sub-format: %w", err)
+			return 0, fmt.Errorf("reading format sub-chunk: sub-format: %w", err)
 		} else if n != len(f.FormatChunk.SubFormat) {
-			return fmt.Errorf("reading format sub-chunk: sub-format: %w", io.ErrShortBuffer)
+			return 0, fmt.Errorf("reading format sub-chunk: sub-format: %w", io.ErrShortBuffer)
 		}
 
-		if binary.LittleEndian.Uint16(f.FormatChunk.SubFormat[:2]) != binary.LittleEndian.Uint16(f.FormatChunk.Format[:]) {
-			return ErrDecodeFormatSubFormat
-		}
-
-		// Fact sub-chunk ID
-		n, err = reader.Read(f.FactChunk.Chunk.ID[:])
-		if err != nil {
-			return fmt.Errorf("reading fact sub-chunk: id: %w", err)
-		} else if n != len(f.FormatChunk.Chunk.ID) {
-			return fmt.Errorf("reading fact sub-chunk: id: %w", io.ErrShortBuffer)
-		}
-
-		if f.FactChunk.Chunk.ID != [4]byte{'f', 'a', 'c', 't'} {
-			return ErrDecodeFactID
-		}
-
-		// Fact sub-chunk size
-		n, err = reader.Read(f.FactChunk.Chunk.Size[:])
-		if err != nil {
-			return fmt.Errorf("reading fact sub-chunk: size: %w", err)
-		} else if n != len(f.FormatChunk.Chunk.Size) {
-			return fmt.Errorf("reading fact sub-chunk: size: %w", io.ErrShortBuffer)
-		}
-
-		if binary.LittleEndian.Uint32(f.FactChunk.Chunk.Size[:]) != FactChunkSize {
-			return ErrDecodeFactSize
-		}
-
-		// Fact sub-chunk sample length
-		n, err = reader.Read(f.FactChunk.SampleLength[:])
-		if err != nil {
-			return fmt.Errorf("reading fact sub-chunk: sample length: %w", err)
-		} else if n != len(f.FactChunk.SampleLength) {
-			return fmt.Errorf("reading fact sub-chunk: sample length: %w", io.ErrShortBuffer)
+		if _, ok := LookupSubFormat(f.FormatChunk.SubFormat); !ok {
+			return 0, ErrDecodeFormatSubFormat
 		}
 	default:
-		// Non-PCM
-		if formatSize != FormatChunkSizeNonPCM {
-			return ErrDecodeFormatSize
+		// Non-PCM, possibly with a codec-specific extension
+		if formatSize < FormatChunkSizeNonPCM {
+			return 0, ErrDecodeFormatSize
 		}
 
 		// Format sub-chunk extension size
 		n, err = reader.Read(f.FormatChunk.ExtensionSize[:])
 		if err != nil {
-			return fmt.Errorf("reading format sub-chunk: extension size: %w", err)
+			return 0, fmt.Errorf("reading format sub-chunk: extension size: %w", err)
 		} else if n != len(f.FormatChunk.ExtensionSize) {
-			return fmt.Errorf("reading format sub-chunk: extension size: %w", io.ErrShortBuffer)
+			return 0, fmt.Errorf("reading format sub-chunk: extension size: %w", io.ErrShortBuffer)
 		}
 
-		if binary.LittleEndian.Uint16(f.FormatChunk.ExtensionSize[:]) != ExtensionSizeZero {
-			return ErrDecodeFormatExtensionSize
+		extensionSize := uint32(binary.LittleEndian.Uint16(f.FormatChunk.ExtensionSize[:]))
+		if extensionSize != formatSize-FormatChunkSizeNonPCM {
+			return 0, ErrDecodeFormatExtensionSize
 		}
 
-		// Fact sub-chunk ID
-		n, err = reader.Read(f.FactChunk.Chunk.ID[:])
-		if err != nil {
-			return fmt.Errorf("reading fact sub-chunk: id: %w", err)
-		} else if n != len(f.FormatChunk.Chunk.ID) {
-			return fmt.Errorf("reading fact sub-chunk: id: %w", io.ErrShortBuffer)
+		if extensionSize > 0 {
+			// Format sub-chunk codec-specific extension, padded to an even size
+			extension := make([]byte, extensionSize+extensionSize%2)
+
+			if _, err := io.ReadFull(reader, extension); err != nil {
+				return 0, fmt.Errorf("reading format sub-chunk: extension: %w", err)
+			}
+
+			f.FormatChunk.extension = string(extension[:extensionSize])
 		}
+	}
+
+	// Bytes of the RIFF chunk consumed so far: identifier and format sub-chunk
+	read := 4 + 8 + formatSize + formatSize%2
+
+	// Sub-chunks preceding the data sub-chunk
+	for {
+		var chunk Chunk
 
-		if f.FactChunk.Chunk.ID != [4]byte{'f', 'a', 'c', 't'} {
-			return ErrDecodeFactID
+		// Sub-chunk ID
+		n, err = reader.Read(chunk.ID[:])
+		if errors.Is(err, io.EOF) {
+			return 0, ErrDecodeDataID
+		} else if err != nil {
+			return 0, fmt.Errorf("reading sub-chunk: id: %w", err)
+		} else if n != len(chunk.ID) {
+			return 0, fmt.Errorf("reading sub-chunk: id: %w", io.ErrShortBuffer)
 		}
 
-		// Fact sub-chunk size
-		n, err = reader.Read(f.FactChunk.Chunk.Size[:])
+		// Sub-chunk size
+		n, err = reader.Read(chunk.Size[:])
 		if err != nil {
-			return fmt.Errorf("reading fact sub-chunk: size: %w", err)
-		} else if n != len(f.FormatChunk.Chunk.Size) {
-			return fmt.Errorf("reading fact sub-chunk: size: %w", io.ErrShortBuffer)
+			return 0, fmt.Errorf("reading sub-chunk: size: %w", err)
+		} else if n != len(chunk.Size) {
+			return 0, fmt.Errorf("reading sub-chunk: size: %w", io.ErrShortBuffer)
 		}
 
-		if binary.LittleEndian.Uint32(f.FactChunk.Chunk.Size[:]) != FactChunkSize {
-			return ErrDecodeFactSize
-		}
+		size := binary.LittleEndian.Uint32(chunk.Size[:])
+		read += 8
 
-		// Fact sub-chunk sample length
-		n, err = reader.Read(f.FactChunk.SampleLength[:])
-		if err != nil {
-			return fmt.Errorf("reading fact sub-chunk: sample length: %w", err)
-		} else if n != len(f.FactChunk.SampleLength) {
-			return fmt.Errorf("reading fact sub-chunk: sample length: %w", io.ErrShortBuffer)
+		switch chunk.ID {
+		case [4]byte{'d', 'a', 't', 'a'}:
+			f.DataChunk.Chunk = chunk
+
+			if chuckSize < read+size {
+				return 0, ErrDecodeRIFFSize
+			}
+
+			return read, nil
+		case [4]byte{'f', 'a', 'c', 't'}:
+			f.FactChunk.Chunk = chunk
+
+			if size != FactChunkSize {
+				return 0, ErrDecodeFactSize
+			}
+
+			// Fact sub-chunk sample length
+			n, err = reader.Read(f.FactChunk.SampleLength[:])
+			if err != nil {
+				return 0, fmt.Errorf("reading fact sub-chunk: sample length: %w", err)
+			} else if n != len(f.FactChunk.SampleLength) {
+				return 0, fmt.Errorf("reading fact sub-chunk: sample length: %w", io.ErrShortBuffer)
+			}
+		default:
+			subChunk, err := decodeSubChunk(reader, chunk)
+			if err != nil {
+				return 0, err
+			}
+
+			f.SubChunks = append(f.SubChunks, subChunk)
 		}
+
+		read += size + size%2
 	}
+}
 
-	// Data sub-chunk ID
-	n, err = reader.Read(f.DataChunk.Chunk.ID[:])
+// DecodeHeader decodes all chunks up to and including the data sub-chunk
+// header. The reader is left at the start of the audio data, so the samples
+// can be streamed with a SampleReader instead of being loaded into memory.
+func (f *WAVEFileFormat) DecodeHeader(reader io.Reader) error {
+	_, err := f.decodeHeader(reader)
+	return err
+}
+
+func (f *WAVEFileFormat) Decode(reader io.Reader) error {
+	read, err := f.decodeHeader(reader)
 	if err != nil {
-		return fmt.Errorf("reading data sub-chunk: id: %w", err)
-	} else if n != len(f.DataChunk.Chunk.ID) {
-		return fmt.Errorf("reading data sub-chunk: id: %w", io.ErrShortBuffer)
+		return err
 	}
 
-	if f.DataChunk.Chunk.ID != [4]byte{'d', 'a', 't', 'a'} {
-		return ErrDecodeDataID
-	}
+	f.DataChunk.Data = make([]byte, f.DataSize())
 
-	// Data sub-chunk size
-	n, err = reader.Read(f.DataChunk.Chunk.Size[:])
+	// Data sub-chunk audio data
+	n, err := io.ReadFull(reader, f.DataChunk.Data)
 	if err != nil {
-		return fmt.Errorf("reading data sub-chunk: size: %w", err)
-	} else if n != len(f.DataChunk.Chunk.Size) {
-		return fmt.Errorf("reading data sub-chunk: size: %w", io.ErrShortWrite)
+		return fmt.Errorf("reading data sub-chunk: audio data: %w", err)
+	} else if n != len(f.DataChunk.Data) {
+		return fmt.Errorf("reading data sub-chunk: audio data: %w", io.ErrShortBuffer)
 	}
 
-	dataChunkSize := binary.LittleEndian.Uint32(f.DataChunk.Chunk.Size[:])
+	// Data sub-chunk padding byte, which is often omitted at the end of the file
+	if len(f.DataChunk.Data)%2 != 0 {
+		var padding [1]byte
 
-	if chuckSize != 4+(8+FormatChunkSizePCM)+(8+dataChunkSize) {
-		return ErrDecodeRIFFSize
+		if _, err := reader.Read(padding[:]); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("reading data sub-chunk: padding byte: %w", err)
+		}
+
+		f.DataChunk.PaddingByte = padding[0]
 	}
 
-	f.DataChunk.Data = make([]byte, dataChunkSize)
+	return f.decodeTrailer(reader, read+uint32(len(f.DataChunk.Data)+len(f.DataChunk.Data)%2))
+}
 
-	// Data sub-chunk audio data
-	n, err = reader.Read(f.DataChunk.Data)
+// DecodeMetadata decodes all chunks except the audio data, which is skipped
+// by seeking, so metadata following the data sub-chunk is decoded without
+// loading the audio data into memory. The data sub-chunk size is kept, but
+// Data is left empty, so Encode returns ErrDataNotLoaded.
+func (f *WAVEFileFormat) DecodeMetadata(reader io.ReadSeeker) error {
+	read, err := f.decodeHeader(reader)
 	if err != nil {
-		return fmt.Errorf("reading data sub-chunk: audio data: %w", err)
-	} else if n != len(f.DataChunk.Data) {
-		return fmt.Errorf("reading data sub-chunk: audio data: %w", io.ErrShortWrite)
+		return err
+	}
+
+	f.DataChunk.Data = nil
+	size := uint32(f.DataSize())
+
+	if _, err := reader.Seek(int64(size)+int64(size%2), io.SeekCurrent); err != nil {
+		return fmt.Errorf("skipping data sub-chunk: %w", err)
 	}
 
-	if f.DataChunk.Data[len(f.DataChunk.Data)-1] == 0 {
-		f.DataChunk.PaddingByte = 1
-		// Discard last byte
-		f.DataChunk.Data = f.DataChunk.Data[:len(f.DataChunk.Data)-1]
+	return f.decodeTrailer(reader, read+size+size%2)
+}
+
+// decodeTrailer decodes the sub-chunks following the data sub-chunk, up to
+// the end of the RIFF chunk, of which read bytes have been read.
+func (f *WAVEFileFormat) decodeTrailer(reader io.Reader, read uint32) error {
+	for chuckSize := binary.LittleEndian.Uint32(f.RIFFChunk.Chunk.Size[:]); read+8 <= chuckSize; {
+		var chunk Chunk
+
+		if _, err := io.ReadFull(reader, chunk.ID[:]); errors.Is(err, io.EOF) {
+			// Truncated RIFF chunk
+			break
+		} else if err != nil {
+			return fmt.Errorf("reading sub-chunk: id: %w", err)
+		}
+
+		if _, err := io.ReadFull(reader, chunk.Size[:]); err != nil {
+			return fmt.Errorf("reading sub-chunk: size: %w", err)
+		}
+
+		subChunk, err := decodeSubChunk(reader, chunk)
+		if err != nil {
+			return err
+		}
+
+		f.SubChunks = append(f.SubChunks, subChunk)
+		read += 8 + subChunk.size()
 	}
 
 	return nil
 }
 
-// TODO: implement extension encoding
+// Encode writes the file, including its format extension and sub-chunks.
+// The audio data must match the data sub-chunk size, so files decoded by
+// DecodeHeader or DecodeMetadata cannot be encoded.
 func (f *WAVEFileFormat) Encode(writer io.Writer) error {
+	if len(f.DataChunk.Data) != f.DataSize() {
+		return fmt.Errorf("%w: %d of %d bytes", ErrDataNotLoaded, len(f.DataChunk.Data), f.DataSize())
+	}
+
+	return f.encode(writer)
+}
+
+// encode writes the file, regardless of whether the audio data matches the
+// data sub-chunk size.
+func (f *WAVEFileFormat) encode(writer io.Writer) error {
 	// RIFF chuck ID
 	n, err := writer.Write(f.RIFFChunk.Chunk.ID[:])
 	if err != nil {
@@ -658,33 +824,9 @@ func (f *WAVEFileFormat) Encode(writer io.Writer) error {
 		} else if n != len(f.FormatChunk.SubFormat) {
 			return fmt.Errorf("writing format sub-chunk: sub-format: %w", io.ErrShortWrite)
 		}
-
-		// Fact sub-chunk ID
-		n, err = writer.Write(f.FactChunk.Chunk.ID[:])
-		if err != nil {
-			return fmt.Errorf("writing fact sub-chunk: id: %w", err)
-		} else if n != len(f.FormatChunk.Chunk.ID) {
-			return fmt.Errorf("writing fact sub-chunk: id: %w", io.ErrShortWrite)
-		}
-
-		// Fact sub-chunk size
-		n, err = writer.Write(f.FactChunk.Chunk.Size[:])
-		if err != nil {
-			return fmt.Errorf("writing fact sub-chunk: size: %w", err)
-		} else if n != len(f.FormatChunk.Chunk.Size) {
-			return fmt.Errorf("writing fact sub-chunk: size: %w", io.ErrShortWrite)
-		}
-
-		// Fact sub-chunk sample length
-		n, err = writer.Write(f.FactChunk.SampleLength[:])
-		if err != nil {
-			return fmt.Errorf("writing fact sub-chunk: sample length: %w", err)
-		} else if n != len(f.FactChunk.SampleLength) {
-			return fmt.Errorf("writing fact sub-chunk: sample length: %w", io.ErrShortWrite)
-		}
 	default:
-		// Non-PCM
-		if formatSize != FormatChunkSizeNonPCM {
+		// Non-PCM, possibly with a codec-specific extension
+		if formatSize != FormatChunkSizeNonPCM+uint32(len(f.FormatChunk.extension)) {
 			return ErrDecodeFormatSize
 		}
 
@@ -696,28 +838,50 @@ func (f *WAVEFileFormat) Encode(writer io.Writer) error {
 			return fmt.Errorf("writer format sub-chunk: extension size: %w", io.ErrShortWrite)
 		}
 
+		// Format sub-chunk codec-specific extension, padded to an even size
+		extension := []byte(f.FormatChunk.extension)
+		if len(extension)%2 != 0 {
+			extension = append(extension, 0)
+		}
+
+		n, err = writer.Write(extension)
+		if err != nil {
+			return fmt.Errorf("writing format sub-chunk: extension: %w", err)
+		} else if n != len(extension) {
+			return fmt.Errorf("writing format sub-chunk: extension: %w", io.ErrShortWrite)
+		}
+	}
+
+	if f.FactChunk.Chunk.ID == [4]byte{'f', 'a', 'c', 't'} {
 		// Fact sub-chunk ID
 		n, err = writer.Write(f.FactChunk.Chunk.ID[:])
 		if err != nil {
-			return fmt.Errorf("writer fact sub-chunk: id: %w", err)
-		} else if n != len(f.FormatChunk.Chunk.ID) {
-			return fmt.Errorf("writer fact sub-chunk: id: %w", io.ErrShortWrite)
+			return fmt.Errorf("writing fact sub-chunk: id: %w", err)
+		} else if n != len(f.FactChunk.Chunk.ID) {
+			return fmt.Errorf("writing fact sub-chunk: id: %w", io.ErrShortWrite)
 		}
 
 		// Fact sub-chunk size
 		n, err = writer.Write(f.FactChunk.Chunk.Size[:])
 		if err != nil {
-			return fmt.Errorf("writer fact sub-chunk: size: %w", err)
-		} else if n != len(f.FormatChunk.Chunk.Size) {
-			return fmt.Errorf("writer fact sub-chunk: size: %w", io.ErrShortWrite)
+			return fmt.Errorf("writing fact sub-chunk: size: %w", err)
+		} else if n != len(f.FactChunk.Chunk.Size) {
+			return fmt.Errorf("writing fact sub-chunk: size: %w", io.ErrShortWrite)
 		}
 
 		// Fact sub-chunk sample length
 		n, err = writer.Write(f.FactChunk.SampleLength[:])
 		if err != nil {
-			return fmt.Errorf("writer fact sub-chunk: sample length: %w", err)
+			return fmt.Errorf("writing fact sub-chunk: sample length: %w", err)
 		} else if n != len(f.FactChunk.SampleLength) {
-			return fmt.Errorf("writer fact sub-chunk: sample length: %w", io.ErrShortWrite)
+			return fmt.Errorf("writing fact sub-chunk: sample length: %w", io.ErrShortWrite)
+		}
+	}
+
+	// Other sub-chunks, such as metadata
+	for _, subChunk := range f.SubChunks {
+		if err := subChunk.Encode(writer); err != nil {
+			return err
 		}
 	}
 
@@ -745,6 +909,16 @@ func (f *WAVEFileFormat) Encode(writer io.Writer) error {
 		return fmt.Errorf("writing data sub-chunk: audio data: %w", io.ErrShortWrite)
 	}
 
+	// Data sub-chunk padding byte
+	if len(f.DataChunk.Data)%2 != 0 {
+		n, err = writer.Write([]byte{f.DataChunk.PaddingByte})
+		if err != nil {
+			return fmt.Errorf("writing data sub-chunk: padding byte: %w", err)
+		} else if n != 1 {
+			return fmt.Errorf("writing data sub-chunk: padding byte: %w", io.ErrShortWrite)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,365 @@
+package wav
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Version of the audiowaveform data format written by Waveform
+const WaveformVersion = 2
+
+var (
+	ErrWaveformResolution = errors.New("waveform samples per pixel must be positive")
+	ErrWaveformBits       = errors.New("waveform bits must be 8 or 16")
+	ErrDecodeWaveform     = errors.New("waveform data is malformed")
+)
+
+// Waveform is an overview of audio data for display, holding the lowest and
+// highest sample of each channel per pixel, which spans a fixed number of
+// frames.
+type Waveform struct {
+	SampleRate      int
+	SamplesPerPixel int // Frames per pixel
+	Channels        int
+
+	// Data holds, for each pixel and each channel in turn, the minimum and
+	// maximum sample, relative to full scale.
+	Data []float64
+}
+
+// Len returns the number of pixels.
+func (w *Waveform) Len() int {
+	return len(w.Data) / (2 * w.Channels)
+}
+
+// Point returns the minimum and maximum sample of a channel at a pixel.
+func (w *Waveform) Point(pixel, channel int) (float64, float64) {
+	i := 2 * (pixel*w.Channels + channel)
+
+	return w.Data[i], w.Data[i+1]
+}
+
+// Downsample returns a coarser waveform combining factor pixels into one.
+func (w *Waveform) Downsample(factor int) (*Waveform, error) {
+	if factor <= 0 {
+		return nil, ErrWaveformResolution
+	}
+
+	pixels := (w.Len() + factor - 1) / factor
+	size := 2 * w.Channels
+
+	waveform := &Waveform{
+		SampleRate:      w.SampleRate,
+		SamplesPerPixel: w.SamplesPerPixel * factor,
+		Channels:        w.Channels,
+		Data:            make([]float64, pixels*size),
+	}
+
+	for pixel := range pixels {
+		points := w.Data[pixel*factor*size : min((pixel+1)*factor, w.Len())*size]
+		combined := waveform.Data[pixel*size : (pixel+1)*size]
+
+		copy(combined, points)
+
+		for i := size; i < len(points); i += 2 {
+			channel := (i % size) / 2
+			combined[2*channel] = min(combined[2*channel], points[i])
+			combined[2*channel+1] = max(combined[2*channel+1], points[i+1])
+		}
+	}
+
+	return waveform, nil
+}
+
+// GenerateWaveforms decodes the header of the WAV file read from reader and
+// streams over the audio data once to compute a waveform at each of the
+// given resolutions in samples per pixel.
+func GenerateWaveforms(reader io.Reader, samplesPerPixel ...int) ([]*Waveform, error) {
+	file, samples, err := DecodeSamples(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return generateWaveforms(samples, file.FormatChunk, samplesPerPixel)
+}
+
+// Waveforms computes a waveform of the decoded audio data at each of the
+// given resolutions in samples per pixel.
+func (f *WAVEFileFormat) Waveforms(samplesPerPixel ...int) ([]*Waveform, error) {
+	samples, err := f.SampleReader()
+	if err != nil {
+		return nil, err
+	}
+
+	return generateWaveforms(samples, f.FormatChunk, samplesPerPixel)
+}
+
+func generateWaveforms(samples *SampleReader, format FormatChunk, samplesPerPixel []int) ([]*Waveform, error) {
+	builders := make([]*WaveformBuilder, len(samplesPerPixel))
+
+	for i, resolution := range samplesPerPixel {
+		builder, err := NewWaveformBuilder(format, resolution)
+		if err != nil {
+			return nil, err
+		}
+
+		builders[i] = builder
+	}
+
+	buffer := make([]float64, analysisFrames*samples.Channels())
+
+	for {
+		frames, err := samples.ReadFrames(buffer)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, builder := range builders {
+			builder.WriteFrames(buffer[:frames*samples.Channels()])
+		}
+	}
+
+	waveforms := make([]*Waveform, len(builders))
+
+	for i, builder := range builders {
+		waveforms[i] = builder.Waveform()
+	}
+
+	return waveforms, nil
+}
+
+// WaveformBuilder computes a waveform of interleaved samples incrementally.
+type WaveformBuilder struct {
+	waveform *Waveform
+	frames   int       // Frames in the current pixel
+	current  []float64 // Minimum and maximum of each channel of the current pixel
+}
+
+// NewWaveformBuilder returns a WaveformBuilder for samples of the given
+// format, with a resolution in samples per pixel.
+func NewWaveformBuilder(format FormatChunk, samplesPerPixel int) (*WaveformBuilder, error) {
+	if _, err := format.validateSampleFormat(); err != nil {
+		return nil, err
+	}
+
+	if samplesPerPixel <= 0 {
+		return nil, ErrWaveformResolution
+	}
+
+	channels := int(binary.LittleEndian.Uint16(format.NumChannels[:]))
+
+	return &WaveformBuilder{
+		waveform: &Waveform{
+			SampleRate:      int(binary.LittleEndian.Uint32(format.SampleRate[:])),
+			SamplesPerPixel: samplesPerPixel,
+			Channels:        channels,
+		},
+		current: make([]float64, 2*channels),
+	}, nil
+}
+
+// WriteFrames processes whole frames of interleaved samples.
+func (b *WaveformBuilder) WriteFrames(samples []float64) {
+	channels := b.waveform.Channels
+
+	for frame := range len(samples) / channels {
+		for channel, sample := range samples[frame*channels : (frame+1)*channels] {
+			if b.frames == 0 {
+				b.current[2*channel] = sample
+				b.current[2*channel+1] = sample
+			} else {
+				b.current[2*channel] = min(b.current[2*channel], sample)
+				b.current[2*channel+1] = max(b.current[2*channel+1], sample)
+			}
+		}
+
+		b.frames++
+
+		if b.frames == b.waveform.SamplesPerPixel {
+			b.waveform.Data = append(b.waveform.Data, b.current...)
+			b.frames = 0
+		}
+	}
+}
+
+// Waveform returns the waveform of all samples written so far, including a
+// last partial pixel.
+func (b *WaveformBuilder) Waveform() *Waveform {
+	waveform := *b.waveform
+	waveform.Data = append([]float64(nil), b.waveform.Data...)
+
+	if b.frames > 0 {
+		waveform.Data = append(waveform.Data, b.current...)
+	}
+
+	return &waveform
+}
+
+// EncodeBinary writes the waveform in the binary audiowaveform data format,
+// version 2, with points of 8 or 16 bits.
+func (w *Waveform) EncodeBinary(writer io.Writer, bits int) error {
+	points, err := w.quantize(bits)
+	if err != nil {
+		return err
+	}
+
+	var flags uint32
+	if bits == 8 {
+		flags = 1
+	}
+
+	header := []uint32{
+		WaveformVersion,
+		flags,
+		uint32(w.SampleRate),
+		uint32(w.SamplesPerPixel),
+		uint32(w.Len()),
+		uint32(w.Channels),
+	}
+
+	data := make([]byte, 0, 4*len(header)+len(points)*bits/8)
+
+	for _, value := range header {
+		data = binary.LittleEndian.AppendUint32(data, value)
+	}
+
+	for _, point := range points {
+		if bits == 8 {
+			data = append(data, byte(int8(point)))
+		} else {
+			data = binary.LittleEndian.AppendUint16(data, uint16(point))
+		}
+	}
+
+	n, err := writer.Write(data)
+	if err != nil {
+		return fmt.Errorf("writing waveform: %w", err)
+	} else if n != len(data) {
+		return fmt.Errorf("writing waveform: %w", io.ErrShortWrite)
+	}
+
+	return nil
+}
+
+// DecodeWaveform reads a waveform in the binary audiowaveform data format,
+// version 1 or 2.
+func DecodeWaveform(reader io.Reader) (*Waveform, error) {
+	var header [5]uint32
+
+	if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("reading waveform header: %w", err)
+	}
+
+	version, flags := header[0], header[1]
+
+	waveform := &Waveform{
+		SampleRate:      int(header[2]),
+		SamplesPerPixel: int(header[3]),
+		Channels:        1,
+	}
+
+	if version != 1 && version != WaveformVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrDecodeWaveform, version)
+	}
+
+	if version == WaveformVersion {
+		var channels uint32
+
+		if err := binary.Read(reader, binary.LittleEndian, &channels); err != nil {
+			return nil, fmt.Errorf("reading waveform header: %w", err)
+		}
+
+		waveform.Channels = int(channels)
+	}
+
+	if waveform.Channels == 0 || waveform.Channels > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: %d channels", ErrDecodeWaveform, waveform.Channels)
+	}
+
+	bytesPerPoint := 2
+	if flags&1 != 0 {
+		bytesPerPoint = 1
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, int64(header[4])*int64(waveform.Channels)*2*int64(bytesPerPoint)))
+	if err != nil {
+		return nil, fmt.Errorf("reading waveform data: %w", err)
+	}
+
+	if len(data) != int(header[4])*waveform.Channels*2*bytesPerPoint {
+		return nil, fmt.Errorf("reading waveform data: %w", io.ErrUnexpectedEOF)
+	}
+
+	waveform.Data = make([]float64, len(data)/bytesPerPoint)
+
+	for i := range waveform.Data {
+		if bytesPerPoint == 1 {
+			waveform.Data[i] = float64(int8(data[i])) / math.MaxInt8
+		} else {
+			waveform.Data[i] = float64(int16(binary.LittleEndian.Uint16(data[2*i:]))) / math.MaxInt16
+		}
+	}
+
+	return waveform, nil
+}
+
+// EncodeJSON writes the waveform in the JSON audiowaveform format, version
+// 2, with points of 8 or 16 bits.
+func (w *Waveform) EncodeJSON(writer io.Writer, bits int) error {
+	points, err := w.quantize(bits)
+	if err != nil {
+		return err
+	}
+
+	document := struct {
+		Version         int   `json:"version"`
+		Channels        int   `json:"channels"`
+		SampleRate      int   `json:"sample_rate"`
+		SamplesPerPixel int   `json:"samples_per_pixel"`
+		Bits            int   `json:"bits"`
+		Length          int   `json:"length"`
+		Data            []int `json:"data"`
+	}{
+		Version:         WaveformVersion,
+		Channels:        w.Channels,
+		SampleRate:      w.SampleRate,
+		SamplesPerPixel: w.SamplesPerPixel,
+		Bits:            bits,
+		Length:          w.Len(),
+		Data:            points,
+	}
+
+	if err := json.NewEncoder(writer).Encode(document); err != nil {
+		return fmt.Errorf("writing waveform: %w", err)
+	}
+
+	return nil
+}
+
+// quantize scales the points to signed integers of the given bits.
+func (w *Waveform) quantize(bits int) ([]int, error) {
+	var scale float64
+
+	switch bits {
+	case 8:
+		scale = math.MaxInt8
+	case 16:
+		scale = math.MaxInt16
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrWaveformBits, bits)
+	}
+
+	points := make([]int, len(w.Data))
+
+	for i, value := range w.Data {
+		points[i] = int(math.Round(max(-1, min(1, value)) * scale))
+	}
+
+	return points, nil
+}
@@ -0,0 +1,112 @@
+package wav_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/samborkent/wav"
+)
+
+func TestWaveforms(t *testing.T) {
+	// Stereo float samples with the right channel inverted
+	samples := []float32{0.5, -0.25, 1, -1, 0.125, -0.75, 0.25}
+
+	data := make([]byte, 0, 8*len(samples))
+	for _, sample := range samples {
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(sample))
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(-sample))
+	}
+
+	waveFile, err := wav.New(wav.Config{Channels: 2, SampleRate: 8000, BitDepth: 32, FloatingPoint: true}, data)
+	if err != nil {
+		t.Fatalf("creating wav file: %s", err.Error())
+	}
+
+	encoded := new(bytes.Buffer)
+
+	if err := waveFile.Encode(encoded); err != nil {
+		t.Fatalf("encoding wav file: %s", err.Error())
+	}
+
+	waveforms, err := wav.GenerateWaveforms(encoded, 2, 4)
+	if err != nil {
+		t.Fatalf("generating waveforms: %s", err.Error())
+	}
+
+	fine, coarse := waveforms[0], waveforms[1]
+
+	want := []float64{
+		-0.25, 0.5, -0.5, 0.25,
+		-1, 1, -1, 1,
+		-0.75, 0.125, -0.125, 0.75,
+		0.25, 0.25, -0.25, -0.25,
+	}
+
+	if fine.Len() != 4 || !slices.Equal(fine.Data, want) {
+		t.Errorf("waveform: got %v, want %v", fine.Data, want)
+	}
+
+	if low, high := coarse.Point(1, 1); low != -0.25 || high != 0.75 {
+		t.Errorf("coarse point 1 of channel 1: got %f and %f, want -0.25 and 0.75", low, high)
+	}
+
+	downsampled, err := fine.Downsample(2)
+	if err != nil {
+		t.Fatalf("downsampling: %s", err.Error())
+	}
+
+	if downsampled.SamplesPerPixel != 4 || !slices.Equal(downsampled.Data, coarse.Data) {
+		t.Errorf("downsampled waveform: got %v, want %v", downsampled.Data, coarse.Data)
+	}
+
+	for _, bits := range []int{8, 16} {
+		encodedWaveform := new(bytes.Buffer)
+
+		if err := fine.EncodeBinary(encodedWaveform, bits); err != nil {
+			t.Fatalf("encoding %d bit waveform: %s", bits, err.Error())
+		}
+
+		decoded, err := wav.DecodeWaveform(encodedWaveform)
+		if err != nil {
+			t.Fatalf("decoding %d bit waveform: %s", bits, err.Error())
+		}
+
+		if decoded.Channels != 2 || decoded.SampleRate != 8000 || decoded.SamplesPerPixel != 2 || decoded.Len() != 4 {
+			t.Errorf("decoded %d bit waveform: got %+v", bits, decoded)
+		}
+
+		for i, value := range decoded.Data {
+			if math.Abs(value-fine.Data[i]) > 1/math.Pow(2, float64(bits-1)) {
+				t.Errorf("decoded %d bit point %d: got %f, want %f", bits, i, value, fine.Data[i])
+			}
+		}
+	}
+
+	document := new(bytes.Buffer)
+
+	if err := fine.EncodeJSON(document, 8); err != nil {
+		t.Fatalf("encoding json waveform: %s", err.Error())
+	}
+
+	var decoded struct {
+		Length int   `json:"length"`
+		Data   []int `json:"data"`
+	}
+
+	if err := json.Unmarshal(document.Bytes(), &decoded); err != nil {
+		t.Fatalf("decoding json waveform: %s", err.Error())
+	}
+
+	if decoded.Length != 4 || len(decoded.Data) != 16 || decoded.Data[0] != -32 || decoded.Data[1] != 64 {
+		t.Errorf("json waveform: got %+v", decoded)
+	}
+
+	if err := fine.EncodeBinary(new(bytes.Buffer), 12); !errors.Is(err, wav.ErrWaveformBits) {
+		t.Errorf("encoding 12 bit waveform: got %v, want %v", err, wav.ErrWaveformBits)
+	}
+}